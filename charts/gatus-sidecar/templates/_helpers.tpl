@@ -117,7 +117,8 @@ lands in the shared volume gatus reads.
 sidecar flag list, rendered as a YAML sequence, derived from the structured
 sidecar.* values (see internal/config/config.go for the authoritative flags).
 Always emits --output, --default-interval, --probe-paths and --log-level; emits
---namespace / --annotation-config / --annotation-enabled only when non-empty;
+--namespace / --annotation-config / --annotation-enabled only when non-empty and
+--emit-events only when sidecar.emitEvents is on;
 emits --gateway-name / --ingress-class once per list item; per kind emits
 --enable-<kind> / --auto-<kind> / --prefix-<kind> as configured; then appends
 sidecar.extraArgs verbatim.
@@ -137,13 +138,16 @@ sidecar.extraArgs verbatim.
 {{- with $s.annotationEnabled }}
 - --annotation-enabled={{ . }}
 {{- end }}
+{{- if $s.emitEvents }}
+- --emit-events
+{{- end }}
 {{- range $s.gatewayNames }}
 - --gateway-name={{ . }}
 {{- end }}
 {{- range $s.ingressClasses }}
 - --ingress-class={{ . }}
 {{- end }}
-{{- range $kind := list "ingress" "httproute" "service" "ingressroute" }}
+{{- range $kind := list "ingress" "httproute" "service" "ingressroute" "gateway" "endpointslice" }}
 {{- $kc := index $s.kinds $kind }}
 {{- if $kc.enable }}
 - --enable-{{ $kind }}
@@ -162,8 +166,8 @@ sidecar.extraArgs verbatim.
 
 {{/*
 RBAC policy rules, rendered as a YAML sequence, DERIVED from which sidecar kinds
-are enabled (enable OR auto) — least privilege, get/list/watch only. Appends
-rbac.extraRules. Renders an empty list ("[]") when no kind is enabled and no
+are enabled (enable OR auto) — least privilege, get/list/watch only — plus
+create/patch on events when sidecar.emitEvents is on. Appends rbac.extraRules. Renders an empty list ("[]") when no kind is enabled and no
 extra rules are set.
 */}}
 {{- define "gatus-sidecar.rbacRules" -}}
@@ -177,10 +181,22 @@ extra rules are set.
 {{- end -}}
 {{- if or (index $s.kinds "httproute").enable (index $s.kinds "httproute").auto -}}
 {{- $rules = append $rules (dict "apiGroups" (list "gateway.networking.k8s.io") "resources" (list "httproutes" "gateways") "verbs" (list "get" "list" "watch")) -}}
+{{- else if or (index $s.kinds "gateway").enable (index $s.kinds "gateway").auto -}}
+{{- $rules = append $rules (dict "apiGroups" (list "gateway.networking.k8s.io") "resources" (list "gateways") "verbs" (list "get" "list" "watch")) -}}
 {{- end -}}
 {{- if or (index $s.kinds "ingressroute").enable (index $s.kinds "ingressroute").auto -}}
 {{- $rules = append $rules (dict "apiGroups" (list "traefik.io") "resources" (list "ingressroutes") "verbs" (list "get" "list" "watch")) -}}
 {{- end -}}
+{{- if or (index $s.kinds "endpointslice").enable (index $s.kinds "endpointslice").auto -}}
+{{- $rules = append $rules (dict "apiGroups" (list "discovery.k8s.io") "resources" (list "endpointslices") "verbs" (list "get" "list" "watch")) -}}
+{{- if not (or (index $s.kinds "service").enable (index $s.kinds "service").auto) -}}
+{{- /* the owning Service's annotations are its parent template */ -}}
+{{- $rules = append $rules (dict "apiGroups" (list "") "resources" (list "services") "verbs" (list "get")) -}}
+{{- end -}}
+{{- end -}}
+{{- if $s.emitEvents -}}
+{{- $rules = append $rules (dict "apiGroups" (list "") "resources" (list "events") "verbs" (list "create" "patch")) -}}
+{{- end -}}
 {{- range .Values.rbac.extraRules -}}
 {{- $rules = append $rules . -}}
 {{- end -}}
@@ -6,23 +6,30 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"runtime"
+	"slices"
 	"sync"
 	"syscall"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/debug"
 	"github.com/home-operations/gatus-sidecar/internal/gatus"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
 	"github.com/home-operations/gatus-sidecar/internal/resources"
+	"github.com/home-operations/gatus-sidecar/internal/version"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
-)
-
-var (
-	Version = "local"
-	Gitsha  = "?"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 )
 
 func main() {
@@ -40,19 +47,58 @@ func run(name string, args []string) error {
 	if err != nil {
 		return err
 	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.LogLevel})))
-	slog.Info("starting gatus-sidecar", "version", Version, "gitsha", Gitsha)
+	if cfg.ShowVersion {
+		fmt.Println(version.String())
+		return nil
+	}
+	slog.SetDefault(slog.New(newLogHandler(cfg)))
+	slog.Info("starting gatus-sidecar", "version", version.Version, "gitsha", version.Gitsha,
+		"buildDate", version.BuildDate, "goVersion", runtime.Version())
+	for _, w := range cfg.Warnings() {
+		slog.Warn(w)
+	}
 
 	enabled := resources.All(cfg)
 	if len(enabled) == 0 {
 		slog.Warn("no resource controllers enabled; exiting")
 		return nil
 	}
+	kinds := make([]string, 0, len(enabled))
+	for _, r := range enabled {
+		kinds = append(kinds, r.GVR().Resource)
+	}
+	slog.Info("enabled resource controllers", "resources", kinds, "annotationOnly", cfg.AnnotationOnly())
 
 	restCfg, err := k8s.RestConfig()
 	if err != nil {
 		return err
 	}
+	// Identifies the sidecar's requests in apiserver audit logs.
+	restCfg.UserAgent = "gatus-sidecar/" + version.Version
+	if cfg.KubeQPS > 0 {
+		restCfg.QPS = float32(cfg.KubeQPS)
+	}
+	if cfg.KubeBurst > 0 {
+		restCfg.Burst = cfg.KubeBurst
+	}
+	needsGatewayAPI := cfg.GatewayAPIVersion == "" && slices.ContainsFunc(enabled, resources.UsesGatewayAPI)
+	// A cluster-scoped --watch-gvr kind must not be watched in --namespace.
+	needsScopes := cfg.Namespace != "" && len(cfg.WatchGVRs) > 0
+	if needsGatewayAPI || needsScopes {
+		disc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+		if err != nil {
+			return err
+		}
+		if needsGatewayAPI {
+			cfg.GatewayAPIVersion = resources.ResolveGatewayAPIVersion(disc)
+			slog.Info("discovered Gateway API version", "version", cfg.GatewayAPIVersion)
+		}
+		if needsScopes {
+			resources.ResolveWatchGVRScopes(disc, cfg.WatchGVRs)
+		}
+		// Rebuild so the kinds pick the discovered version and scopes up.
+		enabled = resources.All(cfg)
+	}
 	dc, err := dynamic.NewForConfig(restCfg)
 	if err != nil {
 		return err
@@ -61,11 +107,59 @@ func run(name string, args []string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	writer := gatus.NewWriter(cfg.Output)
+	if cfg.ServiceNodePortProbe == config.NodePortProbeAuto && slices.ContainsFunc(enabled, resources.UsesNodeAddress) {
+		addr, err := resources.ResolveNodeAddress(ctx, dc)
+		if err != nil {
+			return fmt.Errorf("--service-nodeport-probe=%s: %w", config.NodePortProbeAuto, err)
+		}
+		cfg.ServiceNodePortProbe = addr
+		slog.Info("resolved NodePort probe address", "address", addr)
+	}
+
+	writer := gatus.NewWriter(cfg.Output,
+		gatus.WithDryRun(cfg.DryRun),
+		gatus.WithMergeExisting(cfg.MergeExisting),
+		gatus.WithManagedBy(cfg.ManagedBy),
+		gatus.WithSkipEmptyWrite(cfg.SkipEmptyWrite),
+		gatus.WithFileMode(cfg.OutputFileMode),
+		gatus.WithFileGID(cfg.OutputFileGID),
+	)
+	if err := writer.CheckOutputDir(); err != nil {
+		if cfg.FailOnUnwritableOutput {
+			return err
+		}
+		// Keep running: the mount may become writable, and every flush
+		// logs the same error until it does.
+		slog.Warn("output is not writable; nothing will reach Gatus until it is", "error", err)
+	}
+	if cfg.MergeExisting {
+		n, err := writer.LoadExisting()
+		if err != nil {
+			return err
+		}
+		slog.Info("preserving hand-written endpoints", "path", cfg.Output, "count", n)
+	}
+
+	if !cfg.Once {
+		// Written in one go by FlushAfterSync once every kind is listed.
+		writer.Hold()
+	}
+
+	var opts []k8s.ControllerOption
+	if cfg.EmitEvents {
+		recorder, stop, err := eventRecorder(restCfg)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		opts = append(opts, k8s.WithEventRecorder(recorder))
+	}
 
 	var wg sync.WaitGroup
+	controllers := make([]*k8s.Controller, 0, len(enabled))
 	for _, r := range enabled {
-		c := k8s.NewController(cfg, r, writer, dc)
+		c := k8s.NewController(cfg, r, writer, dc, opts...)
+		controllers = append(controllers, c)
 		wg.Go(func() {
 			if err := c.Run(ctx); err != nil {
 				slog.Error("controller stopped", "resource", c.Resource(), "error", err)
@@ -73,7 +167,97 @@ func run(name string, args []string) error {
 			}
 		})
 	}
+	if !cfg.Once {
+		wg.Go(func() { k8s.FlushAfterSync(ctx, writer, controllers, cfg.InitialSyncTimeout) })
+		wg.Go(func() { rewriteOnSIGHUP(ctx, writer, cfg.MergeExisting) })
+		if cfg.DebugAddr != "" {
+			wg.Go(func() {
+				// The debug API is an aid, not a dependency; keep generating
+				// endpoints when it can't listen.
+				if err := debug.Serve(ctx, cfg.DebugAddr, debug.Handler(cfg, writer)); err != nil {
+					slog.Error("debug API stopped", "addr", cfg.DebugAddr, "error", err)
+				}
+			})
+		}
+	}
 	wg.Wait()
+
+	if cfg.Once {
+		// A cancelled context means an interrupt or a failed controller;
+		// don't replace the file with a partial listing.
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("one-shot generation interrupted: %w", err)
+		}
+		if _, err := writer.Flush(); err != nil {
+			return err
+		}
+		slog.Info("one-shot generation complete", "endpoints", writer.Len())
+		return nil
+	}
+	if cfg.DeleteOnExit {
+		if err := writer.DeleteOutput(); err != nil {
+			return err
+		}
+		slog.Info("removed generated endpoints from output on exit", "path", cfg.Output)
+	}
 	slog.Info("shutdown complete")
 	return nil
 }
+
+// eventRecorder returns a recorder posting Events through the typed core
+// client, and a func flushing and stopping it on shutdown.
+func eventRecorder(restCfg *rest.Config) (record.EventRecorder, func(), error) {
+	cs, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: cs.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "gatus-sidecar"})
+	return recorder, broadcaster.Shutdown, nil
+}
+
+// rewriteOnSIGHUP forces the writer to rewrite the output file on every
+// SIGHUP, restoring it after someone edits or clobbers it by hand. With
+// --merge-existing the file is re-read first so newly hand-written
+// endpoints are kept.
+func rewriteOnSIGHUP(ctx context.Context, writer *gatus.Writer, mergeExisting bool) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-hup:
+		case <-ctx.Done():
+			return
+		}
+		if mergeExisting {
+			if _, err := writer.LoadExisting(); err != nil {
+				slog.Error("SIGHUP: reloading hand-written endpoints failed", "error", err)
+				continue
+			}
+		}
+		wrote, err := writer.Flush()
+		if err != nil {
+			slog.Error("SIGHUP: rewrite failed", "error", err)
+			continue
+		}
+		if !wrote {
+			// Held until the initial listing completes (which writes it
+			// anyway), --dry-run, or nothing generated yet.
+			slog.Info("SIGHUP: output not rewritten yet", "endpoints", writer.Len())
+			continue
+		}
+		slog.Info("rewrote output on SIGHUP", "endpoints", writer.Len())
+	}
+}
+
+// newLogHandler builds the process-wide slog handler from --log-level and
+// --log-format.
+func newLogHandler(cfg *config.Config) slog.Handler {
+	opts := &slog.HandlerOptions{Level: cfg.LogLevel}
+	if cfg.LogFormat == "json" {
+		return slog.NewJSONHandler(os.Stderr, opts)
+	}
+	return slog.NewTextHandler(os.Stderr, opts)
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AlertProfiles is a [flag.Value] collecting named Gatus alert lists from
+// repeated `--alert-profile name=<yaml>` flags. The YAML is either a list of
+// alerts or a single alert mapping. Redefining a name replaces it.
+type AlertProfiles map[string][]any
+
+func (p *AlertProfiles) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(slices.Sorted(maps.Keys(*p)), ",")
+}
+
+func (p *AlertProfiles) Set(v string) error {
+	name, raw, ok := strings.Cut(v, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("want name=<yaml>, got %q", v)
+	}
+	var parsed any
+	if err := yaml.Unmarshal([]byte(raw), &parsed); err != nil {
+		return fmt.Errorf("profile %q: %w", name, err)
+	}
+	var alerts []any
+	switch a := parsed.(type) {
+	case []any:
+		alerts = a
+	case map[string]any:
+		alerts = []any{a}
+	default:
+		return fmt.Errorf("profile %q: want a list of alerts or a single alert mapping", name)
+	}
+	if *p == nil {
+		*p = make(AlertProfiles)
+	}
+	(*p)[name] = alerts
+	return nil
+}
+
+// Lookup concatenates the alerts of the comma-separated profile names in
+// list, in order. Names without a profile are returned as unknown.
+func (p AlertProfiles) Lookup(list string) (alerts []any, unknown []string) {
+	for name := range strings.SplitSeq(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		profile, ok := p[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		alerts = append(alerts, profile...)
+	}
+	return alerts, unknown
+}
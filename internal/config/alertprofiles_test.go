@@ -0,0 +1,60 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestAlertProfiles_Set(t *testing.T) {
+	t.Parallel()
+	var p AlertProfiles
+	fs := flag.NewFlagSet("t", flag.ContinueOnError)
+	fs.Var(&p, "alert-profile", "")
+	err := fs.Parse([]string{
+		"--alert-profile=critical=[{type: pagerduty}, {type: slack, failure-threshold: 2}]",
+		"--alert-profile=chat={type: discord}",
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := AlertProfiles{
+		"critical": {
+			map[string]any{"type": "pagerduty"},
+			map[string]any{"type": "slack", "failure-threshold": 2},
+		},
+		"chat": {map[string]any{"type": "discord"}},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("got %v, want %v", p, want)
+	}
+	if got := p.String(); got != "chat,critical" {
+		t.Errorf("String() = %q, want chat,critical", got)
+	}
+}
+
+func TestAlertProfiles_SetRejects(t *testing.T) {
+	t.Parallel()
+	for _, v := range []string{"", "critical", "=[{type: slack}]", "critical=just-a-string", "critical=[unclosed"} {
+		var p AlertProfiles
+		if err := p.Set(v); err == nil {
+			t.Errorf("Set(%q) should fail", v)
+		}
+	}
+}
+
+func TestAlertProfiles_Lookup(t *testing.T) {
+	t.Parallel()
+	p := AlertProfiles{
+		"a": {map[string]any{"type": "slack"}},
+		"b": {map[string]any{"type": "pagerduty"}},
+	}
+	alerts, unknown := p.Lookup(" b, nope ,a,")
+	want := []any{map[string]any{"type": "pagerduty"}, map[string]any{"type": "slack"}}
+	if !reflect.DeepEqual(alerts, want) {
+		t.Errorf("alerts = %v, want %v", alerts, want)
+	}
+	if !reflect.DeepEqual(unknown, []string{"nope"}) {
+		t.Errorf("unknown = %v, want [nope]", unknown)
+	}
+}
@@ -1,11 +1,19 @@
-// Package config loads the gatus-sidecar runtime configuration from CLI flags.
+// Package config loads the gatus-sidecar runtime configuration. Each setting
+// is taken from its CLI flag, else its GATUS_SIDECAR_* environment variable,
+// else the --config-file YAML, else the built-in default.
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -13,18 +21,71 @@ import (
 const (
 	DefaultOutputPath         = "/config/gatus-sidecar.yaml"
 	DefaultInterval           = time.Minute
-	DefaultTemplateAnnotation = "gatus.home-operations.com/endpoint"
-	DefaultEnabledAnnotation  = "gatus.home-operations.com/enabled"
+	DefaultAnnotationPrefix   = "gatus.home-operations.com"
+	DefaultTemplateAnnotation = DefaultAnnotationPrefix + "/endpoint"
+	DefaultEnabledAnnotation  = DefaultAnnotationPrefix + "/enabled"
 	DefaultLogLevel           = "info"
+	DefaultLogFormat          = "text"
+	DefaultManagedBy          = "gatus-sidecar"
+	DefaultGuardedCondition   = "len([BODY]) == 0"
+	DefaultUDPCondition       = "[CONNECTED] == true"
+	DefaultTLSMinValidity     = 7 * 24 * time.Hour
+	DefaultParentCacheTTL     = 30 * time.Second
+	DefaultParentGetTimeout   = 5 * time.Second
+	DefaultInitialSyncTimeout = 2 * time.Minute
+	DefaultParentGetRetries   = 2
+	DefaultWatchTimeout       = 5 * time.Minute
+	DefaultListPageSize       = 500
+	DefaultReconcileWorkers   = 4
+	DefaultBreakerThreshold   = 0
+	DefaultBreakerWindow      = time.Minute
+	DefaultBreakerCooldown    = 2 * time.Minute
+	DefaultSummaryInterval    = time.Minute
+	DefaultServiceDNSQuery    = "kubernetes.default.svc.cluster.local"
+	DefaultOutputFileMode     = os.FileMode(0o644)
+
+	DefaultConnectTimeoutAnnotation = DefaultAnnotationPrefix + "/connect-timeout"
+	DefaultInsecureTLSAnnotation    = DefaultAnnotationPrefix + "/insecure-tls"
+	DefaultSchemeAnnotation         = DefaultAnnotationPrefix + "/scheme"
+	DefaultHostFilterAnnotation     = DefaultAnnotationPrefix + "/host-filter"
+	DefaultPortFilterAnnotation     = DefaultAnnotationPrefix + "/port-filter"
+	DefaultExternalAnnotation       = DefaultAnnotationPrefix + "/external"
+	DefaultAlertsAnnotation         = DefaultAnnotationPrefix + "/alerts"
+	DefaultMaintenanceAnnotation    = DefaultAnnotationPrefix + "/maintenance"
+	DefaultBodyConditionAnnotation  = DefaultAnnotationPrefix + "/body-condition"
+	DefaultConditionsAnnotation     = DefaultAnnotationPrefix + "/conditions"
+	DefaultMethodAnnotation         = DefaultAnnotationPrefix + "/method"
+	DefaultBodyAnnotation           = DefaultAnnotationPrefix + "/body"
+	DefaultGroupAnnotation          = DefaultAnnotationPrefix + "/group"
+	DefaultGuardedAnnotation        = DefaultAnnotationPrefix + "/guarded"
+	DefaultCheckAnnotation          = DefaultAnnotationPrefix + "/check"
+	DefaultHideURLAnnotation        = DefaultAnnotationPrefix + "/hide-url"
+	DefaultShardAnnotation          = DefaultAnnotationPrefix + "/shard"
 )
 
+// NodePortProbeAuto makes --service-nodeport-probe use the first Node's
+// address, looked up once at startup.
+const NodePortProbeAuto = "auto"
+
+// DefaultServicePortProtocols leaves out SCTP, which Gatus can't probe.
+const DefaultServicePortProtocols = "TCP,UDP"
+
 // Kind identifiers — the canonical set of watchable resource kinds. The values
 // double as the suffix of the per-kind flags (e.g. KindIngress → --enable-ingress).
 const (
-	KindIngress      = "ingress"
-	KindHTTPRoute    = "httproute"
-	KindService      = "service"
-	KindIngressRoute = "ingressroute"
+	KindIngress       = "ingress"
+	KindHTTPRoute     = "httproute"
+	KindService       = "service"
+	KindIngressRoute  = "ingressroute"
+	KindGateway       = "gateway"
+	KindEndpointSlice = "endpointslice"
+)
+
+// Gateway API versions --gateway-api-version accepts. Left empty, the
+// served version is discovered at startup.
+const (
+	GatewayAPIV1      = "v1"
+	GatewayAPIV1Beta1 = "v1beta1"
 )
 
 // kindMeta drives per-kind flag registration and help text.
@@ -37,6 +98,8 @@ var kindMeta = []struct {
 	{KindHTTPRoute, "HTTPRoute", "HTTPRoutes"},
 	{KindService, "Service", "Services"},
 	{KindIngressRoute, "Traefik IngressRoute", "Traefik IngressRoutes"},
+	{KindGateway, "Gateway listener", "Gateway listeners"},
+	{KindEndpointSlice, "EndpointSlice", "ready EndpointSlice addresses"},
 }
 
 // KindConfig holds the per-kind flag values.
@@ -44,23 +107,262 @@ type KindConfig struct {
 	Enable bool
 	Auto   bool
 	Prefix string
+	// Interval overrides DefaultInterval for the kind's endpoints; zero
+	// falls back to it.
+	Interval time.Duration
 }
 
 type Config struct {
 	Namespace      string
 	GatewayNames   StringSet
 	IngressClasses StringSet
+	// GatewayNamespaces narrows GatewayNames (or, alone, filters) to
+	// Gateways in these namespaces.
+	GatewayNamespaces StringSet
+	// GatewayAPIVersion is the version HTTPRoutes and Gateways are watched
+	// at; empty until discovery picks one.
+	GatewayAPIVersion string
+	// HostRewrites replace extracted hostnames before they are probed, for
+	// public names the Gatus pod can't resolve.
+	HostRewrites HostRewrites
+	// WatchGVRs are extra kinds, such as a bespoke CRD, watched for
+	// annotated objects whose template sets the probe url.
+	WatchGVRs GVRs
+	// Shard, when set, limits this sidecar to objects whose shard
+	// annotation names it, so several Gatus instances can split a cluster.
+	Shard string
+	// IncludeUnsharded also keeps objects without a shard annotation
+	// when Shard is set.
+	IncludeUnsharded bool
+
+	// GatewayAllAddresses fans each Gateway listener out into one endpoint
+	// per status address instead of probing only the first.
+	GatewayAllAddresses bool
+	// IngressAllHosts fans a multi-host Ingress out into one endpoint per
+	// rule host instead of monitoring only the first.
+	IngressAllHosts bool
+	// IngressInternalProbe is the ingress controller's in-cluster origin,
+	// such as http://ingress-nginx-controller.ingress-nginx.svc. When set,
+	// Ingresses are probed there with the rule host as Host header.
+	IngressInternalProbe string
+	// IngressDefaultBackend probes the default backend Service of an
+	// Ingress without rule hosts, over TCP.
+	IngressDefaultBackend bool
+	// IngressResolveBackend looks up the Service behind each probed
+	// Ingress host's first path and adds a TCP check of it alongside.
+	IngressResolveBackend bool
+	// RequireReadyStatus skips Ingresses without a load-balancer address
+	// and HTTPRoutes no Gateway has accepted, until their status changes.
+	RequireReadyStatus bool
+	// ServiceAllPorts fans a multi-port Service out into one endpoint per
+	// ServicePort instead of monitoring only the first.
+	ServiceAllPorts bool
+	// ServiceExternalDefault emits Services as push-based
+	// external-endpoints unless their external annotation says otherwise.
+	ServiceExternalDefault bool
+	// ServiceUseTargetPort probes a headless Service port's numeric
+	// targetPort rather than the port itself, since its name resolves
+	// straight to the Pods. Services with a cluster IP keep the port.
+	ServiceUseTargetPort bool
+	// RequireBackends skips Services without a ready EndpointSlice address
+	// until one appears.
+	RequireBackends bool
+	// ServiceNodePortProbe is the node address NodePort Services are
+	// probed at, on their nodePort, or NodePortProbeAuto until main
+	// resolves it. Empty probes them in-cluster like any other Service.
+	ServiceNodePortProbe string
+	// ServiceDNSPorts are UDP Service ports checked with a DNS query for
+	// ServiceDNSQuery rather than a meaningless UDP connect.
+	ServiceDNSPorts []int32
+	ServiceDNSQuery string
+	// UDPCondition is the success condition of UDP Service ports not
+	// checked with a DNS query. A UDP "connect" only proves the name
+	// resolved, so a condition on [BODY] is often more telling.
+	UDPCondition string
+	// ServiceTypes, when set, limits Services to these spec.type values,
+	// spelled as Kubernetes does (ClusterIP, LoadBalancer, ...).
+	ServiceTypes StringSet
+	// ServicePortProtocols are the port protocols (upper-case) considered
+	// when picking a Service's ports; others, such as SCTP, are skipped.
+	ServicePortProtocols StringSet
 
 	Kinds map[string]*KindConfig
+	// NoDefaultControllers turns off the annotation-only fallback that runs
+	// every default kind when no --enable-*/--auto-* flag is set.
+	NoDefaultControllers bool
+
+	Output string
+	DryRun bool
+	// EmitEvents records a Kubernetes Event on a watched object each time
+	// its outcome (generated, or skipped and why) changes after the
+	// initial listing.
+	EmitEvents bool
+	// SkipEmptyWrite keeps an existing non-empty Output rather than writing
+	// an empty list before anything has been generated.
+	SkipEmptyWrite bool
+	// DeleteOnExit removes Output on shutdown, keeping only hand-written
+	// endpoints under MergeExisting.
+	DeleteOnExit bool
+	// FailOnUnwritableOutput exits at startup when Output's directory can't
+	// be written; by default the problem is only logged.
+	FailOnUnwritableOutput bool
+	// IgnoreBadTemplates generates an endpoint without a template
+	// annotation that doesn't parse, instead of skipping the object.
+	IgnoreBadTemplates bool
+	// MergeExisting keeps hand-written endpoints already in Output instead
+	// of replacing the whole file.
+	MergeExisting bool
+	// ManagedBy is stamped as managed-by on every generated endpoint;
+	// --merge-existing relies on it to recognise the sidecar's own entries.
+	ManagedBy string
+	// OutputFileMode and OutputFileGID set the output file's permissions
+	// and group; a negative GID leaves the group alone.
+	OutputFileMode os.FileMode
+	OutputFileGID  int
+	// Once lists every resource, writes the output a single time and exits
+	// instead of watching.
+	Once bool
+	// KubeQPS and KubeBurst override the Kubernetes client's rate limits;
+	// zero keeps the client-go defaults.
+	KubeQPS   float64
+	KubeBurst int
+
+	// WatchParents watches the parents templates are inherited from
+	// (Gateways, IngressClasses) and re-reconciles their children when one
+	// changes.
+	WatchParents bool
+	// ParentCacheTTL is how long a parent's annotations are reused across
+	// reconciles before it is fetched again. Zero disables the cache.
+	ParentCacheTTL time.Duration
+	// ParentGetTimeout bounds each apiserver GET of a parent or referenced
+	// object, and ParentGetRetries is how many times a transient failure
+	// is retried before the lookup gives up. Zero timeout means no bound.
+	ParentGetTimeout time.Duration
+	ParentGetRetries int
+
+	// InitialSyncTimeout is how long the first write waits for every kind
+	// to finish its initial listing before going ahead with those that
+	// have. Zero waits indefinitely.
+	InitialSyncTimeout time.Duration
+
+	// WatchTimeout asks the apiserver to end each watch after this long so
+	// the informer reconnects, rather than sit on a silently dead
+	// connection. Zero keeps client-go's randomized 5-10m.
+	WatchTimeout time.Duration
+	// ListPageSize caps the objects per page of the informers' list
+	// requests, so a cluster-wide list of thousands of Services arrives in
+	// pieces. Zero leaves paging to client-go.
+	ListPageSize int64
+	// ReconcileWorkers is how many objects of a kind are reconciled at
+	// once, during the initial listing and after it, so parent and backend
+	// lookups overlap instead of queueing behind each other.
+	ReconcileWorkers int
+	// BreakerThreshold consecutive watch failures, each within
+	// BreakerWindow of the last, pause that watch for BreakerCooldown
+	// instead of letting the reflector keep retrying. Zero, the default,
+	// disables it.
+	BreakerThreshold int
+	BreakerWindow    time.Duration
+	BreakerCooldown  time.Duration
+
+	// ResyncInterval re-reconciles every known object on this period,
+	// pruning endpoints whose objects are gone. Zero disables it.
+	ResyncInterval time.Duration
+	// SummaryInterval is how often each controller logs counts of
+	// processed and skipped resources. Zero logs only the initial listing.
+	SummaryInterval time.Duration
 
-	Output          string
 	DefaultInterval time.Duration
 	ProbePaths      bool
+	// SanitizeNames rewrites endpoint names the way Gatus derives keys
+	// from them (see gatus.SanitizeName).
+	SanitizeNames bool
+	// MergeConditions appends template conditions (parent, then object) to
+	// the default conditions instead of letting them replace the list.
+	MergeConditions bool
+	// MergeLists appends list values (alerts, ...) from the object's
+	// template to the parent's instead of replacing them.
+	MergeLists bool
+	// GuardedCondition is the success condition of guarded DNS probes.
+	GuardedCondition string
+	// DNSResolver is the server, host or host:port, that guarded probes and
+	// dns checks query; empty leaves Gatus's default. Service DNS checks
+	// query the Service instead.
+	DNSResolver string
+	// TLSCheckPort, when set, replaces the port of tls check endpoints.
+	TLSCheckPort int
+	// TLSMinValidity is how long a certificate must remain valid for a tls
+	// check to pass. Zero only checks that the handshake succeeds.
+	TLSMinValidity time.Duration
+
+	// DefaultConnectTimeout maps onto the endpoint's client.timeout. Gatus
+	// has a single client timeout covering connect and response, so there
+	// is no separate response timeout to set. Zero leaves Gatus' default.
+	DefaultConnectTimeout time.Duration
+	// DefaultInsecureTLS sets client.insecure on https endpoints so
+	// self-signed certificates don't fail the check.
+	DefaultInsecureTLS bool
+	// ClientDNSResolver is set as client.dns-resolver on http(s) endpoints,
+	// in Gatus' {tcp,udp}://host:port form, so Gatus resolves their hosts
+	// through it instead of the pod's resolver.
+	ClientDNSResolver string
+	// DefaultHeaders are request headers set on every http(s) endpoint,
+	// e.g. a token for an auth gateway in front of the cluster.
+	DefaultHeaders Headers
+
+	// AlertProfiles are named alert lists the alerts annotation expands
+	// into the endpoint's alerts.
+	AlertProfiles AlertProfiles
+	// DefaultMaintenance holds the maintenance windows loaded from
+	// --default-maintenance-file, applied to every endpoint ahead of any
+	// from the maintenance annotation.
+	DefaultMaintenance []any
+
+	// DefaultGroup is the last-resort endpoint group when neither the object
+	// nor its parent template sets one.
+	DefaultGroup string
+	// GroupByLabel names a label whose value groups endpoints, ahead of
+	// the parent template and DefaultGroup.
+	GroupByLabel string
 
-	TemplateAnnotation string
-	EnabledAnnotation  string
+	TemplateAnnotation       string
+	EnabledAnnotation        string
+	ConnectTimeoutAnnotation string
+	InsecureTLSAnnotation    string
+	SchemeAnnotation         string
+	HostFilterAnnotation     string
+	PortFilterAnnotation     string
+	ExternalAnnotation       string
+	AlertsAnnotation         string
+	MaintenanceAnnotation    string
+	BodyConditionAnnotation  string
+	ConditionsAnnotation     string
+	MethodAnnotation         string
+	BodyAnnotation           string
+	GroupAnnotation          string
+	GuardedAnnotation        string
+	CheckAnnotation          string
+	HideURLAnnotation        string
+	ShardAnnotation          string
 
 	LogLevel slog.Level
+	// LogFormat is "text" or "json".
+	LogFormat string
+
+	// ShowVersion asks the caller to print build information and exit. Load
+	// skips validation when it is set.
+	ShowVersion bool
+
+	// DebugAddr is the listen address of the debug HTTP API; empty disables
+	// it.
+	DebugAddr string
+
+	// flags is the parsed flag set, read by Effective.
+	flags *flag.FlagSet
+
+	// warnings collects values Load replaced with a default instead of
+	// rejecting; Warnings reports them.
+	warnings []string
 }
 
 // Load parses args (without the program name) into a Config.
@@ -71,7 +373,13 @@ func Load(name string, args []string, errOut io.Writer) (*Config, error) {
 
 	fs.StringVar(&cfg.Namespace, "namespace", "", "Namespace to watch (empty for all namespaces)")
 	fs.Var(&cfg.GatewayNames, "gateway-name", "Gateway name(s) to filter HTTPRoutes; may be repeated")
+	fs.Var(&cfg.GatewayNamespaces, "gateway-namespace", "Gateway namespace(s) to filter HTTPRoutes, matched with --gateway-name on the same parentRef; may be repeated")
+	fs.StringVar(&cfg.GatewayAPIVersion, "gateway-api-version", "", "Gateway API version for HTTPRoutes and Gateways: v1 or v1beta1 (empty discovers the newest served)")
 	fs.Var(&cfg.IngressClasses, "ingress-class", "Ingress class(es) to filter Ingresses; may be repeated")
+	fs.StringVar(&cfg.Shard, "shard", "", "Only process objects whose shard annotation names this shard (empty processes every object)")
+	fs.BoolVar(&cfg.IncludeUnsharded, "include-unsharded", false, "With --shard, also process objects without a shard annotation")
+	fs.Var(&cfg.HostRewrites, "hostname-rewrite", "Rewrite extracted hostnames before probing, as from=to (from is a literal host or a regex with $1 expanding in to); may be repeated")
+	fs.Var(&cfg.WatchGVRs, "watch-gvr", "Extra kind to watch as group/version/resource; annotated objects whose template sets url are monitored; may be repeated")
 
 	cfg.Kinds = make(map[string]*KindConfig, len(kindMeta))
 	for _, k := range kindMeta {
@@ -80,19 +388,121 @@ func Load(name string, args []string, errOut io.Writer) (*Config, error) {
 		fs.BoolVar(&kc.Enable, "enable-"+k.name, false, fmt.Sprintf("Enable %s endpoint generation", k.display))
 		fs.BoolVar(&kc.Auto, "auto-"+k.name, false, fmt.Sprintf("Automatically create endpoints for %s", k.plural))
 		fs.StringVar(&kc.Prefix, "prefix-"+k.name, "", fmt.Sprintf("Prefix prepended to generated endpoint names for %s resources", k.display))
+		fs.DurationVar(&kc.Interval, k.name+"-interval", 0, fmt.Sprintf("Probe interval for %s endpoints (0 uses --default-interval)", k.display))
 	}
 
+	fs.BoolVar(&cfg.NoDefaultControllers, "no-default-controllers", false, "Run only the kinds named by --enable-*/--auto-* flags, even when none are set")
+	fs.BoolVar(&cfg.IngressAllHosts, "ingress-all-hosts", false, "Generate one endpoint per Ingress rule host instead of only the first")
+	fs.BoolVar(&cfg.GatewayAllAddresses, "gateway-all-addresses", false, "Generate one endpoint per Gateway status address and listener instead of probing only the first address")
+	fs.BoolVar(&cfg.IngressDefaultBackend, "ingress-default-backend", false, "Probe the defaultBackend Service (tcp://<service>.<namespace>.svc:<port>) of Ingresses without rule hosts")
+	fs.BoolVar(&cfg.IngressResolveBackend, "ingress-resolve-backend", false, "Look up the Service behind each Ingress host's first path and add a TCP check of it as a <name>-backend endpoint")
+	fs.BoolVar(&cfg.RequireReadyStatus, "require-ready-status", false, "Skip Ingresses without status.loadBalancer.ingress and HTTPRoutes no Gateway reports Accepted, until their status says they serve traffic")
+	fs.StringVar(&cfg.IngressInternalProbe, "ingress-internal-probe", "", "Probe Ingresses at this in-cluster ingress controller URL (scheme://host[:port]), sending the rule host as Host header")
+	fs.BoolVar(&cfg.ServiceAllPorts, "service-all-ports", false, "Generate one endpoint per Service port instead of only the first")
+	dnsPorts := fs.String("service-dns-ports", "53", "Comma-separated UDP Service ports checked with a DNS query instead of a UDP connect (empty disables)")
+	fs.StringVar(&cfg.ServiceDNSQuery, "service-dns-query", DefaultServiceDNSQuery, "Name queried by DNS checks of --service-dns-ports")
+	portProtocols := fs.String("service-port-protocol-filter", DefaultServicePortProtocols, "Comma-separated Service port protocols to probe (TCP, UDP, SCTP); Services with no such port are skipped")
+	fs.BoolVar(&cfg.RequireBackends, "require-backends", false, "Skip Services with no ready EndpointSlice address until one appears (needs list/watch on endpointslices)")
+	fs.BoolVar(&cfg.ServiceUseTargetPort, "service-use-target-port", false, "Probe each headless Service port's numeric targetPort instead of the port (named targetPorts keep the port)")
+	fs.StringVar(&cfg.ServiceNodePortProbe, "service-nodeport-probe", "", "Probe NodePort Services at <protocol>://<address>:<nodePort> on this node address; \"auto\" uses the first Node's (needs list on nodes)")
+	fs.StringVar(&cfg.UDPCondition, "udp-condition", DefaultUDPCondition, "Success condition for UDP Service ports outside --service-dns-ports")
+	serviceTypes := fs.String("service-types", "", "Comma-separated Service types to monitor (ClusterIP, NodePort, LoadBalancer, ExternalName); empty allows all")
+	fs.BoolVar(&cfg.ServiceExternalDefault, "service-external-default", false, "Emit Services as external-endpoints (push-based) unless annotated otherwise")
+
 	fs.StringVar(&cfg.Output, "output", DefaultOutputPath, "File to write generated YAML")
+	fileMode := fs.String("output-file-mode", fmt.Sprintf("%04o", DefaultOutputFileMode), "Octal permission bits of the output file")
+	fs.IntVar(&cfg.OutputFileGID, "output-file-gid", -1, "Group ID to chown the output file to after each write (-1 leaves it alone)")
+	fs.BoolVar(&cfg.Once, "once", false, "List resources, write the output once and exit instead of watching")
+	fs.Float64Var(&cfg.KubeQPS, "kube-qps", 0, "Kubernetes client queries per second (0 keeps the client-go default)")
+	fs.IntVar(&cfg.KubeBurst, "kube-burst", 0, "Kubernetes client burst (0 keeps the client-go default)")
+	fs.DurationVar(&cfg.ResyncInterval, "resync-interval", 0, "Periodically re-reconcile every resource and prune orphaned endpoints (0 disables)")
+	fs.DurationVar(&cfg.SummaryInterval, "summary-interval", DefaultSummaryInterval, "How often to log counts of processed and skipped resources (0 logs only after the initial listing)")
+	fs.BoolVar(&cfg.WatchParents, "watch-parents", false, "Watch Gateways/IngressClasses and refresh their routes' endpoints when a parent template changes")
+	fs.DurationVar(&cfg.InitialSyncTimeout, "initial-sync-timeout", DefaultInitialSyncTimeout, "How long the first write waits for every kind's initial listing before writing those that finished (0 waits indefinitely)")
+	fs.DurationVar(&cfg.WatchTimeout, "watch-timeout", DefaultWatchTimeout, "Server-side timeout for each watch request, after which it is re-established (0 keeps client-go's randomized default)")
+	fs.Int64Var(&cfg.ListPageSize, "list-page-size", DefaultListPageSize, "Objects per page when listing a kind on startup or relist (0 leaves paging to client-go)")
+	fs.IntVar(&cfg.ReconcileWorkers, "reconcile-workers", DefaultReconcileWorkers, "Objects of each kind reconciled concurrently, including during the initial listing")
+	fs.IntVar(&cfg.BreakerThreshold, "circuit-break-threshold", DefaultBreakerThreshold, "Consecutive watch failures that pause watching for --circuit-break-cooldown (0 disables)")
+	fs.DurationVar(&cfg.BreakerWindow, "circuit-break-window", DefaultBreakerWindow, "Longest gap between watch failures that still counts them as consecutive")
+	fs.DurationVar(&cfg.BreakerCooldown, "circuit-break-cooldown", DefaultBreakerCooldown, "How long watching pauses once the circuit breaker trips")
+	fs.DurationVar(&cfg.ParentCacheTTL, "parent-cache-ttl", DefaultParentCacheTTL, "How long parent (Gateway/IngressClass) annotations are cached between lookups (0 disables)")
+	fs.DurationVar(&cfg.ParentGetTimeout, "parent-get-timeout", DefaultParentGetTimeout, "Timeout for each apiserver GET of a parent or referenced object (0 for none)")
+	fs.IntVar(&cfg.ParentGetRetries, "parent-get-retries", DefaultParentGetRetries, "Retries of a parent GET that failed transiently, with backoff, before its annotations are dropped")
+	fs.BoolVar(&cfg.IgnoreBadTemplates, "ignore-bad-templates", false, "Generate endpoints without template annotations that don't parse, instead of skipping the resource")
+	fs.BoolVar(&cfg.MergeExisting, "merge-existing", false, "Keep hand-written endpoints already present in --output")
+	fs.StringVar(&cfg.ManagedBy, "managed-by-label", DefaultManagedBy, "Value of the managed-by marker on generated endpoints (empty disables it)")
+	fs.BoolVar(&cfg.FailOnUnwritableOutput, "fail-on-unwritable-output", false, "Exit at startup when the --output directory isn't writable, instead of only logging a warning")
+	fs.BoolVar(&cfg.SkipEmptyWrite, "skip-empty-write", false, "Don't replace a non-empty --output with an empty endpoint list until something has been generated")
+	fs.BoolVar(&cfg.DeleteOnExit, "delete-on-exit", false, "Remove --output on shutdown so a restarted Gatus doesn't probe stale endpoints (hand-written ones kept by --merge-existing stay)")
+	fs.BoolVar(&cfg.EmitEvents, "emit-events", false, "Record a Kubernetes Event on a resource when its endpoint is generated or skipped after startup (needs create/patch on events)")
+	fs.BoolVar(&cfg.DryRun, "dry-run", false, "Log the generated YAML (at debug level) instead of writing --output")
 	fs.DurationVar(&cfg.DefaultInterval, "default-interval", DefaultInterval, "Default interval value for endpoints")
+	fs.BoolVar(&cfg.MergeConditions, "merge-conditions", false, "Append template conditions to the defaults and the parent's instead of replacing them")
+	fs.BoolVar(&cfg.MergeLists, "merge-lists", false, "Append list values (e.g. alerts) in a resource's template to its parent's instead of replacing them")
+	fs.StringVar(&cfg.GuardedCondition, "guarded-condition", DefaultGuardedCondition, "Success condition for guarded DNS probes (e.g. \"[DNS_RCODE] == NXDOMAIN\")")
+	fs.StringVar(&cfg.DNSResolver, "dns-resolver", "", "DNS server (host or host:port) queried by guarded probes and dns checks (empty = 1.1.1.1)")
+	fs.IntVar(&cfg.TLSCheckPort, "tls-check-port", 0, "Port probed by tls checks (0 keeps the URL's port, 443 for https)")
+	fs.DurationVar(&cfg.TLSMinValidity, "tls-check-min-validity", DefaultTLSMinValidity, "Remaining certificate validity a tls check requires ([CERTIFICATE_EXPIRATION]); 0 only checks the handshake")
 	fs.BoolVar(&cfg.ProbePaths, "probe-paths", true, "Include paths from Ingress/HTTPRoute/IngressRoute match rules in probe URLs; set false to probe bare hostnames")
+	fs.BoolVar(&cfg.SanitizeNames, "sanitize-names", false, "Lowercase endpoint names and replace characters Gatus rewrites in its keys (. _ / etc.) with -")
+	fs.BoolVar(&cfg.DefaultInsecureTLS, "default-insecure-tls", false, "Skip TLS certificate verification (client.insecure) on https endpoints")
+	fs.StringVar(&cfg.ClientDNSResolver, "client-dns-resolver", "", "DNS server as tcp:// or udp://host:port, set as client.dns-resolver on http(s) endpoints (empty leaves Gatus' resolver)")
+	fs.StringVar(&cfg.DefaultGroup, "default-group", "", "Group for endpoints whose templates don't set one")
+	fs.StringVar(&cfg.GroupByLabel, "group-by-label", "", "Label whose value sets the endpoint group, e.g. app.kubernetes.io/part-of; a template or group annotation wins")
+	fs.Var(&cfg.DefaultHeaders, "default-headers", "Comma-separated 'Name: value' request headers set on http(s) endpoints; may be repeated")
+	fs.Var(&cfg.AlertProfiles, "alert-profile", "Named alert list as name=<yaml>, applied via the alerts annotation; may be repeated")
+	fs.DurationVar(&cfg.DefaultConnectTimeout, "default-connect-timeout", 0, "Default client timeout for endpoints (0 leaves the Gatus default)")
 	fs.StringVar(&cfg.TemplateAnnotation, "annotation-config", DefaultTemplateAnnotation, "Annotation key for YAML config override")
 	fs.StringVar(&cfg.EnabledAnnotation, "annotation-enabled", DefaultEnabledAnnotation, "Annotation key for enabling/disabling resource processing")
+	fs.StringVar(&cfg.ConnectTimeoutAnnotation, "annotation-connect-timeout", DefaultConnectTimeoutAnnotation, "Annotation key for the per-resource client timeout")
+	fs.StringVar(&cfg.InsecureTLSAnnotation, "annotation-insecure-tls", DefaultInsecureTLSAnnotation, "Annotation key for the per-resource insecure TLS override")
+	fs.StringVar(&cfg.SchemeAnnotation, "annotation-scheme", DefaultSchemeAnnotation, "Annotation key for the per-resource http/https scheme override")
+	fs.StringVar(&cfg.HostFilterAnnotation, "annotation-host-filter", DefaultHostFilterAnnotation, "Annotation key for the regex limiting which hosts --ingress-all-hosts monitors")
+	fs.StringVar(&cfg.AlertsAnnotation, "annotation-alerts", DefaultAlertsAnnotation, "Annotation key naming the alert profile(s) to apply")
+	fs.StringVar(&cfg.ExternalAnnotation, "annotation-external", DefaultExternalAnnotation, "Annotation key routing a resource into external-endpoints")
+	fs.StringVar(&cfg.PortFilterAnnotation, "annotation-port-filter", DefaultPortFilterAnnotation, "Annotation key for the regex limiting which ports --service-all-ports monitors")
+	fs.StringVar(&cfg.MaintenanceAnnotation, "annotation-maintenance", DefaultMaintenanceAnnotation, "Annotation key for per-resource maintenance windows")
+	fs.StringVar(&cfg.BodyConditionAnnotation, "annotation-body-condition", DefaultBodyConditionAnnotation, "Annotation key for comma-separated [BODY] checks appended to the conditions")
+	fs.StringVar(&cfg.ConditionsAnnotation, "annotation-conditions", DefaultConditionsAnnotation, "Annotation key for a list of conditions replacing the defaults")
+	fs.StringVar(&cfg.MethodAnnotation, "annotation-method", DefaultMethodAnnotation, "Annotation key for the HTTP method of http(s) probes")
+	fs.StringVar(&cfg.BodyAnnotation, "annotation-body", DefaultBodyAnnotation, "Annotation key for the request body of http(s) probes")
+	fs.StringVar(&cfg.GroupAnnotation, "annotation-group", DefaultGroupAnnotation, "Annotation key setting the endpoint group without a template")
+	fs.StringVar(&cfg.GuardedAnnotation, "annotation-guarded", DefaultGuardedAnnotation, "Annotation key overriding the template's guarded setting")
+	fs.StringVar(&cfg.HideURLAnnotation, "annotation-hide-url", DefaultHideURLAnnotation, "Annotation key hiding the endpoint's URL in the Gatus UI")
+	fs.StringVar(&cfg.ShardAnnotation, "annotation-shard", DefaultShardAnnotation, "Annotation key assigning a resource to a --shard")
+	fs.StringVar(&cfg.CheckAnnotation, "annotation-check", DefaultCheckAnnotation, "Annotation key selecting the probe type: http, tcp, icmp, dns, tls or ws")
 
+	annotationPrefix := fs.String("annotation-prefix", DefaultAnnotationPrefix, "Prefix for every annotation key not set by its own --annotation-* flag")
+	maintenanceFile := fs.String("default-maintenance-file", "", "YAML file of maintenance windows applied to every endpoint")
 	logLevel := fs.String("log-level", DefaultLogLevel, "Log level: debug, info, warn, error")
+	fs.StringVar(&cfg.LogFormat, "log-format", DefaultLogFormat, "Log format: text, json")
+	fs.BoolVar(&cfg.ShowVersion, "version", false, "Print version information and exit")
+	fs.StringVar(&cfg.DebugAddr, "debug-addr", "", "Listen address (e.g. :8090) of the unauthenticated debug API serving /endpoints and /config (empty disables it)")
+	configFile := fs.String(configFileFlag, "", "YAML file of settings keyed by flag name; environment variables ("+EnvPrefix+"*) and flags override it")
 
 	if err := fs.Parse(args); err != nil {
 		return nil, err
 	}
+	if *configFile == "" {
+		*configFile = os.Getenv(envName(configFileFlag))
+	}
+	var settings map[string][]string
+	if *configFile != "" {
+		var err error
+		if settings, err = readConfigFile(*configFile); err != nil {
+			return nil, fmt.Errorf("--%s %s: %w", configFileFlag, *configFile, err)
+		}
+	}
+	if err := applySources(fs, settings); err != nil {
+		return nil, err
+	}
+	cfg.flags = fs
+	if cfg.ShowVersion {
+		return cfg, nil
+	}
+	if err := applyAnnotationPrefix(fs, *annotationPrefix); err != nil {
+		return nil, err
+	}
 
 	if cfg.Output == "" {
 		return nil, fmt.Errorf("--output must not be empty")
@@ -100,15 +510,236 @@ func Load(name string, args []string, errOut io.Writer) (*Config, error) {
 	if cfg.DefaultInterval <= 0 {
 		return nil, fmt.Errorf("--default-interval must be positive (got %s)", cfg.DefaultInterval)
 	}
+	for _, k := range kindMeta {
+		if d := cfg.Kinds[k.name].Interval; d < 0 {
+			return nil, fmt.Errorf("--%s-interval must not be negative (got %s)", k.name, d)
+		}
+	}
+	if cfg.MergeExisting && cfg.ManagedBy == "" {
+		return nil, fmt.Errorf("--merge-existing needs a non-empty --managed-by-label to recognise generated endpoints")
+	}
+	cfg.OutputFileMode = DefaultOutputFileMode
+	if mode, err := strconv.ParseUint(*fileMode, 8, 32); err != nil || mode > 0o777 {
+		cfg.warnings = append(cfg.warnings, fmt.Sprintf("--output-file-mode %q is not an octal permission; using %04o", *fileMode, DefaultOutputFileMode))
+	} else {
+		cfg.OutputFileMode = os.FileMode(mode)
+	}
+	if cfg.OutputFileGID < -1 {
+		return nil, fmt.Errorf("--output-file-gid must be -1 or a group ID (got %d)", cfg.OutputFileGID)
+	}
+	if strings.TrimSpace(cfg.GuardedCondition) == "" {
+		return nil, fmt.Errorf("--guarded-condition must not be empty")
+	}
+	if strings.TrimSpace(cfg.UDPCondition) == "" {
+		// Gatus rejects an endpoint without conditions.
+		return nil, fmt.Errorf("--udp-condition must not be empty")
+	}
+	if err := validateNodeAddress(cfg.ServiceNodePortProbe); err != nil {
+		return nil, fmt.Errorf("--service-nodeport-probe: %w", err)
+	}
+	if err := validateOrigin(cfg.IngressInternalProbe); err != nil {
+		return nil, fmt.Errorf("--ingress-internal-probe: %w", err)
+	}
+	if err := validateResolver(cfg.DNSResolver); err != nil {
+		return nil, fmt.Errorf("--dns-resolver: %w", err)
+	}
+	if err := validateClientResolver(cfg.ClientDNSResolver); err != nil {
+		return nil, fmt.Errorf("--client-dns-resolver: %w", err)
+	}
+	if strings.ContainsAny(cfg.GroupByLabel, " \t\n=,") {
+		return nil, fmt.Errorf("--group-by-label must be a single label key such as app.kubernetes.io/part-of (got %q)", cfg.GroupByLabel)
+	}
+	switch cfg.GatewayAPIVersion {
+	case "", GatewayAPIV1, GatewayAPIV1Beta1:
+	default:
+		return nil, fmt.Errorf("--gateway-api-version must be %s or %s (got %q)", GatewayAPIV1, GatewayAPIV1Beta1, cfg.GatewayAPIVersion)
+	}
+	if cfg.TLSCheckPort < 0 || cfg.TLSCheckPort > 65535 {
+		return nil, fmt.Errorf("--tls-check-port must be between 0 and 65535 (got %d)", cfg.TLSCheckPort)
+	}
+	if cfg.TLSMinValidity < 0 {
+		return nil, fmt.Errorf("--tls-check-min-validity must not be negative (got %s)", cfg.TLSMinValidity)
+	}
+	if cfg.KubeQPS < 0 || cfg.KubeBurst < 0 {
+		return nil, fmt.Errorf("--kube-qps and --kube-burst must not be negative")
+	}
+	ports, err := parsePorts(*dnsPorts)
+	if err != nil {
+		return nil, fmt.Errorf("--service-dns-ports: %w", err)
+	}
+	cfg.ServiceDNSPorts = ports
+	if len(cfg.ServiceDNSPorts) > 0 && cfg.ServiceDNSQuery == "" {
+		return nil, fmt.Errorf("--service-dns-query must not be empty while --service-dns-ports is set")
+	}
+	if cfg.ServiceTypes, err = parseServiceTypes(*serviceTypes); err != nil {
+		return nil, fmt.Errorf("--service-types: %w", err)
+	}
+	if cfg.ServicePortProtocols, err = parsePortProtocols(*portProtocols); err != nil {
+		return nil, fmt.Errorf("--service-port-protocol-filter: %w", err)
+	}
+	if cfg.ParentCacheTTL < 0 {
+		return nil, fmt.Errorf("--parent-cache-ttl must not be negative (got %s)", cfg.ParentCacheTTL)
+	}
+	if cfg.ParentGetTimeout < 0 {
+		return nil, fmt.Errorf("--parent-get-timeout must not be negative (got %s)", cfg.ParentGetTimeout)
+	}
+	if cfg.ParentGetRetries < 0 {
+		return nil, fmt.Errorf("--parent-get-retries must not be negative (got %d)", cfg.ParentGetRetries)
+	}
+	if cfg.InitialSyncTimeout < 0 {
+		return nil, fmt.Errorf("--initial-sync-timeout must not be negative (got %s)", cfg.InitialSyncTimeout)
+	}
+	if cfg.WatchTimeout != 0 && cfg.WatchTimeout < time.Second {
+		return nil, fmt.Errorf("--watch-timeout must be 0 or at least 1s (got %s)", cfg.WatchTimeout)
+	}
+	if cfg.ListPageSize < 0 {
+		return nil, fmt.Errorf("--list-page-size must not be negative (got %d)", cfg.ListPageSize)
+	}
+	if cfg.ReconcileWorkers < 1 {
+		return nil, fmt.Errorf("--reconcile-workers must be at least 1 (got %d)", cfg.ReconcileWorkers)
+	}
+	if cfg.BreakerThreshold < 0 {
+		return nil, fmt.Errorf("--circuit-break-threshold must not be negative (got %d)", cfg.BreakerThreshold)
+	}
+	if cfg.BreakerThreshold > 0 && (cfg.BreakerWindow <= 0 || cfg.BreakerCooldown <= 0) {
+		return nil, fmt.Errorf("--circuit-break-window and --circuit-break-cooldown must be positive while --circuit-break-threshold is set")
+	}
+	if cfg.SummaryInterval < 0 {
+		return nil, fmt.Errorf("--summary-interval must not be negative (got %s)", cfg.SummaryInterval)
+	}
+	if cfg.ResyncInterval < 0 {
+		return nil, fmt.Errorf("--resync-interval must not be negative (got %s)", cfg.ResyncInterval)
+	}
+	if cfg.DefaultConnectTimeout < 0 {
+		return nil, fmt.Errorf("--default-connect-timeout must not be negative (got %s)", cfg.DefaultConnectTimeout)
+	}
+	if *maintenanceFile != "" {
+		windows, err := loadMaintenanceFile(*maintenanceFile)
+		if err != nil {
+			return nil, fmt.Errorf("--default-maintenance-file: %w", err)
+		}
+		cfg.DefaultMaintenance = windows
+	}
 	lvl, err := parseLogLevel(*logLevel)
 	if err != nil {
 		return nil, err
 	}
 	cfg.LogLevel = lvl
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return nil, fmt.Errorf("--log-format must be one of text|json (got %q)", cfg.LogFormat)
+	}
 
 	return cfg, nil
 }
 
+// redactedFlags may carry credentials (auth headers, alert tokens), so
+// Effective masks their values.
+var redactedFlags = []string{"default-headers", "alert-profile"}
+
+// Effective returns the current value of every flag by name, as the flag
+// prints it, with redactedFlags masked. Values are read at call time, so
+// adjustments made after Load (a discovered Gateway API version, a resolved
+// node address) show up.
+func (c *Config) Effective() map[string]string {
+	out := make(map[string]string)
+	if c.flags == nil {
+		return out
+	}
+	c.flags.VisitAll(func(f *flag.Flag) {
+		v := f.Value.String()
+		if v != "" && slices.Contains(redactedFlags, f.Name) {
+			v = "<redacted>"
+		}
+		out[f.Name] = v
+	})
+	return out
+}
+
+// applyAnnotationPrefix rebases every --annotation-* key that wasn't set
+// explicitly from DefaultAnnotationPrefix onto prefix, so independent
+// sidecars can each own a whole annotation namespace.
+func applyAnnotationPrefix(fs *flag.FlagSet, prefix string) error {
+	if prefix == "" || strings.Contains(prefix, "/") {
+		return fmt.Errorf("--annotation-prefix must be a non-empty annotation prefix without \"/\" (got %q)", prefix)
+	}
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "annotation-") || explicit[f.Name] || err != nil {
+			return
+		}
+		if suffix, ok := strings.CutPrefix(f.DefValue, DefaultAnnotationPrefix+"/"); ok {
+			err = f.Value.Set(prefix + "/" + suffix)
+		}
+	})
+	return err
+}
+
+// parsePorts parses a comma-separated list of port numbers.
+func parsePorts(s string) ([]int32, error) {
+	var ports []int32
+	for field := range strings.SplitSeq(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		port, err := strconv.ParseInt(field, 10, 32)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+		ports = append(ports, int32(port))
+	}
+	return ports, nil
+}
+
+// serviceTypes maps each Service spec.type, lower-cased, to its spelling.
+var serviceTypes = map[string]string{
+	"clusterip":    "ClusterIP",
+	"nodeport":     "NodePort",
+	"loadbalancer": "LoadBalancer",
+	"externalname": "ExternalName",
+}
+
+// parseServiceTypes reads a comma-separated list of Service types in any
+// case, returning them as Kubernetes spells them.
+func parseServiceTypes(s string) (StringSet, error) {
+	var types StringSet
+	for field := range strings.SplitSeq(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		typ, ok := serviceTypes[strings.ToLower(field)]
+		if !ok {
+			return nil, fmt.Errorf("invalid Service type %q", field)
+		}
+		_ = types.Set(typ)
+	}
+	return types, nil
+}
+
+// parsePortProtocols reads a comma-separated list of port protocols in any
+// case, returning them upper-cased. At least one is required, or no
+// Service could ever be probed.
+func parsePortProtocols(s string) (StringSet, error) {
+	var protocols StringSet
+	for field := range strings.SplitSeq(s, ",") {
+		field = strings.ToUpper(strings.TrimSpace(field))
+		if field == "" {
+			continue
+		}
+		if field != "TCP" && field != "UDP" && field != "SCTP" {
+			return nil, fmt.Errorf("invalid protocol %q", field)
+		}
+		_ = protocols.Set(field)
+	}
+	if len(protocols) == 0 {
+		return nil, errors.New("must list at least one protocol")
+	}
+	return protocols, nil
+}
+
 func parseLogLevel(s string) (slog.Level, error) {
 	switch strings.ToLower(s) {
 	case "debug":
@@ -124,6 +755,91 @@ func parseLogLevel(s string) (slog.Level, error) {
 	}
 }
 
+// Warnings describes flag combinations that parse fine but can't do
+// anything, such as a filter for a kind that isn't running, and values Load
+// replaced with their default. Load rejects outright invalid values; these
+// are left to the caller to log.
+func (c *Config) Warnings() []string {
+	out := slices.Clone(c.warnings)
+	if len(c.GatewayNames) > 0 && !c.runsByDefault(KindHTTPRoute) && !c.KindEnabled(KindGateway) {
+		out = append(out, "--gateway-name has no effect: neither HTTPRoutes nor Gateways are enabled")
+	}
+	if len(c.GatewayNamespaces) > 0 && !c.runsByDefault(KindHTTPRoute) && !c.KindEnabled(KindGateway) {
+		out = append(out, "--gateway-namespace has no effect: neither HTTPRoutes nor Gateways are enabled")
+	}
+	if len(c.IngressClasses) > 0 && !c.runsByDefault(KindIngress) {
+		out = append(out, "--ingress-class has no effect: Ingresses are not enabled")
+	}
+	if c.GatewayAllAddresses && !c.KindEnabled(KindGateway) {
+		out = append(out, "--gateway-all-addresses has no effect: Gateways are not enabled")
+	}
+	if c.IngressAllHosts && !c.runsByDefault(KindIngress) {
+		out = append(out, "--ingress-all-hosts has no effect: Ingresses are not enabled")
+	}
+	if c.IngressInternalProbe != "" && !c.runsByDefault(KindIngress) {
+		out = append(out, "--ingress-internal-probe has no effect: Ingresses are not enabled")
+	}
+	if c.IngressDefaultBackend && !c.runsByDefault(KindIngress) {
+		out = append(out, "--ingress-default-backend has no effect: Ingresses are not enabled")
+	}
+	if c.IngressResolveBackend && !c.runsByDefault(KindIngress) {
+		out = append(out, "--ingress-resolve-backend has no effect: Ingresses are not enabled")
+	}
+	if c.RequireReadyStatus && !c.runsByDefault(KindIngress) && !c.runsByDefault(KindHTTPRoute) {
+		out = append(out, "--require-ready-status has no effect: neither Ingresses nor HTTPRoutes are enabled")
+	}
+	if c.ServiceNodePortProbe != "" && !c.runsByDefault(KindService) {
+		out = append(out, "--service-nodeport-probe has no effect: Services are not enabled")
+	}
+	if c.RequireBackends && !c.runsByDefault(KindService) {
+		out = append(out, "--require-backends has no effect: Services are not enabled")
+	}
+	if c.ServiceUseTargetPort && !c.runsByDefault(KindService) {
+		out = append(out, "--service-use-target-port has no effect: Services are not enabled")
+	}
+	if len(c.ServiceTypes) > 0 && !c.runsByDefault(KindService) {
+		out = append(out, "--service-types has no effect: Services are not enabled")
+	}
+	if (c.ServiceAllPorts || c.ServiceExternalDefault) && !c.runsByDefault(KindService) {
+		out = append(out, "--service-all-ports and --service-external-default have no effect: Services are not enabled")
+	}
+	if c.IncludeUnsharded && c.Shard == "" {
+		out = append(out, "--include-unsharded has no effect: --shard is not set")
+	}
+	if c.DebugAddr != "" && c.Once {
+		out = append(out, "--debug-addr has no effect with --once")
+	}
+	if c.DeleteOnExit && c.Once {
+		out = append(out, "--delete-on-exit has no effect with --once")
+	}
+	if !c.anyAuto() && c.TemplateAnnotation == "" && c.EnabledAnnotation == "" {
+		out = append(out, "no --auto-* flag is set and both --annotation-config and --annotation-enabled are empty; no resource can opt in")
+	}
+	return out
+}
+
+// runsByDefault reports whether a kind that annotation-only mode covers
+// (every kind except Gateway and EndpointSlice) will run.
+func (c *Config) runsByDefault(name string) bool {
+	return c.AnnotationOnly() || c.KindEnabled(name)
+}
+
+func (c *Config) anyAuto() bool {
+	for _, k := range c.Kinds {
+		if k.Auto {
+			return true
+		}
+	}
+	return false
+}
+
+// AnnotationOnly reports whether the default kinds all run in
+// annotation-only mode: no --enable-*/--auto-* flag is set and
+// --no-default-controllers isn't either.
+func (c *Config) AnnotationOnly() bool {
+	return !c.NoDefaultControllers && !c.AnyExplicitlyEnabled()
+}
+
 // AnyExplicitlyEnabled reports whether any --enable-* or --auto-* flag is set.
 func (c *Config) AnyExplicitlyEnabled() bool {
 	for _, k := range c.Kinds {
@@ -141,12 +857,123 @@ func (c *Config) KindEnabled(name string) bool {
 	return k != nil && (k.Enable || k.Auto)
 }
 
+// Disabled reports whether annotations switch a resource off: the enabled
+// annotation is present *and* falsy. Absence is not "disabled". Unparseable
+// values (e.g. empty, "yes") count as disabled so a typo can't silently
+// widen monitoring.
+func (c *Config) Disabled(annotations map[string]string) bool {
+	v, ok := annotations[c.EnabledAnnotation]
+	if !ok || c.EnabledAnnotation == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(v)
+	return err != nil || !enabled
+}
+
+// InShard reports whether annotations assign a resource to this sidecar's
+// --shard. Without --shard every resource is in; otherwise the shard
+// annotation must name it, and resources without one are in only with
+// --include-unsharded.
+func (c *Config) InShard(annotations map[string]string) bool {
+	if c.Shard == "" {
+		return true
+	}
+	v, ok := annotations[c.ShardAnnotation]
+	if !ok || c.ShardAnnotation == "" {
+		return c.IncludeUnsharded
+	}
+	return strings.TrimSpace(v) == c.Shard
+}
+
 // AutoEnabled reports whether the named kind is in auto-discovery mode.
 func (c *Config) AutoEnabled(name string) bool {
 	k := c.Kinds[name]
 	return k != nil && k.Auto
 }
 
+// validateNodeAddress accepts NodePortProbeAuto or a bare IP or hostname,
+// without scheme or port since the nodePort supplies one.
+func validateNodeAddress(addr string) error {
+	if addr == "" || addr == NodePortProbeAuto || net.ParseIP(addr) != nil {
+		return nil
+	}
+	if strings.ContainsAny(addr, ":/[] ") {
+		return fmt.Errorf("want %q or an IP or hostname without port, got %q", NodePortProbeAuto, addr)
+	}
+	return nil
+}
+
+// validateOrigin accepts an empty value or an http(s) URL with a host and
+// nothing after it.
+func validateOrigin(origin string) error {
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("want http(s)://host[:port], got %q", origin)
+	}
+	if strings.TrimSuffix(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("must not have a path or query (got %q)", origin)
+	}
+	return nil
+}
+
+// validateResolver accepts a host or host:port with a numeric port, the
+// forms Gatus takes as a DNS endpoint's url, or empty for the default.
+func validateResolver(resolver string) error {
+	if resolver == "" {
+		return nil
+	}
+	host, port, err := net.SplitHostPort(resolver)
+	if err != nil {
+		// No port: Gatus adds :53. A bare IPv6 literal has colons too.
+		if strings.Contains(resolver, ":") && net.ParseIP(resolver) == nil {
+			return fmt.Errorf("invalid server %q", resolver)
+		}
+		return nil
+	}
+	if host == "" {
+		return fmt.Errorf("missing host in %q", resolver)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port in %q", resolver)
+	}
+	return nil
+}
+
+// validateClientResolver accepts "" or the {tcp,udp}://host:port form
+// Gatus requires of client.dns-resolver, where the port can't be omitted.
+func validateClientResolver(resolver string) error {
+	if resolver == "" {
+		return nil
+	}
+	scheme, hostPort, ok := strings.Cut(resolver, "://")
+	if !ok || (scheme != "tcp" && scheme != "udp") {
+		return fmt.Errorf("%q must start with tcp:// or udp://", resolver)
+	}
+	host, port, err := net.SplitHostPort(hostPort)
+	if err != nil || host == "" {
+		return fmt.Errorf("%q must be %s://host:port", resolver, scheme)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid port in %q", resolver)
+	}
+	return nil
+}
+
+// Interval returns the probe interval for the named kind's endpoints: its
+// --<kind>-interval, or DefaultInterval when that is unset.
+func (c *Config) Interval(name string) time.Duration {
+	if k := c.Kinds[name]; k != nil && k.Interval > 0 {
+		return k.Interval
+	}
+	return c.DefaultInterval
+}
+
 // Prefix returns the endpoint-name prefix configured for the named kind.
 func (c *Config) Prefix(name string) string {
 	if k := c.Kinds[name]; k != nil {
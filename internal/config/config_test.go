@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"log/slog"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -20,9 +22,42 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.DefaultInterval != DefaultInterval {
 		t.Errorf("DefaultInterval = %v, want %v", cfg.DefaultInterval, DefaultInterval)
 	}
+	if cfg.ManagedBy != DefaultManagedBy {
+		t.Errorf("ManagedBy = %q, want %q", cfg.ManagedBy, DefaultManagedBy)
+	}
+	if cfg.ParentCacheTTL != DefaultParentCacheTTL {
+		t.Errorf("ParentCacheTTL = %v, want %v", cfg.ParentCacheTTL, DefaultParentCacheTTL)
+	}
+	if cfg.ParentGetTimeout != DefaultParentGetTimeout || cfg.ParentGetRetries != DefaultParentGetRetries {
+		t.Errorf("ParentGetTimeout/ParentGetRetries = %v/%d", cfg.ParentGetTimeout, cfg.ParentGetRetries)
+	}
+	if cfg.InitialSyncTimeout != DefaultInitialSyncTimeout {
+		t.Errorf("InitialSyncTimeout = %v, want %v", cfg.InitialSyncTimeout, DefaultInitialSyncTimeout)
+	}
+	if cfg.BreakerThreshold != 0 {
+		t.Errorf("BreakerThreshold = %d, want 0 (breaker off)", cfg.BreakerThreshold)
+	}
+	if cfg.DNSResolver != "" {
+		t.Errorf("DNSResolver = %q, want empty", cfg.DNSResolver)
+	}
+	if cfg.LogFormat != DefaultLogFormat {
+		t.Errorf("LogFormat = %q, want %q", cfg.LogFormat, DefaultLogFormat)
+	}
 	if cfg.TemplateAnnotation != DefaultTemplateAnnotation {
 		t.Errorf("TemplateAnnotation = %q, want %q", cfg.TemplateAnnotation, DefaultTemplateAnnotation)
 	}
+	if cfg.FailOnUnwritableOutput {
+		t.Errorf("FailOnUnwritableOutput should default to false")
+	}
+	if !slices.Equal(cfg.ServicePortProtocols, StringSet{"TCP", "UDP"}) {
+		t.Errorf("ServicePortProtocols = %q, want [TCP UDP]", cfg.ServicePortProtocols)
+	}
+	if cfg.UDPCondition != DefaultUDPCondition {
+		t.Errorf("UDPCondition = %q, want %q", cfg.UDPCondition, DefaultUDPCondition)
+	}
+	if cfg.ReconcileWorkers != DefaultReconcileWorkers {
+		t.Errorf("ReconcileWorkers = %d, want %d", cfg.ReconcileWorkers, DefaultReconcileWorkers)
+	}
 	if cfg.AnyExplicitlyEnabled() {
 		t.Errorf("AnyExplicitlyEnabled() should be false with default flags")
 	}
@@ -34,14 +69,71 @@ func TestLoad_AllFlags(t *testing.T) {
 		"--namespace=ns",
 		"--gateway-name=gw1",
 		"--gateway-name=gw2",
+		"--gateway-namespace=network",
 		"--ingress-class=nginx",
 		"--ingress-class=traefik",
 		"--enable-httproute=true",
 		"--auto-ingress=true",
 		"--output=/tmp/foo.yaml",
 		"--default-interval=30s",
+		"--resync-interval=5m",
+		"--watch-parents",
+		"--parent-cache-ttl=0s",
+		"--parent-get-timeout=2s",
+		"--initial-sync-timeout=30s",
+		"--parent-get-retries=0",
+		"--watch-timeout=90s",
+		"--service-dns-ports=53, 5353",
+		"--service-dns-query=example.com",
+		"--service-types=clusterip, LoadBalancer",
+		"--service-port-protocol-filter=tcp,sctp",
+		"--no-default-controllers",
+		"--merge-conditions",
+		"--merge-lists",
+		"--sanitize-names",
+		"--guarded-condition=[DNS_RCODE] == NXDOMAIN",
+		"--udp-condition=len([BODY]) > 0",
+		"--dns-resolver=9.9.9.9:53",
+		"--output-file-mode=0640",
+		"--output-file-gid=2000",
+		"--kube-qps=50",
+		"--kube-burst=100",
+		"--reconcile-workers=8",
 		"--annotation-config=k1",
 		"--annotation-enabled=k2",
+		"--annotation-connect-timeout=k3",
+		"--default-connect-timeout=5s",
+		"--annotation-insecure-tls=k4",
+		"--default-insecure-tls",
+		"--client-dns-resolver=udp://10.96.0.10:53",
+		"--annotation-scheme=k5",
+		"--annotation-host-filter=k6",
+		"--annotation-port-filter=k7",
+		"--annotation-external=k8",
+		"--service-external-default",
+		"--default-group=apps",
+		"--group-by-label=app.kubernetes.io/part-of",
+		"--alert-profile=critical={type: pagerduty}",
+		"--annotation-alerts=k9",
+		"--ingress-all-hosts",
+		"--gateway-all-addresses",
+		"--ingress-resolve-backend",
+		"--require-ready-status",
+		"--service-all-ports",
+		"--service-use-target-port",
+		"--require-backends",
+		"--shard=eu-west",
+		"--include-unsharded",
+		"--service-nodeport-probe=192.168.1.10",
+		"--dry-run",
+		"--skip-empty-write",
+		"--delete-on-exit",
+		"--fail-on-unwritable-output",
+		"--log-format=json",
+		"--once",
+		"--merge-existing",
+		"--managed-by-label=team-a",
+		"--debug-addr=127.0.0.1:8090",
 	}
 	cfg, err := Load("test", args, &bytes.Buffer{})
 	if err != nil {
@@ -49,24 +141,103 @@ func TestLoad_AllFlags(t *testing.T) {
 	}
 	if cfg.Namespace != "ns" ||
 		!reflect.DeepEqual([]string(cfg.GatewayNames), []string{"gw1", "gw2"}) ||
+		!reflect.DeepEqual([]string(cfg.GatewayNamespaces), []string{"network"}) ||
 		!reflect.DeepEqual([]string(cfg.IngressClasses), []string{"nginx", "traefik"}) {
 		t.Errorf("filter flags incorrect: %+v", cfg)
 	}
 	if !cfg.Kinds[KindHTTPRoute].Enable || !cfg.Kinds[KindIngress].Auto {
 		t.Errorf("enable flags incorrect: %+v", cfg)
 	}
-	if cfg.Output != "/tmp/foo.yaml" {
+	if cfg.Output != "/tmp/foo.yaml" || !cfg.DryRun || !cfg.SkipEmptyWrite || !cfg.DeleteOnExit || !cfg.FailOnUnwritableOutput || !cfg.Once || !cfg.MergeExisting || cfg.ManagedBy != "team-a" {
 		t.Errorf("Output = %q", cfg.Output)
 	}
 	if cfg.DefaultInterval != 30*time.Second {
 		t.Errorf("DefaultInterval = %v", cfg.DefaultInterval)
 	}
-	if cfg.TemplateAnnotation != "k1" || cfg.EnabledAnnotation != "k2" {
+	if !cfg.MergeConditions || !cfg.MergeLists || !cfg.SanitizeNames {
+		t.Errorf("MergeConditions/MergeLists/SanitizeNames = %v/%v/%v", cfg.MergeConditions, cfg.MergeLists, cfg.SanitizeNames)
+	}
+	if !slices.Equal(cfg.ServiceDNSPorts, []int32{53, 5353}) || cfg.ServiceDNSQuery != "example.com" {
+		t.Errorf("ServiceDNSPorts/Query = %v/%q", cfg.ServiceDNSPorts, cfg.ServiceDNSQuery)
+	}
+	if !slices.Equal(cfg.ServiceTypes, StringSet{"ClusterIP", "LoadBalancer"}) {
+		t.Errorf("ServiceTypes = %q", cfg.ServiceTypes)
+	}
+	if !slices.Equal(cfg.ServicePortProtocols, StringSet{"TCP", "SCTP"}) {
+		t.Errorf("ServicePortProtocols = %q", cfg.ServicePortProtocols)
+	}
+	if cfg.ParentCacheTTL != 0 || cfg.WatchTimeout != 90*time.Second {
+		t.Errorf("ParentCacheTTL/WatchTimeout = %v/%v", cfg.ParentCacheTTL, cfg.WatchTimeout)
+	}
+	if cfg.InitialSyncTimeout != 30*time.Second {
+		t.Errorf("InitialSyncTimeout = %v, want 30s", cfg.InitialSyncTimeout)
+	}
+	if cfg.ParentGetTimeout != 2*time.Second || cfg.ParentGetRetries != 0 {
+		t.Errorf("ParentGetTimeout/ParentGetRetries = %v/%d", cfg.ParentGetTimeout, cfg.ParentGetRetries)
+	}
+	if cfg.ResyncInterval != 5*time.Minute || !cfg.WatchParents {
+		t.Errorf("ResyncInterval = %v, WatchParents = %v", cfg.ResyncInterval, cfg.WatchParents)
+	}
+	if cfg.OutputFileMode != 0o640 || cfg.OutputFileGID != 2000 {
+		t.Errorf("OutputFileMode/OutputFileGID = %o/%d", cfg.OutputFileMode, cfg.OutputFileGID)
+	}
+	if cfg.UDPCondition != "len([BODY]) > 0" {
+		t.Errorf("UDPCondition = %q", cfg.UDPCondition)
+	}
+	if cfg.ServiceNodePortProbe != "192.168.1.10" {
+		t.Errorf("ServiceNodePortProbe = %q", cfg.ServiceNodePortProbe)
+	}
+	if cfg.GuardedCondition != "[DNS_RCODE] == NXDOMAIN" || cfg.DNSResolver != "9.9.9.9:53" {
+		t.Errorf("GuardedCondition/DNSResolver = %q/%q", cfg.GuardedCondition, cfg.DNSResolver)
+	}
+	if cfg.KubeQPS != 50 || cfg.KubeBurst != 100 {
+		t.Errorf("KubeQPS/KubeBurst = %v/%d", cfg.KubeQPS, cfg.KubeBurst)
+	}
+	if cfg.ReconcileWorkers != 8 {
+		t.Errorf("ReconcileWorkers = %d", cfg.ReconcileWorkers)
+	}
+	if cfg.Shard != "eu-west" || !cfg.IncludeUnsharded {
+		t.Errorf("Shard/IncludeUnsharded = %q/%v", cfg.Shard, cfg.IncludeUnsharded)
+	}
+	if !cfg.RequireReadyStatus || !cfg.RequireBackends {
+		t.Errorf("RequireReadyStatus/RequireBackends = %v/%v", cfg.RequireReadyStatus, cfg.RequireBackends)
+	}
+	if !cfg.IngressAllHosts || !cfg.GatewayAllAddresses || !cfg.IngressResolveBackend || !cfg.ServiceAllPorts || !cfg.ServiceUseTargetPort || !cfg.ServiceExternalDefault {
+		t.Errorf("fan-out flags incorrect: %+v", cfg)
+	}
+	if cfg.LogFormat != "json" {
+		t.Errorf("LogFormat = %q", cfg.LogFormat)
+	}
+	if cfg.DefaultGroup != "apps" || cfg.GroupByLabel != "app.kubernetes.io/part-of" {
+		t.Errorf("DefaultGroup/GroupByLabel = %q/%q", cfg.DefaultGroup, cfg.GroupByLabel)
+	}
+	if cfg.DebugAddr != "127.0.0.1:8090" {
+		t.Errorf("DebugAddr = %q", cfg.DebugAddr)
+	}
+	if len(cfg.AlertProfiles["critical"]) != 1 {
+		t.Errorf("AlertProfiles = %v", cfg.AlertProfiles)
+	}
+	if cfg.DefaultConnectTimeout != 5*time.Second {
+		t.Errorf("DefaultConnectTimeout = %v", cfg.DefaultConnectTimeout)
+	}
+	if !cfg.DefaultInsecureTLS {
+		t.Errorf("DefaultInsecureTLS = false")
+	}
+	if cfg.ClientDNSResolver != "udp://10.96.0.10:53" {
+		t.Errorf("ClientDNSResolver = %q", cfg.ClientDNSResolver)
+	}
+	if cfg.TemplateAnnotation != "k1" || cfg.EnabledAnnotation != "k2" ||
+		cfg.ConnectTimeoutAnnotation != "k3" || cfg.InsecureTLSAnnotation != "k4" || cfg.SchemeAnnotation != "k5" ||
+		cfg.HostFilterAnnotation != "k6" || cfg.PortFilterAnnotation != "k7" ||
+		cfg.ExternalAnnotation != "k8" || cfg.AlertsAnnotation != "k9" {
 		t.Errorf("annotation flags incorrect: %+v", cfg)
 	}
 	if !cfg.AnyExplicitlyEnabled() {
 		t.Errorf("AnyExplicitlyEnabled() should be true with --enable-httproute and --auto-ingress")
 	}
+	if !cfg.NoDefaultControllers || cfg.AnnotationOnly() {
+		t.Errorf("NoDefaultControllers = %v, AnnotationOnly() = %v", cfg.NoDefaultControllers, cfg.AnnotationOnly())
+	}
 }
 
 func TestLoad_RejectsBadValues(t *testing.T) {
@@ -77,6 +248,42 @@ func TestLoad_RejectsBadValues(t *testing.T) {
 	}{
 		{"empty output", []string{"--output="}},
 		{"zero interval", []string{"--default-interval=0s"}},
+		{"negative connect timeout", []string{"--default-connect-timeout=-1s"}},
+		{"negative resync interval", []string{"--resync-interval=-1m"}},
+		{"group-by-label selector", []string{"--group-by-label=app=web"}},
+		{"bad dns port", []string{"--service-dns-ports=53,dns"}},
+		{"out of range dns port", []string{"--service-dns-ports=70000"}},
+		{"dns ports without query", []string{"--service-dns-query="}},
+		{"negative parent cache ttl", []string{"--parent-cache-ttl=-1s"}},
+		{"negative parent get timeout", []string{"--parent-get-timeout=-1s"}},
+		{"negative initial sync timeout", []string{"--initial-sync-timeout=-1s"}},
+		{"negative parent get retries", []string{"--parent-get-retries=-1"}},
+		{"negative kind interval", []string{"--service-interval=-1s"}},
+		{"negative summary interval", []string{"--summary-interval=-1s"}},
+		{"negative circuit break threshold", []string{"--circuit-break-threshold=-1"}},
+		{"zero circuit break cooldown", []string{"--circuit-break-threshold=5", "--circuit-break-cooldown=0s"}},
+		{"tls check port out of range", []string{"--tls-check-port=70000"}},
+		{"negative tls check min validity", []string{"--tls-check-min-validity=-1h"}},
+		{"unknown gateway api version", []string{"--gateway-api-version=v2"}},
+		{"dns resolver with bad port", []string{"--dns-resolver=1.1.1.1:dns"}},
+		{"sub-second watch timeout", []string{"--watch-timeout=500ms"}},
+		{"negative list page size", []string{"--list-page-size=-1"}},
+		{"zero reconcile workers", []string{"--reconcile-workers=0"}},
+		{"nodeport probe with port", []string{"--service-nodeport-probe=192.168.1.10:30080"}},
+		{"nodeport probe with scheme", []string{"--service-nodeport-probe=tcp://node-1"}},
+		{"empty udp condition", []string{"--udp-condition= "}},
+		{"unknown port protocol", []string{"--service-port-protocol-filter=TCP,QUIC"}},
+		{"no port protocols", []string{"--service-port-protocol-filter=,"}},
+		{"unknown service type", []string{"--service-types=ClusterIP,Headless"}},
+		{"internal probe without scheme", []string{"--ingress-internal-probe=ingress-nginx.ingress.svc"}},
+		{"internal probe with path", []string{"--ingress-internal-probe=http://ingress-nginx.ingress.svc/healthz"}},
+		{"internal probe over tcp", []string{"--ingress-internal-probe=tcp://ingress-nginx.ingress.svc:80"}},
+		{"negative kube qps", []string{"--kube-qps=-1"}},
+		{"negative output gid", []string{"--output-file-gid=-2"}},
+		{"empty guarded condition", []string{"--guarded-condition= "}},
+		{"merge without marker", []string{"--merge-existing", "--managed-by-label="}},
+		{"bad log format", []string{"--log-format=xml"}},
+		{"malformed alert profile", []string{"--alert-profile=critical"}},
 		{"unknown flag", []string{"--nope"}},
 	}
 	for _, tt := range cases {
@@ -144,3 +351,241 @@ func TestAnyExplicitlyEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestLoad_VersionSkipsValidation(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("test", []string{"--version", "--output="}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if !cfg.ShowVersion {
+		t.Error("ShowVersion = false")
+	}
+}
+
+func TestConfig_Effective(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("test", []string{
+		"--namespace=apps",
+		"--default-headers=Authorization: Bearer secret",
+		"--alert-profile=critical={type: pagerduty, integration-key: secret}",
+	}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	cfg.GatewayAPIVersion = "v1"
+
+	got := cfg.Effective()
+	for name, want := range map[string]string{
+		"namespace":           "apps",
+		"output":              DefaultOutputPath,
+		"gateway-api-version": "v1",
+		"default-headers":     "<redacted>",
+		"alert-profile":       "<redacted>",
+		"default-group":       "",
+	} {
+		if v, ok := got[name]; !ok || v != want {
+			t.Errorf("Effective()[%q] = %q (present %v), want %q", name, v, ok, want)
+		}
+	}
+	for name, v := range got {
+		if strings.Contains(v, "secret") {
+			t.Errorf("Effective()[%q] leaks a credential: %q", name, v)
+		}
+	}
+}
+
+func TestLoad_AnnotationPrefix(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("test", []string{"--annotation-prefix=team-a.example.com", "--annotation-enabled=custom/on"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if cfg.TemplateAnnotation != "team-a.example.com/endpoint" ||
+		cfg.MaintenanceAnnotation != "team-a.example.com/maintenance" ||
+		cfg.AlertsAnnotation != "team-a.example.com/alerts" {
+		t.Errorf("prefix not applied: %+v", cfg)
+	}
+	if cfg.EnabledAnnotation != "custom/on" {
+		t.Errorf("explicit flag overridden by prefix: %q", cfg.EnabledAnnotation)
+	}
+
+	for _, prefix := range []string{"", "a/b"} {
+		if _, err := Load("test", []string{"--annotation-prefix=" + prefix}, &bytes.Buffer{}); err == nil {
+			t.Errorf("expected error for prefix %q", prefix)
+		}
+	}
+}
+
+func TestLoad_OutputFileModeFallsBack(t *testing.T) {
+	t.Parallel()
+	for _, mode := range []string{"rw-r--r--", "0999", "1777"} {
+		cfg, err := Load("test", []string{"--output-file-mode=" + mode}, &bytes.Buffer{})
+		if err != nil {
+			t.Fatalf("Load(%q) returned error: %v", mode, err)
+		}
+		if cfg.OutputFileMode != DefaultOutputFileMode {
+			t.Errorf("OutputFileMode for %q = %o, want the default", mode, cfg.OutputFileMode)
+		}
+		if warnings := cfg.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "--output-file-mode") {
+			t.Errorf("Warnings() for %q = %v", mode, warnings)
+		}
+	}
+}
+
+func TestConfig_Warnings(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"defaults", nil, nil},
+		{"gateway name in annotation-only mode", []string{"--gateway-name=gw"}, nil},
+		{"gateway name with no default controllers", []string{"--gateway-name=gw", "--no-default-controllers"}, []string{"--gateway-name"}},
+		{"gateway name with httproute", []string{"--gateway-name=gw", "--enable-httproute"}, nil},
+		{"gateway name with gateway", []string{"--gateway-name=gw", "--enable-gateway"}, nil},
+		{"gateway name without either", []string{"--gateway-name=gw", "--enable-ingress"}, []string{"--gateway-name"}},
+		{"gateway namespace with httproute", []string{"--gateway-namespace=network", "--enable-httproute"}, nil},
+		{"gateway namespace without either", []string{"--gateway-namespace=network", "--enable-ingress"}, []string{"--gateway-namespace"}},
+		{"ingress class without ingress", []string{"--ingress-class=nginx", "--auto-service"}, []string{"--ingress-class"}},
+		{"ingress all hosts without ingress", []string{"--ingress-all-hosts", "--auto-service"}, []string{"--ingress-all-hosts"}},
+		{"ingress resolve backend without ingress", []string{"--ingress-resolve-backend", "--auto-service"}, []string{"--ingress-resolve-backend"}},
+		{"ready status without ingress or httproute", []string{"--require-ready-status", "--auto-service"}, []string{"--require-ready-status"}},
+		{"ready status with httproute", []string{"--require-ready-status", "--auto-httproute"}, nil},
+		{"all addresses without gateways", []string{"--gateway-all-addresses"}, []string{"--gateway-all-addresses has no effect"}},
+		{"all addresses with gateways", []string{"--gateway-all-addresses", "--auto-gateway"}, nil},
+		{"backends without service", []string{"--require-backends", "--auto-ingress"}, []string{"--require-backends has no effect"}},
+		{"backends with services", []string{"--require-backends"}, nil},
+		{"unsharded without shard", []string{"--include-unsharded"}, []string{"--include-unsharded has no effect"}},
+		{"unsharded with shard", []string{"--include-unsharded", "--shard=eu-west"}, nil},
+		{"nodeport probe without service", []string{"--service-nodeport-probe=auto", "--auto-ingress"}, []string{"--service-nodeport-probe"}},
+		{"target port without service", []string{"--service-use-target-port", "--auto-ingress"}, []string{"--service-use-target-port"}},
+		{"service types without service", []string{"--service-types=ClusterIP", "--auto-ingress"}, []string{"--service-types"}},
+		{"service fan-out without service", []string{"--service-all-ports", "--auto-ingress"}, []string{"--service-all-ports"}},
+		{"delete on exit with once", []string{"--delete-on-exit", "--once"}, []string{"--delete-on-exit"}},
+		{"debug API with once", []string{"--debug-addr=:8090", "--once"}, []string{"--debug-addr"}},
+		{"nothing can opt in", []string{"--annotation-config=", "--annotation-enabled="}, []string{"no --auto-*"}},
+		{"auto with no annotations", []string{"--annotation-config=", "--annotation-enabled=", "--auto-ingress"}, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := Load("test", tt.args, &bytes.Buffer{})
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			got := cfg.Warnings()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Warnings() = %q, want %d matching %q", got, len(tt.want), tt.want)
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(got[i], prefix) {
+					t.Errorf("warning %d = %q, want prefix %q", i, got[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_KindInterval(t *testing.T) {
+	t.Parallel()
+	cfg, err := Load("test", []string{"--default-interval=2m", "--service-interval=15s"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := cfg.Interval(KindService); got != 15*time.Second {
+		t.Errorf("service interval = %v, want 15s", got)
+	}
+	if got := cfg.Interval(KindIngress); got != 2*time.Minute {
+		t.Errorf("ingress interval = %v, want the 2m default", got)
+	}
+	if got := cfg.Interval("unknown"); got != 2*time.Minute {
+		t.Errorf("unknown kind interval = %v, want the 2m default", got)
+	}
+}
+
+func TestValidateResolver(t *testing.T) {
+	t.Parallel()
+	for _, ok := range []string{"", "1.1.1.1", "1.1.1.1:53", "dns.example.com", "dns.example.com:5353", "2606:4700::1111", "[2606:4700::1111]:53"} {
+		if err := validateResolver(ok); err != nil {
+			t.Errorf("validateResolver(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{":53", "1.1.1.1:0", "1.1.1.1:dns", "a:b:c"} {
+		if err := validateResolver(bad); err == nil {
+			t.Errorf("validateResolver(%q) should fail", bad)
+		}
+	}
+}
+
+func TestValidateClientResolver(t *testing.T) {
+	t.Parallel()
+	for _, ok := range []string{"", "tcp://8.8.8.8:53", "udp://10.96.0.10:53", "udp://dns.example.com:5353", "tcp://[2606:4700::1111]:53"} {
+		if err := validateClientResolver(ok); err != nil {
+			t.Errorf("validateClientResolver(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"8.8.8.8:53", "https://8.8.8.8:53", "udp://8.8.8.8", "udp://:53", "tcp://8.8.8.8:dns", "tcp://8.8.8.8:0"} {
+		if err := validateClientResolver(bad); err == nil {
+			t.Errorf("validateClientResolver(%q) should fail", bad)
+		}
+	}
+}
+
+func TestConfig_Disabled(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		ann  map[string]string
+		want bool
+	}{
+		{"absent", nil, false},
+		{"true", map[string]string{"enabled": "true"}, false},
+		{"True", map[string]string{"enabled": "True"}, false},
+		{"TRUE", map[string]string{"enabled": "TRUE"}, false},
+		{"one", map[string]string{"enabled": "1"}, false},
+		{"false", map[string]string{"enabled": "false"}, true},
+		{"zero", map[string]string{"enabled": "0"}, true},
+		{"empty", map[string]string{"enabled": ""}, true},
+		{"unparseable", map[string]string{"enabled": "yes"}, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{EnabledAnnotation: "enabled"}
+			if got := cfg.Disabled(tt.ann); got != tt.want {
+				t.Errorf("Disabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_InShard(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name      string
+		shard     string
+		unsharded bool
+		ann       map[string]string
+		want      bool
+	}{
+		{"sharding off", "", false, map[string]string{"shard": "us-east"}, true},
+		{"match", "eu-west", false, map[string]string{"shard": "eu-west"}, true},
+		{"match with spaces", "eu-west", false, map[string]string{"shard": " eu-west\n"}, true},
+		{"mismatch", "eu-west", false, map[string]string{"shard": "us-east"}, false},
+		{"case-sensitive", "eu-west", false, map[string]string{"shard": "EU-West"}, false},
+		{"unsharded", "eu-west", false, nil, false},
+		{"unsharded included", "eu-west", true, nil, true},
+		{"empty value is a shard name", "eu-west", true, map[string]string{"shard": ""}, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &Config{Shard: tt.shard, IncludeUnsharded: tt.unsharded, ShardAnnotation: "shard"}
+			if got := cfg.InShard(tt.ann); got != tt.want {
+				t.Errorf("InShard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
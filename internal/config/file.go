@@ -0,0 +1,98 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix starts the environment variable for each flag: upper-cased,
+// with dashes as underscores, so --default-interval is
+// GATUS_SIDECAR_DEFAULT_INTERVAL.
+const EnvPrefix = "GATUS_SIDECAR_"
+
+// configFileFlag names the flag (and its environment variable) pointing at
+// the YAML settings file.
+const configFileFlag = "config-file"
+
+// envName returns the environment variable that sets the named flag.
+func envName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// readConfigFile parses a YAML settings file keyed by flag name, without
+// the dashes:
+//
+//	default-interval: 30s
+//	ingress-class: [nginx, traefik]
+//
+// Repeatable flags take a list. Values are kept as written, so an octal
+// output-file-mode such as 0640 isn't reinterpreted as a YAML number.
+func readConfigFile(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	settings := make(map[string][]string, len(doc))
+	for key, node := range doc {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			if node.Tag != "!!null" {
+				settings[key] = []string{node.Value}
+			}
+		case yaml.SequenceNode:
+			values := make([]string, 0, len(node.Content))
+			for _, item := range node.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("%s: list items must be plain values", key)
+				}
+				values = append(values, item.Value)
+			}
+			settings[key] = values
+		default:
+			return nil, fmt.Errorf("%s: want a value or a list of values", key)
+		}
+	}
+	return settings, nil
+}
+
+// applySources sets each flag not given on the command line from the
+// environment or, failing that, the settings file, giving the precedence
+// defaults → file → environment → flags. Every value goes through the
+// flag's own parsing, so the sources can't disagree on syntax.
+func applySources(fs *flag.FlagSet, file map[string][]string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	for key := range file {
+		if key == configFileFlag || fs.Lookup(key) == nil {
+			return fmt.Errorf("--%s: unknown setting %q", configFileFlag, key)
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] || f.Name == configFileFlag {
+			return
+		}
+		values := file[f.Name]
+		source := "--" + configFileFlag
+		if v, ok := os.LookupEnv(envName(f.Name)); ok {
+			values, source = []string{v}, envName(f.Name)
+		}
+		for _, v := range values {
+			if setErr := fs.Set(f.Name, v); setErr != nil {
+				err = fmt.Errorf("%s: invalid value %q for %s: %w", source, v, f.Name, setErr)
+				return
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_ConfigFilePrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+default-interval: 2m
+resync-interval: 10m
+log-level: debug
+output-file-mode: 0640
+ingress-class: [nginx, traefik]
+auto-service: true
+`)
+	// The environment beats the file; flags beat both.
+	t.Setenv("GATUS_SIDECAR_RESYNC_INTERVAL", "20m")
+	t.Setenv("GATUS_SIDECAR_LOG_LEVEL", "warn")
+	cfg, err := Load("test", []string{"--config-file=" + path, "--log-level=error"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DefaultInterval != 2*time.Minute {
+		t.Errorf("DefaultInterval = %v, want 2m from the file", cfg.DefaultInterval)
+	}
+	if cfg.ResyncInterval != 20*time.Minute {
+		t.Errorf("ResyncInterval = %v, want 20m from the environment", cfg.ResyncInterval)
+	}
+	if cfg.LogLevel.String() != "ERROR" {
+		t.Errorf("LogLevel = %v, want error from the flag", cfg.LogLevel)
+	}
+	if cfg.OutputFileMode != 0o640 {
+		t.Errorf("OutputFileMode = %o, want 640 as written", cfg.OutputFileMode)
+	}
+	if !slices.Equal(cfg.IngressClasses, StringSet{"nginx", "traefik"}) || !cfg.Kinds[KindService].Auto {
+		t.Errorf("IngressClasses = %v, auto-service = %v", cfg.IngressClasses, cfg.Kinds[KindService].Auto)
+	}
+	if cfg.Output != DefaultOutputPath {
+		t.Errorf("Output = %q, want the default", cfg.Output)
+	}
+}
+
+func TestLoad_ConfigFileFromEnv(t *testing.T) {
+	t.Setenv("GATUS_SIDECAR_CONFIG_FILE", writeConfigFile(t, "namespace: apps\n"))
+	cfg, err := Load("test", nil, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Namespace != "apps" {
+		t.Errorf("Namespace = %q, want apps", cfg.Namespace)
+	}
+}
+
+func TestLoad_EnvWithoutConfigFile(t *testing.T) {
+	t.Setenv("GATUS_SIDECAR_AUTO_INGRESS", "true")
+	t.Setenv("GATUS_SIDECAR_DEFAULT_INTERVAL", "45s")
+	cfg, err := Load("test", []string{"--default-interval=15s"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !cfg.Kinds[KindIngress].Auto || cfg.DefaultInterval != 15*time.Second {
+		t.Errorf("auto-ingress = %v, DefaultInterval = %v", cfg.Kinds[KindIngress].Auto, cfg.DefaultInterval)
+	}
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	cases := []struct {
+		name, content, want string
+	}{
+		{"unknown setting", "no-such-flag: 1\n", `unknown setting "no-such-flag"`},
+		{"invalid value", "default-interval: soon\n", "default-interval"},
+		{"nested map", "alert-profile:\n  critical: {}\n", "want a value or a list"},
+		{"not yaml", "[", "--config-file"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load("test", []string{"--config-file=" + writeConfigFile(t, tt.content)}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := Load("test", []string{"--config-file=/nonexistent/config.yaml"}, &bytes.Buffer{}); err == nil {
+		t.Error("a missing config file should fail")
+	}
+	t.Setenv("GATUS_SIDECAR_DEFAULT_INTERVAL", "soon")
+	if _, err := Load("test", nil, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "GATUS_SIDECAR_DEFAULT_INTERVAL") {
+		t.Errorf("invalid env value error = %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GVR names a kind by API group, version and plural resource.
+type GVR struct {
+	Group, Version, Resource string
+	// ClusterScoped is set by startup discovery for kinds whose objects
+	// have no namespace, so their watch ignores --namespace.
+	ClusterScoped bool
+}
+
+func (g GVR) String() string {
+	if g.Group == "" {
+		return g.Version + "/" + g.Resource
+	}
+	return g.Group + "/" + g.Version + "/" + g.Resource
+}
+
+// GVRs is a [flag.Value] collecting one kind per flag occurrence, written
+// group/version/resource (version/resource for the core group), such as
+// `--watch-gvr example.com/v1/websites`.
+type GVRs []GVR
+
+func (g *GVRs) String() string {
+	if g == nil {
+		return ""
+	}
+	names := make([]string, 0, len(*g))
+	for _, gvr := range *g {
+		names = append(names, gvr.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Set rejects a second kind with the same resource name, since endpoint
+// keys tell kinds apart by resource alone.
+func (g *GVRs) Set(v string) error {
+	var gvr GVR
+	switch parts := strings.Split(v, "/"); len(parts) {
+	case 2:
+		gvr = GVR{Version: parts[0], Resource: parts[1]}
+	case 3:
+		gvr = GVR{Group: parts[0], Version: parts[1], Resource: parts[2]}
+	default:
+		return fmt.Errorf("want group/version/resource, got %q", v)
+	}
+	if gvr.Version == "" || gvr.Resource == "" {
+		return fmt.Errorf("want group/version/resource, got %q", v)
+	}
+	if gvr.Resource != strings.ToLower(gvr.Resource) {
+		return fmt.Errorf("resource must be the lower-case plural, such as websites (got %q)", gvr.Resource)
+	}
+	if i := slices.IndexFunc(*g, func(o GVR) bool { return o.Resource == gvr.Resource }); i >= 0 {
+		if (*g)[i] == gvr {
+			return nil
+		}
+		return fmt.Errorf("resource %q is already watched as %s", gvr.Resource, (*g)[i])
+	}
+	*g = append(*g, gvr)
+	return nil
+}
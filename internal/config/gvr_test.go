@@ -0,0 +1,43 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestGVRs_Set(t *testing.T) {
+	t.Parallel()
+	var g GVRs
+	fs := flag.NewFlagSet("t", flag.ContinueOnError)
+	fs.Var(&g, "watch-gvr", "")
+	err := fs.Parse([]string{
+		"--watch-gvr=example.com/v1/websites",
+		"--watch-gvr=v1/configmaps",
+		"--watch-gvr=example.com/v1/websites",
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := GVRs{{Group: "example.com", Version: "v1", Resource: "websites"}, {Version: "v1", Resource: "configmaps"}}
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("got %v, want %v", g, want)
+	}
+	if got := g.String(); got != "example.com/v1/websites,v1/configmaps" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestGVRs_SetRejects(t *testing.T) {
+	t.Parallel()
+	for _, v := range []string{"websites", "example.com/v1/websites/extra", "example.com//websites", "example.com/v1/Website"} {
+		var g GVRs
+		if err := g.Set(v); err == nil {
+			t.Errorf("Set(%q) = nil, want error", v)
+		}
+	}
+	g := GVRs{{Group: "example.com", Version: "v1", Resource: "websites"}}
+	if err := g.Set("other.io/v1/websites"); err == nil {
+		t.Error("Set accepted a second kind with the same resource name")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Headers is a [flag.Value] collecting HTTP request headers from
+// comma-separated `Name: value` pairs, such as
+// `--default-headers 'Authorization: Bearer x,X-Probe: gatus'`. The flag may
+// be repeated; a later value for the same name replaces the earlier one.
+type Headers map[string]string
+
+func (h *Headers) String() string {
+	if h == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*h))
+	for _, name := range slices.Sorted(maps.Keys(*h)) {
+		pairs = append(pairs, name+": "+(*h)[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h *Headers) Set(v string) error {
+	for pair := range strings.SplitSeq(v, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return fmt.Errorf("want Name: value, got %q", pair)
+		}
+		if *h == nil {
+			*h = make(Headers)
+		}
+		(*h)[name] = strings.TrimSpace(value)
+	}
+	return nil
+}
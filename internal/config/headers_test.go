@@ -0,0 +1,38 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestHeaders_Set(t *testing.T) {
+	t.Parallel()
+	var h Headers
+	fs := flag.NewFlagSet("t", flag.ContinueOnError)
+	fs.Var(&h, "default-headers", "")
+	err := fs.Parse([]string{
+		"--default-headers=Authorization: Bearer x, X-Probe: gatus",
+		"--default-headers=X-Probe:sidecar,",
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := Headers{"Authorization": "Bearer x", "X-Probe": "sidecar"}
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("got %v, want %v", h, want)
+	}
+	if got := h.String(); got != "Authorization: Bearer x,X-Probe: sidecar" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestHeaders_SetRejects(t *testing.T) {
+	t.Parallel()
+	for _, v := range []string{"Authorization", ": value", "X Probe: gatus", "X-Probe: a,broken"} {
+		var h Headers
+		if err := h.Set(v); err == nil {
+			t.Errorf("Set(%q) = nil, want error", v)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// literalHost matches a rewrite pattern made only of hostname characters,
+// which is compared as-is rather than compiled.
+var literalHost = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// HostRewrite replaces an extracted hostname before it is probed.
+type HostRewrite struct {
+	from, to string
+	// pattern is nil for a literal from.
+	pattern *regexp.Regexp
+}
+
+func (r HostRewrite) String() string { return r.from + "=" + r.to }
+
+// HostRewrites is a [flag.Value] collecting one `from=to` rule per flag
+// occurrence, such as `--hostname-rewrite www.example.com=example.com`. A
+// from made only of hostname characters matches literally, ignoring case;
+// anything else is a regular expression that must match the whole host,
+// with $1 and friends expanded in to.
+type HostRewrites []HostRewrite
+
+func (h *HostRewrites) String() string {
+	if h == nil {
+		return ""
+	}
+	rules := make([]string, 0, len(*h))
+	for _, r := range *h {
+		rules = append(rules, r.String())
+	}
+	return strings.Join(rules, ",")
+}
+
+// Set splits on the last "=", since hostnames can't contain one.
+func (h *HostRewrites) Set(v string) error {
+	i := strings.LastIndex(v, "=")
+	if i <= 0 || i == len(v)-1 {
+		return fmt.Errorf("want from=to, got %q", v)
+	}
+	r := HostRewrite{from: v[:i], to: v[i+1:]}
+	if !literalHost.MatchString(r.from) {
+		pattern, err := regexp.Compile("^(?:" + r.from + ")$")
+		if err != nil {
+			return err
+		}
+		r.pattern = pattern
+	}
+	*h = append(*h, r)
+	return nil
+}
+
+// Rewrite applies the first rule matching host, returning host unchanged
+// when none does.
+func (h HostRewrites) Rewrite(host string) string {
+	for _, r := range h {
+		switch {
+		case r.pattern == nil && strings.EqualFold(r.from, host):
+			return r.to
+		case r.pattern != nil && r.pattern.MatchString(host):
+			return r.pattern.ReplaceAllString(host, r.to)
+		}
+	}
+	return host
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestHostRewrites(t *testing.T) {
+	t.Parallel()
+	var h HostRewrites
+	fs := flag.NewFlagSet("t", flag.ContinueOnError)
+	fs.Var(&h, "hostname-rewrite", "")
+	err := fs.Parse([]string{
+		"--hostname-rewrite=www.example.com=example.com",
+		`--hostname-rewrite=(.+)\.example\.org=$1.internal.example.org`,
+		"--hostname-rewrite=example.com=never.example.com",
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cases := []struct{ host, want string }{
+		{"www.example.com", "example.com"},
+		{"WWW.Example.com", "example.com"},
+		{"wwwxexample.com", "wwwxexample.com"},
+		{"api.example.org", "api.internal.example.org"},
+		{"example.org", "example.org"},
+		{"example.com", "never.example.com"},
+		{"other.example.net", "other.example.net"},
+	}
+	for _, tt := range cases {
+		if got := h.Rewrite(tt.host); got != tt.want {
+			t.Errorf("Rewrite(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+	if got := h.String(); got != `www.example.com=example.com,(.+)\.example\.org=$1.internal.example.org,example.com=never.example.com` {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestHostRewrites_SetRejects(t *testing.T) {
+	t.Parallel()
+	for _, v := range []string{"example.com", "=example.com", "www.example.com=", "(unclosed=example.com"} {
+		var h HostRewrites
+		if err := h.Set(v); err == nil {
+			t.Errorf("Set(%q) = nil, want error", v)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maintenanceKeys are the fields Gatus accepts in an endpoint's
+// maintenance-windows entries.
+var maintenanceKeys = []string{"start", "duration", "timezone", "every"}
+
+// ParseMaintenanceWindows parses a list of Gatus maintenance windows, or a
+// single window mapping. Each needs a "start" (HH:MM) and a positive
+// "duration"; "timezone" and "every" are optional and passed through.
+func ParseMaintenanceWindows(data []byte) ([]any, error) {
+	var parsed any
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	var windows []any
+	switch w := parsed.(type) {
+	case []any:
+		windows = w
+	case map[string]any:
+		windows = []any{w}
+	default:
+		return nil, errors.New("want a list of maintenance windows or a single window mapping")
+	}
+	for i, window := range windows {
+		if err := validateMaintenanceWindow(window); err != nil {
+			return nil, fmt.Errorf("window %d: %w", i, err)
+		}
+	}
+	return windows, nil
+}
+
+func validateMaintenanceWindow(window any) error {
+	m, ok := window.(map[string]any)
+	if !ok {
+		return errors.New("not a mapping")
+	}
+	for key := range m {
+		if !slices.Contains(maintenanceKeys, key) {
+			return fmt.Errorf("unknown key %q", key)
+		}
+	}
+	start, _ := m["start"].(string)
+	if _, err := time.Parse("15:04", start); err != nil {
+		return fmt.Errorf("start must be HH:MM (got %v)", m["start"])
+	}
+	duration, _ := m["duration"].(string)
+	if d, err := time.ParseDuration(duration); err != nil || d <= 0 {
+		return fmt.Errorf("duration must be a positive Go duration (got %v)", m["duration"])
+	}
+	if tz, ok := m["timezone"]; ok {
+		if _, isString := tz.(string); !isString {
+			return fmt.Errorf("timezone must be a string (got %v)", tz)
+		}
+	}
+	return nil
+}
+
+func loadMaintenanceFile(path string) ([]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMaintenanceWindows(data)
+}
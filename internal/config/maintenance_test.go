@@ -0,0 +1,76 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMaintenanceWindows(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		in      string
+		want    []any
+		wantErr bool
+	}{
+		{
+			name: "single mapping",
+			in:   "start: \"02:00\"\nduration: 1h\ntimezone: Europe/Amsterdam\n",
+			want: []any{map[string]any{"start": "02:00", "duration": "1h", "timezone": "Europe/Amsterdam"}},
+		},
+		{
+			name: "list with every",
+			in:   "- start: \"23:30\"\n  duration: 30m\n  every: [Saturday, Sunday]\n",
+			want: []any{map[string]any{"start": "23:30", "duration": "30m", "every": []any{"Saturday", "Sunday"}}},
+		},
+		{name: "missing start", in: "duration: 1h\n", wantErr: true},
+		{name: "bad start", in: "start: \"25:00\"\nduration: 1h\n", wantErr: true},
+		{name: "missing duration", in: "start: \"02:00\"\n", wantErr: true},
+		{name: "zero duration", in: "start: \"02:00\"\nduration: 0s\n", wantErr: true},
+		{name: "unknown key", in: "start: \"02:00\"\nduration: 1h\nlength: 2h\n", wantErr: true},
+		{name: "non-string timezone", in: "start: \"02:00\"\nduration: 1h\ntimezone: 5\n", wantErr: true},
+		{name: "scalar", in: "nightly", wantErr: true},
+		{name: "list of scalars", in: "[nightly]", wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseMaintenanceWindows([]byte(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_DefaultMaintenanceFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.yaml")
+	if err := os.WriteFile(good, []byte("- start: \"03:00\"\n  duration: 2h\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("start: noon\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load("test", []string{"--default-maintenance-file=" + good}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.DefaultMaintenance) != 1 {
+		t.Errorf("DefaultMaintenance = %v", cfg.DefaultMaintenance)
+	}
+	for _, path := range []string{bad, filepath.Join(dir, "missing.yaml")} {
+		if _, err := Load("test", []string{"--default-maintenance-file=" + path}, &bytes.Buffer{}); err == nil {
+			t.Errorf("Load with %s should fail", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package debug serves the sidecar's live state over HTTP for
+// troubleshooting: the endpoints it currently generates and the
+// configuration it runs with.
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+)
+
+// redacted replaces credential values in responses.
+const redacted = "<redacted>"
+
+// shutdownTimeout bounds how long Serve waits for in-flight requests once
+// its context is done.
+const shutdownTimeout = 5 * time.Second
+
+// Handler serves GET /endpoints, the writer's current endpoints keyed by
+// writer key, and GET /config, the effective flag values. Request headers
+// and external-endpoint tokens are redacted, as are credential-bearing
+// flags (see [config.Config.Effective]).
+func Handler(cfg *config.Config, writer *gatus.Writer) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /endpoints", func(w http.ResponseWriter, _ *http.Request) {
+		snap, err := writer.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, e := range snap.Endpoints {
+			redactHeaders(e)
+		}
+		for _, e := range snap.ExternalEndpoints {
+			if _, ok := e["token"]; ok {
+				e["token"] = redacted
+			}
+		}
+		writeJSON(w, snap)
+	})
+	mux.HandleFunc("GET /config", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, cfg.Effective())
+	})
+	return mux
+}
+
+// Serve runs the debug API on addr until ctx is done. It returns nil after
+// a clean shutdown and the listener's error otherwise.
+func Serve(ctx context.Context, addr string, h http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: h, ReadHeaderTimeout: shutdownTimeout}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("serving debug API", "addr", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-stopped
+	return nil
+}
+
+// redactHeaders masks every request header value of a rendered endpoint;
+// --default-headers and templates commonly carry Authorization there.
+func redactHeaders(e map[string]any) {
+	headers, ok := e["headers"].(map[string]any)
+	if !ok {
+		return
+	}
+	for name := range headers {
+		headers[name] = redacted
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Debug("debug API: writing response failed", "error", err)
+	}
+}
@@ -0,0 +1,141 @@
+package debug
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+)
+
+func newHandler(t *testing.T, args ...string) (http.Handler, *gatus.Writer) {
+	t.Helper()
+	cfg, err := config.Load("test", args, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	w := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	return Handler(cfg, w), w
+}
+
+func get(t *testing.T, h http.Handler, path string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d: %s", path, rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s Content-Type = %q", path, ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("GET %s: decode: %v\n%s", path, err, rec.Body)
+	}
+	return rec
+}
+
+func TestHandler_Endpoints(t *testing.T) {
+	t.Parallel()
+	h, w := newHandler(t)
+	web := &gatus.Endpoint{Name: "web", URL: "https://web", Interval: "1m"}
+	web.ApplyTemplate(map[string]any{"headers": map[string]any{"Authorization": "Bearer secret"}})
+	if _, err := w.Upsert("ing/web", web, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if _, err := w.UpsertExternal("svc/db", &gatus.ExternalEndpoint{Name: "db", Token: "secret"}, false); err != nil {
+		t.Fatalf("UpsertExternal: %v", err)
+	}
+
+	var snap gatus.Snapshot
+	rec := get(t, h, "/endpoints", &snap)
+	if got := snap.Endpoints["ing/web"]["url"]; got != "https://web" {
+		t.Errorf("endpoints[ing/web].url = %v", got)
+	}
+	if got := snap.ExternalEndpoints["svc/db"]["name"]; got != "db" {
+		t.Errorf("external-endpoints[svc/db].name = %v", got)
+	}
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("response leaks a credential:\n%s", rec.Body)
+	}
+}
+
+func TestHandler_Config(t *testing.T) {
+	t.Parallel()
+	h, _ := newHandler(t, "--namespace=apps", "--default-headers=Authorization: Bearer secret")
+
+	var effective map[string]string
+	rec := get(t, h, "/config", &effective)
+	if effective["namespace"] != "apps" {
+		t.Errorf("namespace = %q", effective["namespace"])
+	}
+	if strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("response leaks a credential:\n%s", rec.Body)
+	}
+}
+
+func TestHandler_RejectsOtherRequests(t *testing.T) {
+	t.Parallel()
+	h, _ := newHandler(t)
+	for _, tt := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/endpoints", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/", http.StatusNotFound},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestServe_StopsWithContext(t *testing.T) {
+	t.Parallel()
+	// Reserve a free port, then hand it to Serve.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	h, _ := newHandler(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, addr, h) }()
+
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get("http://" + addr + "/config"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET /config: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /config = %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve = %v, want nil after shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after cancel")
+	}
+}
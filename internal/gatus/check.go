@@ -0,0 +1,72 @@
+package gatus
+
+import (
+	"strings"
+	"time"
+)
+
+// Check types selectable with the check annotation. Each names the Gatus
+// probe an endpoint's URL is rewritten to.
+const (
+	CheckHTTP = "http"
+	CheckTCP  = "tcp"
+	CheckICMP = "icmp"
+	CheckDNS  = "dns"
+	CheckTLS  = "tls"
+	CheckWS   = "ws"
+
+	// ConnectedCondition passes when the probe reached the host at all.
+	ConnectedCondition = "[CONNECTED] == true"
+	// StatusOKCondition is the default HTTP success condition.
+	StatusOKCondition = "[STATUS] == 200"
+)
+
+// checkConditions are the default conditions for each check type.
+var checkConditions = map[string][]string{
+	CheckHTTP: {StatusOKCondition},
+	CheckTCP:  {ConnectedCondition},
+	CheckICMP: {ConnectedCondition},
+	CheckDNS:  {DNSNoErrorCondition},
+	CheckTLS:  {ConnectedCondition},
+	CheckWS:   {ConnectedCondition},
+}
+
+// checkAliases are alternative spellings of check types.
+var checkAliases = map[string]string{
+	"websocket": CheckWS,
+}
+
+// ParseCheck normalizes a check annotation value, resolving aliases such as
+// "websocket", and reports whether it names a known check type.
+func ParseCheck(raw string) (string, bool) {
+	check := strings.ToLower(strings.TrimSpace(raw))
+	if alias, ok := checkAliases[check]; ok {
+		check = alias
+	}
+	_, known := checkConditions[check]
+	return check, known
+}
+
+// CheckConditions returns the default conditions for check and whether it
+// is a known check type.
+func CheckConditions(check string) ([]string, bool) {
+	conds, ok := checkConditions[check]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), conds...), true
+}
+
+// CertificateExpirationCondition passes while the endpoint's certificate
+// stays valid for longer than minValidity.
+func CertificateExpirationCondition(minValidity time.Duration) string {
+	// Drop zero trailing units so 168h0m0s reads as 168h.
+	d := minValidity.String()
+	if strings.HasSuffix(d, "m0s") {
+		d = strings.TrimSuffix(d, "0s")
+	}
+	if strings.HasSuffix(d, "h0m") {
+		d = strings.TrimSuffix(d, "0m")
+	}
+	return "[CERTIFICATE_EXPIRATION] > " + d
+}
@@ -0,0 +1,54 @@
+package gatus
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheckConditions(t *testing.T) {
+	for _, check := range []string{CheckHTTP, CheckTCP, CheckICMP, CheckDNS, CheckTLS, CheckWS} {
+		if conds, ok := CheckConditions(check); !ok || len(conds) == 0 {
+			t.Errorf("%s: got %v, %v", check, conds, ok)
+		}
+	}
+	if _, ok := CheckConditions("ping"); ok {
+		t.Error("unknown check type should not be known")
+	}
+	conds, _ := CheckConditions(CheckICMP)
+	conds[0] = "mutated"
+	if again, _ := CheckConditions(CheckICMP); !slices.Equal(again, []string{ConnectedCondition}) {
+		t.Errorf("CheckConditions must return a copy, got %v", again)
+	}
+}
+
+func TestParseCheck(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		known     bool
+	}{
+		{"icmp", CheckICMP, true},
+		{" TCP ", CheckTCP, true},
+		{"websocket", CheckWS, true},
+		{"WebSocket", CheckWS, true},
+		{"ping", "ping", false},
+	}
+	for _, tt := range tests {
+		if got, known := ParseCheck(tt.raw); got != tt.want || known != tt.known {
+			t.Errorf("ParseCheck(%q) = %q, %v; want %q, %v", tt.raw, got, known, tt.want, tt.known)
+		}
+	}
+}
+
+func TestCertificateExpirationCondition(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		7 * 24 * time.Hour: "[CERTIFICATE_EXPIRATION] > 168h",
+		90 * time.Minute:   "[CERTIFICATE_EXPIRATION] > 1h30m",
+		time.Minute:        "[CERTIFICATE_EXPIRATION] > 1m",
+		30 * time.Second:   "[CERTIFICATE_EXPIRATION] > 30s",
+	} {
+		if got := CertificateExpirationCondition(d); got != want {
+			t.Errorf("CertificateExpirationCondition(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -1,23 +1,49 @@
 package gatus
 
 // Guarded probes replace a direct HTTP check with a DNS query to a public
-// resolver (Cloudflare). Used when the sidecar pod can't reach the service
-// directly but DNS resolution is still meaningful.
+// resolver (Cloudflare unless --dns-resolver says otherwise). Used when the
+// sidecar pod can't reach the service directly but DNS resolution is still
+// meaningful.
 const (
+	// DNSNoErrorCondition passes when the server answered the query.
+	DNSNoErrorCondition = "[DNS_RCODE] == NOERROR"
+
 	GuardedProbeURL           = "1.1.1.1"
 	GuardedQueryType          = "A"
 	GuardedEmptyBodyCondition = "len([BODY]) == 0"
 )
 
-// ApplyGuardedDNS rewrites e in place to perform a DNS lookup of host.
-func ApplyGuardedDNS(host string, e *Endpoint) {
+// ApplyGuardedDNS rewrites e in place to ask server ([GuardedProbeURL] when
+// empty) for host, succeeding on condition, or on
+// [GuardedEmptyBodyCondition] when that's empty.
+func ApplyGuardedDNS(server, host, condition string, e *Endpoint) {
 	if host == "" || e == nil {
 		return
 	}
-	e.URL = GuardedProbeURL
+	if server == "" {
+		server = GuardedProbeURL
+	}
+	e.URL = server
 	e.DNS = map[string]any{
 		"query-name": host,
 		"query-type": GuardedQueryType,
 	}
-	e.Conditions = []string{GuardedEmptyBodyCondition}
+	if condition == "" {
+		condition = GuardedEmptyBodyCondition
+	}
+	e.Conditions = []string{condition}
+}
+
+// ApplyDNSCheck rewrites e in place to query server for an A record of
+// name, passing when the server answers without error.
+func ApplyDNSCheck(server, name string, e *Endpoint) {
+	if server == "" || e == nil {
+		return
+	}
+	e.URL = server
+	e.DNS = map[string]any{
+		"query-name": name,
+		"query-type": GuardedQueryType,
+	}
+	e.Conditions = []string{DNSNoErrorCondition}
 }
@@ -7,7 +7,7 @@ func TestApplyGuardedDNS(t *testing.T) {
 	t.Run("populates fields", func(t *testing.T) {
 		t.Parallel()
 		e := &Endpoint{}
-		ApplyGuardedDNS("example.com", e)
+		ApplyGuardedDNS("", "example.com", "", e)
 		if e.URL != GuardedProbeURL {
 			t.Errorf("URL = %q, want %q", e.URL, GuardedProbeURL)
 		}
@@ -19,10 +19,28 @@ func TestApplyGuardedDNS(t *testing.T) {
 		}
 	})
 
+	t.Run("custom condition", func(t *testing.T) {
+		t.Parallel()
+		e := &Endpoint{}
+		ApplyGuardedDNS("", "example.com", "[DNS_RCODE] == NXDOMAIN", e)
+		if len(e.Conditions) != 1 || e.Conditions[0] != "[DNS_RCODE] == NXDOMAIN" {
+			t.Errorf("Conditions = %v", e.Conditions)
+		}
+	})
+
+	t.Run("custom server", func(t *testing.T) {
+		t.Parallel()
+		e := &Endpoint{}
+		ApplyGuardedDNS("9.9.9.9:53", "example.com", "", e)
+		if e.URL != "9.9.9.9:53" || e.DNS["query-name"] != "example.com" {
+			t.Errorf("got %+v", e)
+		}
+	})
+
 	t.Run("empty host is no-op", func(t *testing.T) {
 		t.Parallel()
 		e := &Endpoint{}
-		ApplyGuardedDNS("", e)
+		ApplyGuardedDNS("", "", "", e)
 		if e.URL != "" || e.DNS != nil || e.Conditions != nil {
 			t.Errorf("ApplyGuardedDNS with empty host should not mutate: %+v", e)
 		}
@@ -31,6 +49,21 @@ func TestApplyGuardedDNS(t *testing.T) {
 	t.Run("nil endpoint is no-op", func(t *testing.T) {
 		t.Parallel()
 		// just verify it doesn't panic
-		ApplyGuardedDNS("example.com", nil)
+		ApplyGuardedDNS("", "example.com", "", nil)
 	})
 }
+
+func TestApplyDNSCheck(t *testing.T) {
+	t.Parallel()
+	e := &Endpoint{URL: "udp://dns.kube-system.svc:53", Conditions: []string{"[CONNECTED] == true"}}
+	ApplyDNSCheck("10.96.0.10", "kubernetes.default.svc.cluster.local", e)
+	if e.URL != "10.96.0.10" {
+		t.Errorf("URL = %q", e.URL)
+	}
+	if e.DNS["query-name"] != "kubernetes.default.svc.cluster.local" || e.DNS["query-type"] != "A" {
+		t.Errorf("DNS = %v", e.DNS)
+	}
+	if len(e.Conditions) != 1 || e.Conditions[0] != DNSNoErrorCondition {
+		t.Errorf("Conditions = %v", e.Conditions)
+	}
+}
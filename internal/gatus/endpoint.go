@@ -1,7 +1,11 @@
 // Package gatus models Gatus configuration objects (endpoints, templates, writer).
 package gatus
 
-import "maps"
+import (
+	"maps"
+	"slices"
+	"strings"
+)
 
 // Endpoint is a Gatus monitored endpoint. Extra holds template fields with no
 // first-class representation and is inlined into the YAML output.
@@ -14,6 +18,7 @@ type Endpoint struct {
 	DNS        map[string]any `yaml:"dns,omitempty"`
 	Client     map[string]any `yaml:"client,omitempty"`
 	UI         map[string]any `yaml:"ui,omitempty"`
+	ManagedBy  string         `yaml:"managed-by,omitempty"`
 	Extra      map[string]any `yaml:",inline,omitempty"`
 }
 
@@ -40,6 +45,8 @@ func (e *Endpoint) ApplyTemplate(data map[string]any) {
 			mergeMap(&e.Client, value)
 		case "ui":
 			mergeMap(&e.UI, value)
+		case ManagedByKey:
+			assignString(&e.ManagedBy, value)
 		case "guarded", "path":
 			// consumed by the controller; never serialized
 		default:
@@ -48,6 +55,112 @@ func (e *Endpoint) ApplyTemplate(data map[string]any) {
 	}
 }
 
+// SetClientOption sets one key of the endpoint's client block.
+func (e *Endpoint) SetClientOption(key string, value any) {
+	if e.Client == nil {
+		e.Client = make(map[string]any)
+	}
+	e.Client[key] = value
+}
+
+// SetUIOption sets one key of the endpoint's ui block.
+func (e *Endpoint) SetUIOption(key string, value any) {
+	if e.UI == nil {
+		e.UI = make(map[string]any)
+	}
+	e.UI[key] = value
+}
+
+// AppendConditions adds conditions to the endpoint's, skipping any it
+// already has.
+func (e *Endpoint) AppendConditions(conditions []string) {
+	for _, c := range conditions {
+		if !slices.Contains(e.Conditions, c) {
+			e.Conditions = append(e.Conditions, c)
+		}
+	}
+}
+
+// TemplateConditions returns the conditions a template sets, or nil.
+func TemplateConditions(data map[string]any) []string {
+	return toStringSlice(data["conditions"])
+}
+
+// BodyConditions expands a comma-separated list of response body checks
+// such as `.status == "ok"` into "[BODY]" conditions. Commas inside double
+// quotes, or escaped as `\,`, don't split; checks already starting with
+// "[BODY]" are kept as they are.
+func BodyConditions(raw string) []string {
+	var (
+		out     []string
+		current strings.Builder
+		quoted  bool
+	)
+	add := func() {
+		check := strings.TrimSpace(current.String())
+		current.Reset()
+		switch {
+		case check == "":
+		case strings.HasPrefix(check, "[BODY]"):
+			out = append(out, check)
+		case strings.HasPrefix(check, "."), strings.HasPrefix(check, "["):
+			out = append(out, "[BODY]"+check)
+		default:
+			out = append(out, "[BODY] "+check)
+		}
+	}
+	for i := 0; i < len(raw); i++ {
+		switch ch := raw[i]; {
+		case ch == '\\' && i+1 < len(raw) && raw[i+1] == ',':
+			current.WriteByte(',')
+			i++
+		case ch == '\\' && i+1 < len(raw):
+			current.WriteByte(ch)
+			current.WriteByte(raw[i+1])
+			i++
+		case ch == '"':
+			quoted = !quoted
+			current.WriteByte(ch)
+		case ch == ',' && !quoted:
+			add()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	add()
+	return out
+}
+
+// SetAlerts sets the endpoint's alerts list. Alerts have no typed field, so
+// they live in Extra where a template's own "alerts" replaces them.
+func (e *Endpoint) SetAlerts(alerts []any) {
+	e.setExtra("alerts", alerts)
+}
+
+// SetMaintenanceWindows sets the endpoint's maintenance-windows list, which
+// like alerts lives in Extra.
+func (e *Endpoint) SetMaintenanceWindows(windows []any) {
+	e.setExtra("maintenance-windows", windows)
+}
+
+// SetHeaders sets the endpoint's request headers. Like alerts they live in
+// Extra, so a template's own "headers" replaces them.
+func (e *Endpoint) SetHeaders(headers map[string]string) {
+	e.setExtra("headers", maps.Clone(headers))
+}
+
+// SetRequest sets the endpoint's HTTP method and request body, skipping
+// empty values. Both live in Extra, where a template's own keys replace
+// them.
+func (e *Endpoint) SetRequest(method, body string) {
+	if method != "" {
+		e.setExtra("method", method)
+	}
+	if body != "" {
+		e.setExtra("body", body)
+	}
+}
+
 func (e *Endpoint) setExtra(key string, value any) {
 	if e.Extra == nil {
 		e.Extra = make(map[string]any)
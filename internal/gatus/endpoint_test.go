@@ -1,8 +1,12 @@
 package gatus
 
 import (
+	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestEndpoint_ApplyTemplate(t *testing.T) {
@@ -60,6 +64,12 @@ func TestEndpoint_ApplyTemplate(t *testing.T) {
 			tmpl: map[string]any{"alerts": []any{"slack"}},
 			want: &Endpoint{Name: "a", URL: "x", Interval: "1m", Extra: map[string]any{"alerts": []any{"slack"}}},
 		},
+		{
+			name: "managed-by lands in its typed field",
+			in:   &Endpoint{Name: "a", URL: "x", Interval: "1m"},
+			tmpl: map[string]any{"managed-by": "team-a"},
+			want: &Endpoint{Name: "a", URL: "x", Interval: "1m", ManagedBy: "team-a"},
+		},
 		{
 			name: "ignore wrong type for string field",
 			in:   &Endpoint{Name: "a", URL: "x", Interval: "1m"},
@@ -106,3 +116,69 @@ func TestToStringSlice_NilForUnknown(t *testing.T) {
 		t.Errorf("toStringSlice(12345) = %v, want nil", got)
 	}
 }
+
+func TestEndpoint_AppendConditionsDedupes(t *testing.T) {
+	t.Parallel()
+	e := &Endpoint{Conditions: []string{"[STATUS] == 200"}}
+	e.AppendConditions([]string{"[STATUS] == 200", "[RESPONSE_TIME] < 500", "[RESPONSE_TIME] < 500"})
+	want := []string{"[STATUS] == 200", "[RESPONSE_TIME] < 500"}
+	if !reflect.DeepEqual(e.Conditions, want) {
+		t.Errorf("Conditions = %v, want %v", e.Conditions, want)
+	}
+}
+
+func TestBodyConditions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		raw  string
+		want []string
+	}{
+		{"", nil},
+		{`.status == "ok"`, []string{`[BODY].status == "ok"`}},
+		{`.status == "ok", .db.up == true`, []string{`[BODY].status == "ok"`, `[BODY].db.up == true`}},
+		{`.msg == "a,b"`, []string{`[BODY].msg == "a,b"`}},
+		{`.msg == pat(*a\,b*)`, []string{`[BODY].msg == pat(*a,b*)`}},
+		{`[BODY].ready == true,,`, []string{`[BODY].ready == true`}},
+		{`== pat(*healthy*)`, []string{`[BODY] == pat(*healthy*)`}},
+		{`[0].id == 1`, []string{`[BODY][0].id == 1`}},
+	}
+	for _, tt := range cases {
+		if got := BodyConditions(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BodyConditions(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+// Extra is an inlined map; the YAML encoder must sort its keys (and those of
+// nested maps) so an unchanged endpoint always produces the same bytes.
+func TestEndpoint_MarshalIsDeterministic(t *testing.T) {
+	t.Parallel()
+	build := func(keys []string) *Endpoint {
+		e := &Endpoint{Name: "a", URL: "https://a.example.com", Interval: "1m"}
+		for _, k := range keys {
+			e.ApplyTemplate(map[string]any{k: map[string]any{"z": 1, "a": 2, "m": 3}})
+		}
+		return e
+	}
+	keys := []string{"ui-extra", "alerts", "maintenance-windows", "storage", "extra-labels", "body", "headers"}
+	first, err := yaml.Marshal(build(keys))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	for range 20 {
+		reversed := make([]string, len(keys))
+		for i, k := range keys {
+			reversed[len(keys)-1-i] = k
+		}
+		again, err := yaml.Marshal(build(reversed))
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		if !bytes.Equal(first, again) {
+			t.Fatalf("output differs between marshals:\n%s\n---\n%s", first, again)
+		}
+	}
+	if i, j := strings.Index(string(first), "alerts:"), strings.Index(string(first), "ui-extra:"); i < 0 || j < i {
+		t.Errorf("Extra keys not sorted:\n%s", first)
+	}
+}
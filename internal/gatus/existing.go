@@ -0,0 +1,78 @@
+package gatus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManagedByKey marks an endpoint as written by the sidecar, so a later
+// --merge-existing load and downstream tooling can tell it apart from
+// hand-written entries.
+const (
+	ManagedByKey     = "managed-by"
+	DefaultManagedBy = "gatus-sidecar"
+)
+
+// preservedEndpoint is a hand-written endpoint carried over verbatim from
+// the output file. The node keeps the user's key order and comments.
+type preservedEndpoint struct {
+	name string
+	node *yaml.Node
+}
+
+// readUnmanaged returns the endpoints in the file at path whose managed-by
+// value isn't marker. A missing or empty file yields none.
+func readUnmanaged(path, marker string) ([]preservedEndpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read existing output: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode existing output: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	list := mappingValue(doc.Content[0], "endpoints")
+	if list == nil || list.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+
+	var out []preservedEndpoint
+	for _, item := range list.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		if by := mappingValue(item, ManagedByKey); by != nil && by.Value == marker {
+			continue
+		}
+		var name string
+		if n := mappingValue(item, "name"); n != nil {
+			name = n.Value
+		}
+		out = append(out, preservedEndpoint{name: name, node: item})
+	}
+	return out, nil
+}
+
+// mappingValue returns the value node stored under key in a mapping node,
+// or nil when node isn't a mapping or lacks the key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package gatus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadUnmanaged(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		content *string
+		want    []string
+		wantErr bool
+	}{
+		{name: "missing file", content: nil, want: nil},
+		{name: "empty file", content: new(""), want: nil},
+		{name: "no endpoints key", content: new("alerting: {}\n"), want: nil},
+		{
+			name: "skips managed entries",
+			content: new(`endpoints:
+  - name: static
+    url: https://static.example.com
+  - name: generated
+    url: https://gen.example.com
+    managed-by: gatus-sidecar
+  - name: other-owner
+    url: https://other.example.com
+    managed-by: someone-else
+`),
+			want: []string{"static", "other-owner"},
+		},
+		{name: "invalid yaml", content: new("endpoints: [\n"), wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "out.yaml")
+			if tt.content != nil {
+				if err := os.WriteFile(path, []byte(*tt.content), 0o600); err != nil {
+					t.Fatalf("WriteFile: %v", err)
+				}
+			}
+			got, err := readUnmanaged(path, DefaultManagedBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readUnmanaged err=%v wantErr=%v", err, tt.wantErr)
+			}
+			var names []string
+			for _, p := range got {
+				names = append(names, p.name)
+			}
+			if len(names) != len(tt.want) {
+				t.Fatalf("names = %v, want %v", names, tt.want)
+			}
+			for i := range names {
+				if names[i] != tt.want[i] {
+					t.Errorf("names = %v, want %v", names, tt.want)
+				}
+			}
+		})
+	}
+}
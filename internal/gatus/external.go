@@ -0,0 +1,52 @@
+package gatus
+
+import (
+	"errors"
+	"maps"
+)
+
+// ExternalEndpoint is a Gatus push-based endpoint: Gatus doesn't probe it but
+// accepts results posted with its token. It has no url, conditions or client
+// settings.
+type ExternalEndpoint struct {
+	Name      string         `yaml:"name"`
+	Group     string         `yaml:"group,omitempty"`
+	Token     string         `yaml:"token"`
+	Heartbeat map[string]any `yaml:"heartbeat,omitempty"`
+	ManagedBy string         `yaml:"managed-by,omitempty"`
+	Extra     map[string]any `yaml:",inline,omitempty"`
+}
+
+// probeOnlyKeys are Endpoint template keys Gatus rejects on external
+// endpoints.
+var probeOnlyKeys = []string{"method", "body", "headers", "graphql", "ssh"}
+
+// NewExternalEndpoint converts a rendered Endpoint into an external one. The
+// token (and optional heartbeat) come from the template, where they land in
+// Extra; probe settings are dropped. It fails when no token is set, since
+// Gatus refuses to load an external endpoint without one.
+func NewExternalEndpoint(e *Endpoint) (*ExternalEndpoint, error) {
+	extra := maps.Clone(e.Extra)
+	token, _ := extra["token"].(string)
+	if token == "" {
+		return nil, errors.New("external endpoint requires a token in its template")
+	}
+	out := &ExternalEndpoint{
+		Name:      e.Name,
+		Group:     e.Group,
+		Token:     token,
+		ManagedBy: e.ManagedBy,
+	}
+	if heartbeat, ok := extra["heartbeat"].(map[string]any); ok {
+		out.Heartbeat = heartbeat
+	}
+	delete(extra, "token")
+	delete(extra, "heartbeat")
+	for _, key := range probeOnlyKeys {
+		delete(extra, key)
+	}
+	if len(extra) > 0 {
+		out.Extra = extra
+	}
+	return out, nil
+}
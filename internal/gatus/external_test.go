@@ -0,0 +1,76 @@
+package gatus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewExternalEndpoint(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		in      *Endpoint
+		want    *ExternalEndpoint
+		wantErr bool
+	}{
+		{
+			name: "token and heartbeat lifted, probe settings dropped",
+			in: &Endpoint{
+				Name: "svc", Group: "core", URL: "tcp://svc.ns.svc:80", Interval: "1m",
+				Conditions: []string{"[CONNECTED] == true"},
+				Client:     map[string]any{"timeout": "5s"},
+				ManagedBy:  "gatus-sidecar",
+				Extra: map[string]any{
+					"token":     "s3cret",
+					"heartbeat": map[string]any{"interval": "30m"},
+					"headers":   map[string]any{"X": "y"},
+					"alerts":    []any{map[string]any{"type": "slack"}},
+				},
+			},
+			want: &ExternalEndpoint{
+				Name: "svc", Group: "core", Token: "s3cret",
+				Heartbeat: map[string]any{"interval": "30m"},
+				ManagedBy: "gatus-sidecar",
+				Extra:     map[string]any{"alerts": []any{map[string]any{"type": "slack"}}},
+			},
+		},
+		{
+			name: "minimal",
+			in:   &Endpoint{Name: "svc", Extra: map[string]any{"token": "t"}},
+			want: &ExternalEndpoint{Name: "svc", Token: "t"},
+		},
+		{
+			name:    "missing token",
+			in:      &Endpoint{Name: "svc", Extra: map[string]any{"heartbeat": map[string]any{"interval": "1m"}}},
+			wantErr: true,
+		},
+		{
+			name:    "non-string token",
+			in:      &Endpoint{Name: "svc", Extra: map[string]any{"token": 42}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NewExternalEndpoint(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExternalEndpoint_DoesNotMutateInput(t *testing.T) {
+	t.Parallel()
+	in := &Endpoint{Name: "svc", Extra: map[string]any{"token": "t", "headers": map[string]any{}}}
+	if _, err := NewExternalEndpoint(in); err != nil {
+		t.Fatal(err)
+	}
+	if len(in.Extra) != 2 {
+		t.Errorf("input Extra mutated: %v", in.Extra)
+	}
+}
@@ -43,6 +43,7 @@ func TestWriter_GoldenOutput(t *testing.T) {
         - '[STATUS] == 200'
         - '[RESPONSE_TIME] < 500'
       interval: 30s
+      managed-by: gatus-sidecar
       alerts:
         - type: slack
           webhook-url: https://example.com/hook
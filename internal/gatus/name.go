@@ -0,0 +1,25 @@
+package gatus
+
+import "strings"
+
+// keyReplacer mirrors the characters Gatus itself rewrites to "-" when it
+// derives an endpoint's key (used in its API paths and metric labels) from
+// the name and group.
+var keyReplacer = strings.NewReplacer("/", "-", "_", "-", ",", "-", ".", "-", "#", "-", "+", "-", "&", "-", " ", "-")
+
+// SanitizeName lowercases name and replaces the characters Gatus would
+// rewrite in its key with "-", collapsing runs and trimming them from the
+// ends, so the name shown on the dashboard matches the key. Other
+// characters, including non-ASCII letters, are kept as Gatus keeps them.
+func SanitizeName(name string) string {
+	s := keyReplacer.Replace(strings.ToLower(name))
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r == '-' && strings.HasSuffix(b.String(), "-") {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return strings.Trim(b.String(), "-")
+}
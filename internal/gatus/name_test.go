@@ -0,0 +1,24 @@
+package gatus
+
+import "testing"
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"web", "web"},
+		{"my.app_v2", "my-app-v2"},
+		{"Ingress-My.App", "ingress-my-app"},
+		{"a__b..c", "a-b-c"},
+		{"_leading.and.trailing_", "leading-and-trailing"},
+		{"svc-api-HTTP/8080", "svc-api-http-8080"},
+		{"grafana & loki", "grafana-loki"},
+		{"Café.Ünïcode", "café-ünïcode"},
+		{"日本.app", "日本-app"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeName(tt.in); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package gatus
 
 import (
+	"errors"
 	"fmt"
 	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -20,9 +25,59 @@ func ParseTemplate(value string) (map[string]any, error) {
 	return out, nil
 }
 
+// ParseConditions decodes a conditions annotation: a YAML or JSON list, or
+// one condition per line. Conditions themselves start with "[", so only a
+// block list ("- ...") or a flow list whose first item is quoted counts as a
+// list.
+func ParseConditions(value string) ([]string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, errors.New("no conditions")
+	}
+	if !conditionsListPattern.MatchString(value) {
+		var out []string
+		for line := range strings.Lines(value) {
+			if line = strings.TrimSpace(line); line != "" {
+				out = append(out, line)
+			}
+		}
+		return out, nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &node); err != nil {
+		return nil, fmt.Errorf("decode conditions list: %w", err)
+	}
+	list := node.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return nil, errors.New("want a list of conditions")
+	}
+	out := make([]string, 0, len(list.Content))
+	for _, item := range list.Content {
+		if item.Kind != yaml.ScalarNode || strings.TrimSpace(item.Value) == "" {
+			return nil, errors.New("list items must be non-empty strings")
+		}
+		out = append(out, strings.TrimSpace(item.Value))
+	}
+	return out, nil
+}
+
+var conditionsListPattern = regexp.MustCompile(`^(-\s|\[\s*["'])`)
+
 // MergeTemplates deep-merges child into parent. Scalars from child win;
-// nested map values are merged recursively.
+// nested map values are merged recursively. Lists from child replace the
+// parent's.
 func MergeTemplates(parent, child map[string]any) map[string]any {
+	return mergeTemplates(parent, child, false)
+}
+
+// MergeTemplatesAppendingLists is MergeTemplates, except that where both
+// sides hold a list (alerts, conditions, ...) the child's items are appended
+// to the parent's, skipping any the parent already has.
+func MergeTemplatesAppendingLists(parent, child map[string]any) map[string]any {
+	return mergeTemplates(parent, child, true)
+}
+
+func mergeTemplates(parent, child map[string]any, appendLists bool) map[string]any {
 	switch {
 	case parent == nil:
 		return child
@@ -37,7 +92,13 @@ func MergeTemplates(parent, child map[string]any) map[string]any {
 		if parentVal, exists := out[key]; exists {
 			if pm, ok := parentVal.(map[string]any); ok {
 				if cm, ok := childVal.(map[string]any); ok {
-					out[key] = MergeTemplates(pm, cm)
+					out[key] = mergeTemplates(pm, cm, appendLists)
+					continue
+				}
+			}
+			if pl, ok := parentVal.([]any); ok && appendLists {
+				if cl, ok := childVal.([]any); ok {
+					out[key] = appendUnique(pl, cl)
 					continue
 				}
 			}
@@ -47,10 +108,24 @@ func MergeTemplates(parent, child map[string]any) map[string]any {
 	return out
 }
 
+// appendUnique returns parent followed by the items of child that aren't
+// deep-equal to one already present. Whole-item equality is the only key
+// that works for every list Gatus accepts (alerts have no name field).
+func appendUnique(parent, child []any) []any {
+	out := slices.Clone(parent)
+	for _, item := range child {
+		if !slices.ContainsFunc(out, func(existing any) bool { return reflect.DeepEqual(existing, item) }) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 // IsGuarded reports whether data opts the endpoint into a DNS-only probe.
+// Only a boolean true counts, so "guarded: false" can turn off a parent's.
 func IsGuarded(data map[string]any) bool {
-	_, ok := data["guarded"]
-	return ok
+	guarded, _ := data["guarded"].(bool)
+	return guarded
 }
 
 // PathOverride returns the explicit path override and true when the template
@@ -31,6 +31,38 @@ func TestParseTemplate(t *testing.T) {
 	}
 }
 
+func TestParseConditions(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"single", "[STATUS] == 200", []string{"[STATUS] == 200"}, false},
+		{"lines", "[STATUS] == 401\n\n  [RESPONSE_TIME] < 500\n", []string{"[STATUS] == 401", "[RESPONSE_TIME] < 500"}, false},
+		{"json list", `["[STATUS] == 200", "[BODY].ok == true"]`, []string{"[STATUS] == 200", "[BODY].ok == true"}, false},
+		{"yaml list", "- \"[CONNECTED] == true\"\n- '[RESPONSE_TIME] < 300'\n", []string{"[CONNECTED] == true", "[RESPONSE_TIME] < 300"}, false},
+		{"unquoted yaml list", "- [STATUS] == 200\n", nil, true},
+		{"nested list", `- ["[STATUS] == 200"]`, nil, true},
+		{"unterminated json list", `["[STATUS] == 200"`, nil, true},
+		{"empty item", `["[STATUS] == 200", ""]`, nil, true},
+		{"blank", "  \n", nil, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseConditions(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConditions err=%v wantErr=%v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got=%q want=%q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMergeTemplates(t *testing.T) {
 	t.Parallel()
 	cases := []struct {
@@ -80,6 +112,62 @@ func TestMergeTemplates(t *testing.T) {
 	}
 }
 
+func TestMergeTemplatesAppendingLists(t *testing.T) {
+	t.Parallel()
+	pager := map[string]any{"type": "pagerduty"}
+	slack := map[string]any{"type": "slack"}
+	cases := []struct {
+		name          string
+		parent, child map[string]any
+		want          map[string]any
+	}{
+		{
+			"parent only",
+			map[string]any{"alerts": []any{pager}},
+			map[string]any{"interval": "1m"},
+			map[string]any{"alerts": []any{pager}, "interval": "1m"},
+		},
+		{
+			"child only",
+			map[string]any{"interval": "1m"},
+			map[string]any{"alerts": []any{slack}},
+			map[string]any{"alerts": []any{slack}, "interval": "1m"},
+		},
+		{
+			"both with overlap",
+			map[string]any{"alerts": []any{pager}},
+			map[string]any{"alerts": []any{map[string]any{"type": "pagerduty"}, slack}},
+			map[string]any{"alerts": []any{pager, slack}},
+		},
+		{
+			"nested lists",
+			map[string]any{"client": map[string]any{"x": []any{"a"}}},
+			map[string]any{"client": map[string]any{"x": []any{"b"}}},
+			map[string]any{"client": map[string]any{"x": []any{"a", "b"}}},
+		},
+		{
+			"list replaced by scalar",
+			map[string]any{"conditions": []any{"a"}},
+			map[string]any{"conditions": "b"},
+			map[string]any{"conditions": "b"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := MergeTemplatesAppendingLists(tt.parent, tt.child); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got=%v want=%v", got, tt.want)
+			}
+		})
+	}
+
+	// The default strategy still replaces.
+	got := MergeTemplates(map[string]any{"alerts": []any{pager}}, map[string]any{"alerts": []any{slack}})
+	if !reflect.DeepEqual(got, map[string]any{"alerts": []any{slack}}) {
+		t.Errorf("MergeTemplates should replace lists, got %v", got)
+	}
+}
+
 func TestIsGuarded(t *testing.T) {
 	t.Parallel()
 	if IsGuarded(nil) {
@@ -88,8 +176,11 @@ func TestIsGuarded(t *testing.T) {
 	if !IsGuarded(map[string]any{"guarded": true}) {
 		t.Error("explicit guarded should be true")
 	}
-	if !IsGuarded(map[string]any{"guarded": "any-value"}) {
-		t.Error("any value at guarded key should be treated as guarded")
+	if IsGuarded(map[string]any{"guarded": false}) {
+		t.Error("guarded: false should not be guarded")
+	}
+	if IsGuarded(map[string]any{"guarded": "any-value"}) {
+		t.Error("a non-bool guarded value should not be treated as guarded")
 	}
 }
 
@@ -1,12 +1,15 @@
 package gatus
 
 import (
+	"bytes"
 	"cmp"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"maps"
 	"os"
 	"path/filepath"
-	"reflect"
 	"slices"
 	"sync"
 
@@ -16,40 +19,168 @@ import (
 // Writer aggregates endpoints and renders them to a YAML file atomically.
 // Safe for concurrent use.
 type Writer struct {
-	path string
+	path          string
+	dryRun        bool
+	mergeExisting bool
+	managedBy     string
+	skipEmpty     bool
+	fileMode      os.FileMode
+	fileGID       int
 
 	mu        sync.Mutex
 	endpoints map[string]*Endpoint
+	// external holds push-based endpoints, rendered under
+	// external-endpoints. A key lives in at most one of the two maps.
+	external map[string]*ExternalEndpoint
+	// preserved holds hand-written endpoints loaded by LoadExisting. They
+	// are re-emitted on every flush unless a generated endpoint claims the
+	// same name.
+	preserved []preservedEndpoint
 	// dirty signals that the in-memory state has diverged from the on-disk
 	// file (either via an unflushed change or a failed flush). Cleared only
 	// when flushLocked succeeds, so a transient write failure is retried on
 	// the next flush even when the endpoint itself didn't change.
 	dirty bool
+	// populated records that a flush has rendered at least one endpoint, so
+	// an empty flush afterwards is a genuine "everything was deleted".
+	populated bool
+	// held defers every flush until Release; see Hold.
+	held bool
 }
 
-func NewWriter(path string) *Writer {
-	return &Writer{
+// WriterOption customizes a Writer at construction.
+type WriterOption func(*Writer)
+
+// WithDryRun makes flushes log what would be written instead of touching
+// the output file.
+func WithDryRun(dryRun bool) WriterOption {
+	return func(w *Writer) { w.dryRun = dryRun }
+}
+
+// WithMergeExisting keeps endpoints loaded via LoadExisting that don't carry
+// the managed-by marker in the output.
+func WithMergeExisting(merge bool) WriterOption {
+	return func(w *Writer) { w.mergeExisting = merge }
+}
+
+// WithManagedBy sets the managed-by value stamped on endpoints that don't
+// set their own. Empty disables the marker.
+func WithManagedBy(value string) WriterOption {
+	return func(w *Writer) { w.managedBy = value }
+}
+
+// WithSkipEmptyWrite refuses to replace a non-empty output file with an
+// empty endpoint list until this Writer has rendered at least one endpoint,
+// so a run that never populated (a broken watch, missing RBAC) can't wipe
+// the live config.
+func WithSkipEmptyWrite(skip bool) WriterOption {
+	return func(w *Writer) { w.skipEmpty = skip }
+}
+
+// DefaultFileMode is the output file's permission bits unless
+// WithFileMode overrides them.
+const DefaultFileMode os.FileMode = 0o644
+
+// WithFileMode sets the output file's permission bits, e.g. 0o640 when Gatus
+// reads it through a shared group.
+func WithFileMode(mode os.FileMode) WriterOption {
+	return func(w *Writer) { w.fileMode = mode }
+}
+
+// WithFileGID chowns the output file to gid on every write. A negative gid
+// leaves the group alone.
+func WithFileGID(gid int) WriterOption {
+	return func(w *Writer) { w.fileGID = gid }
+}
+
+func NewWriter(path string, opts ...WriterOption) *Writer {
+	w := &Writer{
 		path:      path,
+		fileMode:  DefaultFileMode,
+		fileGID:   -1,
+		managedBy: DefaultManagedBy,
 		endpoints: make(map[string]*Endpoint),
+		external:  make(map[string]*ExternalEndpoint),
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// LoadExisting reads the current output file and keeps every endpoint that
+// the sidecar didn't generate, so they survive subsequent flushes. It is a
+// no-op unless the Writer was built WithMergeExisting.
+func (w *Writer) LoadExisting() (int, error) {
+	if !w.mergeExisting {
+		return 0, nil
+	}
+	preserved, err := readUnmanaged(w.path, w.managedBy)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.preserved = preserved
+	return len(preserved), nil
 }
 
-// Upsert stores e under key. The bool reports whether the stored value
-// changed. The file is rewritten when flush is true and either this call
-// changed something or a previous flush failed.
+// Upsert stores e under key, stamping the managed-by marker unless e sets
+// its own. The bool reports whether the stored value changed. The file is
+// rewritten when flush is true and either this call changed something or a
+// previous flush failed.
 func (w *Writer) Upsert(key string, e *Endpoint, flush bool) (bool, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	changed := false
-	if existing, ok := w.endpoints[key]; !ok || !reflect.DeepEqual(existing, e) {
-		w.endpoints[key] = e
-		w.dirty = true
+	if e.ManagedBy == "" {
+		e.ManagedBy = w.managedBy
+	}
+	changed := store(w.endpoints, key, e)
+	if _, ok := w.external[key]; ok {
+		delete(w.external, key)
+		changed = true
+	}
+	w.dirty = w.dirty || changed
+	return changed, w.flushIfDirty(flush)
+}
+
+// UpsertExternal is Upsert for push-based endpoints. Storing a key here
+// replaces a probed endpoint under the same key, and vice versa.
+func (w *Writer) UpsertExternal(key string, e *ExternalEndpoint, flush bool) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if e.ManagedBy == "" {
+		e.ManagedBy = w.managedBy
+	}
+	changed := store(w.external, key, e)
+	if _, ok := w.endpoints[key]; ok {
+		delete(w.endpoints, key)
 		changed = true
 	}
+	w.dirty = w.dirty || changed
 	return changed, w.flushIfDirty(flush)
 }
 
+// store sets m[key] = v and reports whether that changed anything.
+func store[T any](m map[string]*T, key string, v *T) bool {
+	if existing, ok := m[key]; ok && sameYAML(existing, v) {
+		return false
+	}
+	m[key] = v
+	return true
+}
+
+// sameYAML reports whether a and b marshal to the same YAML. Comparing the
+// output rather than the values means nil and empty slices or maps (both
+// omitted) and maps built in a different order don't count as changes.
+func sameYAML(a, b any) bool {
+	ya, errA := yaml.Marshal(a)
+	yb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ya, yb)
+}
+
 // Delete drops the endpoint stored under key. The bool reports whether a
 // deletion occurred. The file is rewritten when flush is true and either
 // this call removed something or a previous flush failed.
@@ -60,58 +191,260 @@ func (w *Writer) Delete(key string, flush bool) (bool, error) {
 	removed := false
 	if _, ok := w.endpoints[key]; ok {
 		delete(w.endpoints, key)
-		w.dirty = true
 		removed = true
 	}
+	if _, ok := w.external[key]; ok {
+		delete(w.external, key)
+		removed = true
+	}
+	w.dirty = w.dirty || removed
 	return removed, w.flushIfDirty(flush)
 }
 
-// Flush forces the current state to disk.
-func (w *Writer) Flush() error {
+// Hold defers all writes, leaving changes pending, until Release. It lets
+// several controllers load their initial listings without Gatus seeing a
+// file that holds only the kinds that happened to finish first.
+func (w *Writer) Hold() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.held = true
+}
+
+// Release lifts a Hold and writes the accumulated state in one flush.
+func (w *Writer) Release() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.held = false
+	_, err := w.flushLocked()
+	return err
+}
+
+// Flush forces the current state to disk and reports whether the file was
+// written: a Hold, --dry-run or a skipped empty write leave it untouched.
+func (w *Writer) Flush() (bool, error) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	return w.flushLocked()
 }
 
+// FlushIfDirty rewrites the file only when unflushed changes (or a failed
+// flush) are pending. Use it to commit a batch of non-flushing Upserts and
+// Deletes in one write.
+func (w *Writer) FlushIfDirty() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flushIfDirty(true)
+}
+
 func (w *Writer) flushIfDirty(flush bool) error {
 	if flush && w.dirty {
-		return w.flushLocked()
+		_, err := w.flushLocked()
+		return err
 	}
 	return nil
 }
 
+// DeleteOutput removes the output file on shutdown (--delete-on-exit), so
+// a Gatus restarted while the sidecar is gone doesn't probe stale
+// endpoints. Hand-written endpoints kept by LoadExisting aren't the
+// sidecar's to delete: when there are any, the file is rewritten with only
+// them. A file that is already gone is not an error.
+func (w *Writer) DeleteOutput() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	clear(w.endpoints)
+	clear(w.external)
+	w.held = false
+	if len(w.preserved) > 0 {
+		_, err := w.flushLocked()
+		return err
+	}
+	if w.dryRun {
+		slog.Info("dry-run: would delete output", "path", w.path)
+		return nil
+	}
+	if err := os.Remove(w.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete output: %w", err)
+	}
+	w.dirty = false
+	return nil
+}
+
+// Len reports how many generated endpoints, probed and external, the Writer
+// holds. Hand-written endpoints kept by LoadExisting aren't counted.
 func (w *Writer) Len() int {
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	return len(w.endpoints)
+	return len(w.endpoints) + len(w.external)
+}
+
+// Snapshot is a point-in-time copy of a Writer's generated endpoints,
+// keyed by writer key. Each endpoint is the map it renders to, so field
+// names match the Gatus config.
+type Snapshot struct {
+	Endpoints         map[string]map[string]any `json:"endpoints"`
+	ExternalEndpoints map[string]map[string]any `json:"external-endpoints"`
+}
+
+// Snapshot copies the current generated endpoints, flushed or not.
+// Hand-written endpoints kept by LoadExisting are not included. The copy
+// shares nothing with the Writer, so callers may read or modify it while
+// reconciles go on.
+func (w *Writer) Snapshot() (Snapshot, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	snap := Snapshot{
+		Endpoints:         make(map[string]map[string]any, len(w.endpoints)),
+		ExternalEndpoints: make(map[string]map[string]any, len(w.external)),
+	}
+	for key, e := range w.endpoints {
+		m, err := toMap(e)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("endpoint %s: %w", key, err)
+		}
+		snap.Endpoints[key] = m
+	}
+	for key, e := range w.external {
+		m, err := toMap(e)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("external endpoint %s: %w", key, err)
+		}
+		snap.ExternalEndpoints[key] = m
+	}
+	return snap, nil
 }
 
-func (w *Writer) flushLocked() error {
+// toMap round-trips v through YAML, yielding the same keys and values the
+// output file gets.
+func toMap(v any) (map[string]any, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (w *Writer) flushLocked() (bool, error) {
+	if w.held {
+		// Stay dirty so Release (or the next flush) writes it.
+		w.dirty = true
+		return false, nil
+	}
+	if w.skipEmptyLocked() {
+		slog.Warn("skipping write of an empty endpoint list over existing output; nothing has been generated yet",
+			"path", w.path)
+		w.dirty = false
+		return false, nil
+	}
 	endpoints := slices.SortedFunc(maps.Values(w.endpoints), func(a, b *Endpoint) int {
 		return cmp.Compare(a.Name, b.Name)
 	})
+	doc := map[string]any{"endpoints": w.withPreserved(endpoints)}
+	if len(w.external) > 0 {
+		doc["external-endpoints"] = slices.SortedFunc(maps.Values(w.external), func(a, b *ExternalEndpoint) int {
+			return cmp.Compare(a.Name, b.Name)
+		})
+	}
 
-	data, err := yaml.Marshal(map[string]any{"endpoints": endpoints})
+	data, err := yaml.Marshal(doc)
 	if err != nil {
-		return fmt.Errorf("marshal endpoints: %w", err)
+		return false, fmt.Errorf("marshal endpoints: %w", err)
 	}
-	if err := writeAtomic(w.path, data, 0o644); err != nil {
-		return err
+	if w.dryRun {
+		slog.Info("dry-run: would write endpoints", "path", w.path, "count", len(endpoints), "external", len(w.external))
+		slog.Debug("dry-run: rendered output", "yaml", string(data))
+		w.dirty = false
+		return false, nil
+	}
+	if err := writeAtomic(w.path, data, w.fileMode, w.fileGID); err != nil {
+		return false, err
 	}
 	w.dirty = false
+	w.populated = w.populated || len(endpoints)+len(w.external) > 0
+	return true, nil
+}
+
+// skipEmptyLocked reports whether WithSkipEmptyWrite should suppress this
+// flush: nothing to render, never rendered anything, and a non-empty file
+// already on disk.
+func (w *Writer) skipEmptyLocked() bool {
+	if !w.skipEmpty || w.populated || len(w.endpoints)+len(w.external)+len(w.preserved) > 0 {
+		return false
+	}
+	info, err := os.Stat(w.path)
+	return err == nil && info.Size() > 0
+}
+
+// withPreserved prepends the hand-written endpoints to the generated ones.
+// On a name clash the generated endpoint wins and the conflict is logged.
+func (w *Writer) withPreserved(endpoints []*Endpoint) []any {
+	out := make([]any, 0, len(w.preserved)+len(endpoints))
+	generated := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		generated[e.Name] = true
+	}
+	for _, p := range w.preserved {
+		if generated[p.name] {
+			slog.Warn("generated endpoint shadows a hand-written one", "name", p.name)
+			continue
+		}
+		out = append(out, p.node)
+	}
+	for _, e := range endpoints {
+		out = append(out, e)
+	}
+	return out
+}
+
+// OutputDirError reports that the directory holding the output file can't
+// be created or written to. Without it no config ever reaches Gatus, so it
+// is worth failing fast on rather than logging on every flush.
+type OutputDirError struct {
+	Dir string
+	Err error
+}
+
+func (e *OutputDirError) Error() string {
+	return fmt.Sprintf("output directory %s is not writable: %v", e.Dir, e.Err)
+}
+
+func (e *OutputDirError) Unwrap() error { return e.Err }
+
+// CheckOutputDir creates the output file's directory if needed and verifies
+// a file can be created in it, returning an *OutputDirError otherwise. It
+// is a no-op in dry-run mode, which never writes.
+func (w *Writer) CheckOutputDir() error {
+	if w.dryRun {
+		return nil
+	}
+	dir := filepath.Dir(w.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return &OutputDirError{Dir: dir, Err: err}
+	}
+	probe, err := os.CreateTemp(dir, ".gatus-sidecar-*.tmp")
+	if err != nil {
+		return &OutputDirError{Dir: dir, Err: err}
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
 	return nil
 }
 
 // writeAtomic writes data via tempfile+rename so a concurrent reader (Gatus)
-// never observes a partial file.
-func writeAtomic(path string, data []byte, mode os.FileMode) (retErr error) {
+// never observes a partial file. Mode and group (unless gid is negative)
+// are set on the tempfile, so the file never appears with the wrong ones.
+func writeAtomic(path string, data []byte, mode os.FileMode, gid int) (retErr error) {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("create output dir: %w", err)
+		return &OutputDirError{Dir: dir, Err: err}
 	}
 	tmp, err := os.CreateTemp(dir, ".gatus-sidecar-*.tmp")
 	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+		return &OutputDirError{Dir: dir, Err: err}
 	}
 	tmpPath := tmp.Name()
 	defer func() {
@@ -128,6 +461,12 @@ func writeAtomic(path string, data []byte, mode os.FileMode) (retErr error) {
 		_ = tmp.Close()
 		return fmt.Errorf("chmod temp file: %w", err)
 	}
+	if gid >= 0 {
+		if err := tmp.Chown(-1, gid); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("chown temp file: %w", err)
+		}
+	}
 	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("close temp file: %w", err)
 	}
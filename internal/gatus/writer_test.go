@@ -1,6 +1,8 @@
 package gatus
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -58,6 +60,41 @@ func TestWriter_UpsertAndDelete(t *testing.T) {
 	}
 }
 
+func TestWriter_UpsertIgnoresEquivalentValues(t *testing.T) {
+	t.Parallel()
+	w := NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	base := &Endpoint{Name: "a", URL: "https://a", Interval: "1m",
+		Extra: map[string]any{"alerts": []any{"x"}, "body": "{}"}}
+	if changed, err := w.Upsert("k", base, false); err != nil || !changed {
+		t.Fatalf("first Upsert = %v, %v", changed, err)
+	}
+
+	equivalent := []struct {
+		name string
+		e    *Endpoint
+	}{
+		{"empty conditions", &Endpoint{Name: "a", URL: "https://a", Interval: "1m", Conditions: []string{},
+			Extra: map[string]any{"alerts": []any{"x"}, "body": "{}"}}},
+		{"empty client map", &Endpoint{Name: "a", URL: "https://a", Interval: "1m", Client: map[string]any{},
+			Extra: map[string]any{"alerts": []any{"x"}, "body": "{}"}}},
+		{"extra built in another order", func() *Endpoint {
+			e := &Endpoint{Name: "a", URL: "https://a", Interval: "1m", Extra: map[string]any{}}
+			e.Extra["body"] = "{}"
+			e.Extra["alerts"] = []any{"x"}
+			return e
+		}()},
+	}
+	for _, tt := range equivalent {
+		if changed, err := w.Upsert("k", tt.e, false); err != nil || changed {
+			t.Errorf("%s: Upsert = %v, %v; want unchanged", tt.name, changed, err)
+		}
+	}
+	if changed, _ := w.Upsert("k", &Endpoint{Name: "a", URL: "https://a", Interval: "1m",
+		Extra: map[string]any{"alerts": []any{"y"}, "body": "{}"}}, false); !changed {
+		t.Error("a different extra value should count as a change")
+	}
+}
+
 func TestWriter_Flush_SortsAndMatchesYAMLShape(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -74,7 +111,7 @@ func TestWriter_Flush_SortsAndMatchesYAMLShape(t *testing.T) {
 			t.Fatalf("Upsert: %v", err)
 		}
 	}
-	if err := w.Flush(); err != nil {
+	if _, err := w.Flush(); err != nil {
 		t.Fatalf("Flush: %v", err)
 	}
 
@@ -141,6 +178,86 @@ func TestWriter_Concurrent(t *testing.T) {
 	}
 }
 
+func TestWriter_Snapshot(t *testing.T) {
+	t.Parallel()
+	w := NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	if _, err := w.Upsert("ing/web", &Endpoint{Name: "web", URL: "https://web", Interval: "1m"}, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if _, err := w.UpsertExternal("svc/db", &ExternalEndpoint{Name: "db", Token: "t"}, false); err != nil {
+		t.Fatalf("UpsertExternal: %v", err)
+	}
+
+	snap, err := w.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if len(snap.Endpoints) != 1 || snap.Endpoints["ing/web"]["url"] != "https://web" {
+		t.Errorf("Endpoints = %v", snap.Endpoints)
+	}
+	if len(snap.ExternalEndpoints) != 1 || snap.ExternalEndpoints["svc/db"]["token"] != "t" {
+		t.Errorf("ExternalEndpoints = %v", snap.ExternalEndpoints)
+	}
+
+	// The snapshot is a copy: changing either side leaves the other alone,
+	// down to nested values.
+	snap.Endpoints["ing/web"]["url"] = "https://changed"
+	delete(snap.ExternalEndpoints, "svc/db")
+	if _, err := w.Upsert("ing/web", &Endpoint{Name: "web", URL: "https://web2", Interval: "1m"}, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	again, err := w.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if again.Endpoints["ing/web"]["url"] != "https://web2" || snap.Endpoints["ing/web"]["url"] != "https://changed" {
+		t.Errorf("snapshots share state: %v / %v", snap.Endpoints, again.Endpoints)
+	}
+	if len(again.ExternalEndpoints) != 1 || w.Len() != 2 {
+		t.Errorf("deleting from a snapshot reached the writer: %v, Len() = %d", again.ExternalEndpoints, w.Len())
+	}
+
+	e := &Endpoint{Name: "api", URL: "https://api", Interval: "1m"}
+	e.ApplyTemplate(map[string]any{"headers": map[string]any{"X-Probe": "gatus"}})
+	if _, err := w.Upsert("ing/api", e, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	snap, err = w.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	snap.Endpoints["ing/api"]["headers"].(map[string]any)["X-Probe"] = "changed"
+	if e.Extra["headers"].(map[string]any)["X-Probe"] != "gatus" {
+		t.Errorf("nested snapshot value aliases the endpoint: %v", e.Extra["headers"])
+	}
+}
+
+func TestWriter_SnapshotConcurrent(t *testing.T) {
+	t.Parallel()
+	w := NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			_, _ = w.Upsert(fmt.Sprintf("k%d", i%5), &Endpoint{Name: "a", URL: fmt.Sprintf("x%d", i), Interval: "1m"}, false)
+		})
+		wg.Go(func() {
+			if _, err := w.Snapshot(); err != nil {
+				t.Errorf("Snapshot: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	snap, err := w.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if len(snap.Endpoints) != 5 {
+		t.Errorf("len(Endpoints) = %d, want 5", len(snap.Endpoints))
+	}
+}
+
 func TestWriter_CreatesDirectories(t *testing.T) {
 	t.Parallel()
 	dir := t.TempDir()
@@ -153,3 +270,439 @@ func TestWriter_CreatesDirectories(t *testing.T) {
 		t.Errorf("expected output file: %v", err)
 	}
 }
+
+func TestWriter_CheckOutputDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	nested := filepath.Join(dir, "a", "b")
+	if err := NewWriter(filepath.Join(nested, "out.yaml")).CheckOutputDir(); err != nil {
+		t.Fatalf("CheckOutputDir: %v", err)
+	}
+	entries, err := os.ReadDir(nested)
+	if err != nil {
+		t.Fatalf("expected directory to be created: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("probe file left behind: %v", entries)
+	}
+
+	// A regular file where the directory should be can't be fixed by MkdirAll
+	// (a permission check would pass when the tests run as root).
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWriter(filepath.Join(blocker, "out.yaml"))
+	var dirErr *OutputDirError
+	if err := w.CheckOutputDir(); !errors.As(err, &dirErr) || dirErr.Dir != blocker {
+		t.Errorf("CheckOutputDir = %v, want *OutputDirError for %s", err, blocker)
+	}
+	if _, err := w.Flush(); !errors.As(err, &dirErr) {
+		t.Errorf("Flush = %v, want *OutputDirError", err)
+	}
+	if err := NewWriter(filepath.Join(blocker, "out.yaml"), WithDryRun(true)).CheckOutputDir(); err != nil {
+		t.Errorf("dry-run CheckOutputDir = %v, want nil", err)
+	}
+}
+
+func TestWriter_FlushIfDirty(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	w := NewWriter(path)
+
+	if err := w.FlushIfDirty(); err != nil {
+		t.Fatalf("FlushIfDirty: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("clean writer should not create the file, stat err=%v", err)
+	}
+
+	if _, err := w.Upsert("a", &Endpoint{Name: "a", URL: "x", Interval: "1m"}, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if _, err := w.Upsert("b", &Endpoint{Name: "b", URL: "y", Interval: "1m"}, false); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if err := w.FlushIfDirty(); err != nil {
+		t.Fatalf("FlushIfDirty: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "name: a") || !strings.Contains(string(data), "name: b") {
+		t.Errorf("batched upserts missing from output:\n%s", data)
+	}
+}
+
+func TestWriter_DryRunSkipsFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "missing", "out.yaml")
+	w := NewWriter(path, WithDryRun(true))
+
+	if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "x", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if wrote, err := w.Flush(); err != nil || wrote {
+		t.Fatalf("Flush = %v, %v; want false, nil in dry-run", wrote, err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("dry-run should not create the output dir, stat err=%v", err)
+	}
+	if w.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", w.Len())
+	}
+}
+
+func TestWriter_MergeExisting(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	existing := `endpoints:
+  - name: static
+    url: https://static.example.com
+    interval: 5m
+  - name: shared
+    url: https://hand-written.example.com
+  - name: stale
+    url: https://stale.example.com
+    managed-by: gatus-sidecar
+`
+	if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	w := NewWriter(path, WithMergeExisting(true))
+	n, err := w.LoadExisting()
+	if err != nil {
+		t.Fatalf("LoadExisting: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("LoadExisting() = %d, want 2", n)
+	}
+	if _, err := w.Upsert("k", &Endpoint{Name: "shared", URL: "https://generated.example.com", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var doc struct {
+		Endpoints []map[string]any `yaml:"endpoints"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	got := map[string]map[string]any{}
+	for _, e := range doc.Endpoints {
+		got[e["name"].(string)] = e
+	}
+	if len(got) != 2 {
+		t.Fatalf("got endpoints %v, want static + shared", got)
+	}
+	if got["static"]["interval"] != "5m" {
+		t.Errorf("hand-written endpoint not preserved verbatim: %v", got["static"])
+	}
+	if got["shared"]["url"] != "https://generated.example.com" || got["shared"][ManagedByKey] != DefaultManagedBy {
+		t.Errorf("generated endpoint should win the name clash and be marked: %v", got["shared"])
+	}
+	if _, ok := got["stale"]; ok {
+		t.Error("previously generated endpoint should not be preserved")
+	}
+}
+
+func TestWriter_DeleteOutput(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	w := NewWriter(path)
+	if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "https://a", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("output not written: %v", err)
+	}
+
+	if err := w.DeleteOutput(); err != nil {
+		t.Fatalf("DeleteOutput: %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("output still present after DeleteOutput: %v", err)
+	}
+	if err := w.DeleteOutput(); err != nil {
+		t.Errorf("DeleteOutput of a missing file: %v", err)
+	}
+
+	t.Run("dry run leaves the file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		if err := os.WriteFile(path, []byte("endpoints: []\n"), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if err := NewWriter(path, WithDryRun(true)).DeleteOutput(); err != nil {
+			t.Fatalf("DeleteOutput: %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("dry run removed the output: %v", err)
+		}
+	})
+
+	t.Run("hand-written endpoints are kept", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		existing := "endpoints:\n  - name: static\n    url: https://static.example.com\n"
+		if err := os.WriteFile(path, []byte(existing), 0o600); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		w := NewWriter(path, WithMergeExisting(true))
+		if _, err := w.LoadExisting(); err != nil {
+			t.Fatalf("LoadExisting: %v", err)
+		}
+		if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "https://a", Interval: "1m"}, true); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+		if err := w.DeleteOutput(); err != nil {
+			t.Fatalf("DeleteOutput: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !strings.Contains(string(data), "static") || strings.Contains(string(data), "https://a") {
+			t.Errorf("want only the hand-written endpoint left, got:\n%s", data)
+		}
+	})
+}
+
+func TestWriter_LoadExistingNoopWithoutMerge(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	if err := os.WriteFile(path, []byte("endpoints:\n  - name: static\n    url: x\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	w := NewWriter(path)
+	if n, err := w.LoadExisting(); err != nil || n != 0 {
+		t.Errorf("LoadExisting() = (%d, %v), want (0, nil)", n, err)
+	}
+	if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "x", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if strings.Contains(string(data), "static") {
+		t.Errorf("without merge the file should be replaced:\n%s", data)
+	}
+}
+
+func TestWriter_ManagedByMarker(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name      string
+		opts      []WriterOption
+		endpoint  *Endpoint
+		wantValue string
+	}{
+		{"default marker", nil, &Endpoint{Name: "a"}, DefaultManagedBy},
+		{"custom marker", []WriterOption{WithManagedBy("team-a")}, &Endpoint{Name: "a"}, "team-a"},
+		{"template override kept", []WriterOption{WithManagedBy("team-a")}, &Endpoint{Name: "a", ManagedBy: "explicit"}, "explicit"},
+		{"disabled marker", []WriterOption{WithManagedBy("")}, &Endpoint{Name: "a"}, ""},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "out.yaml")
+			w := NewWriter(path, tt.opts...)
+			if _, err := w.Upsert("k", tt.endpoint, true); err != nil {
+				t.Fatalf("Upsert: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			var doc struct {
+				Endpoints []map[string]any `yaml:"endpoints"`
+			}
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			got, _ := doc.Endpoints[0][ManagedByKey].(string)
+			if got != tt.wantValue {
+				t.Errorf("managed-by = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestWriter_ExternalEndpoints(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	w := NewWriter(path)
+
+	read := func() (endpoints, external []map[string]any) {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		var doc struct {
+			Endpoints []map[string]any `yaml:"endpoints"`
+			External  []map[string]any `yaml:"external-endpoints"`
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		return doc.Endpoints, doc.External
+	}
+
+	if _, err := w.Upsert("k", &Endpoint{Name: "svc", URL: "tcp://svc:80", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "external-endpoints") {
+		t.Errorf("external-endpoints key should be omitted when empty:\n%s", data)
+	}
+
+	// Moving the same key to the external bucket replaces the probed entry.
+	changed, err := w.UpsertExternal("k", &ExternalEndpoint{Name: "svc", Token: "t"}, true)
+	if err != nil || !changed {
+		t.Fatalf("UpsertExternal = %v, %v; want changed", changed, err)
+	}
+	endpoints, external := read()
+	if len(endpoints) != 0 || len(external) != 1 {
+		t.Fatalf("got %d endpoints and %d external, want 0 and 1", len(endpoints), len(external))
+	}
+	if external[0]["token"] != "t" || external[0][ManagedByKey] != DefaultManagedBy {
+		t.Errorf("external endpoint = %v", external[0])
+	}
+	if w.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", w.Len())
+	}
+
+	removed, err := w.Delete("k", true)
+	if err != nil || !removed {
+		t.Fatalf("Delete = %v, %v; want removed", removed, err)
+	}
+	if _, external = read(); len(external) != 0 {
+		t.Errorf("external endpoint survived Delete: %v", external)
+	}
+}
+
+func TestWriter_SkipEmptyWrite(t *testing.T) {
+	t.Parallel()
+	const live = "endpoints:\n  - name: live\n"
+
+	t.Run("never populated keeps existing file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		if err := os.WriteFile(path, []byte(live), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewWriter(path, WithSkipEmptyWrite(true)).Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != live {
+			t.Errorf("file clobbered:\n%s", data)
+		}
+	})
+
+	t.Run("all deleted is written", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		w := NewWriter(path, WithSkipEmptyWrite(true))
+		if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "x", Interval: "1m"}, true); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+		if _, err := w.Delete("k", true); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), "name: a") {
+			t.Errorf("deletion not written:\n%s", data)
+		}
+	})
+
+	t.Run("missing file is created", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		if _, err := NewWriter(path, WithSkipEmptyWrite(true)).Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected output file: %v", err)
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "out.yaml")
+		if err := os.WriteFile(path, []byte(live), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewWriter(path).Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) == live {
+			t.Error("file should be overwritten without WithSkipEmptyWrite")
+		}
+	})
+}
+
+func TestWriter_HoldDefersWritesUntilRelease(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	w := NewWriter(path)
+	w.Hold()
+
+	if _, err := w.Upsert("k1", &Endpoint{Name: "a", URL: "https://a", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	if wrote, err := w.Flush(); err != nil || wrote {
+		t.Fatalf("Flush = %v, %v; want false, nil while held", wrote, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("held writer should not write, stat err=%v", err)
+	}
+
+	if err := w.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if wrote, err := w.Flush(); err != nil || !wrote {
+		t.Errorf("Flush = %v, %v after Release; want true, nil", wrote, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "name: a") {
+		t.Errorf("Release should write held changes:\n%s", data)
+	}
+}
+
+func TestWriter_FileModeAndGroup(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	// Our own primary group is always a valid chown target.
+	w := NewWriter(path, WithFileMode(0o640), WithFileGID(os.Getgid()))
+	if _, err := w.Upsert("k", &Endpoint{Name: "a", URL: "x", Interval: "1m"}, true); err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("mode = %o, want 640", info.Mode().Perm())
+	}
+
+	defaultPath := filepath.Join(t.TempDir(), "out.yaml")
+	if _, err := NewWriter(defaultPath).Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	info, err = os.Stat(defaultPath)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Mode().Perm() != DefaultFileMode {
+		t.Errorf("default mode = %o, want %o", info.Mode().Perm(), DefaultFileMode)
+	}
+}
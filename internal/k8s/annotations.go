@@ -0,0 +1,312 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Per-resource annotations that override a cluster-wide flag. Each falls
+// back to the flag when the annotation is absent or doesn't parse; the
+// template annotation still has the final word since ApplyTemplate runs
+// after them.
+
+// annotationWarnings is what warnAnnotation has logged for one object at
+// one resourceVersion.
+type annotationWarnings struct {
+	resourceVersion string
+	logged          map[string]bool
+}
+
+// warnAnnotation logs an unusable annotation on obj once per
+// resourceVersion, so a typo isn't repeated on every reconcile and resync;
+// editing the object logs it again if it is still wrong.
+func (c *Controller) warnAnnotation(obj metav1.Object, msg string, args ...any) {
+	key := obj.GetName()
+	if ns := obj.GetNamespace(); ns != "" {
+		key = ns + "/" + key
+	}
+	id := fmt.Sprint(append([]any{msg}, args...)...)
+	c.mu.Lock()
+	w, ok := c.badAnnotations[key]
+	if !ok || w.resourceVersion != obj.GetResourceVersion() {
+		w = annotationWarnings{resourceVersion: obj.GetResourceVersion(), logged: make(map[string]bool)}
+		c.badAnnotations[key] = w
+	}
+	seen := w.logged[id]
+	w.logged[id] = true
+	c.mu.Unlock()
+	if !seen {
+		c.log.Warn(msg, append([]any{"namespace", obj.GetNamespace(), "name", obj.GetName()}, args...)...)
+	}
+}
+
+// applyOverrides sets the flag- and annotation-driven fields on e. It runs
+// before the template is applied.
+func (c *Controller) applyOverrides(obj metav1.Object, e *gatus.Endpoint) {
+	if timeout := c.connectTimeout(obj); timeout > 0 {
+		e.SetClientOption("timeout", timeout.String())
+	}
+	if strings.HasPrefix(e.URL, "https://") && c.insecureTLS(obj) {
+		e.SetClientOption("insecure", true)
+	}
+	if strings.HasPrefix(e.URL, "http://") || strings.HasPrefix(e.URL, "https://") {
+		if len(c.cfg.DefaultHeaders) > 0 {
+			e.SetHeaders(c.cfg.DefaultHeaders)
+		}
+		if c.cfg.ClientDNSResolver != "" {
+			e.SetClientOption("dns-resolver", c.cfg.ClientDNSResolver)
+		}
+		e.SetRequest(c.method(obj), c.body(obj))
+	}
+	if conditions := c.conditions(obj); len(conditions) > 0 {
+		e.Conditions = conditions
+	}
+	if alerts := c.alerts(obj); len(alerts) > 0 {
+		e.SetAlerts(alerts)
+	}
+	if windows := c.maintenanceWindows(obj); len(windows) > 0 {
+		e.SetMaintenanceWindows(windows)
+	}
+	if c.hideURL(obj) {
+		e.SetUIOption("hide-url", true)
+	}
+}
+
+// connectTimeout overrides --default-connect-timeout.
+func (c *Controller) connectTimeout(obj metav1.Object) time.Duration {
+	raw, ok := obj.GetAnnotations()[c.cfg.ConnectTimeoutAnnotation]
+	if !ok || c.cfg.ConnectTimeoutAnnotation == "" {
+		return c.cfg.DefaultConnectTimeout
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		c.warnAnnotation(obj, "ignoring invalid connect-timeout annotation",
+			"value", raw)
+		return c.cfg.DefaultConnectTimeout
+	}
+	return d
+}
+
+// insecureTLS overrides --default-insecure-tls.
+func (c *Controller) insecureTLS(obj metav1.Object) bool {
+	raw, ok := obj.GetAnnotations()[c.cfg.InsecureTLSAnnotation]
+	if !ok || c.cfg.InsecureTLSAnnotation == "" {
+		return c.cfg.DefaultInsecureTLS
+	}
+	insecure, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid insecure-tls annotation",
+			"value", raw)
+		return c.cfg.DefaultInsecureTLS
+	}
+	return insecure
+}
+
+// hideURL reports whether the hide-url annotation asks Gatus to keep the
+// endpoint's URL, often an internal address, off the dashboard.
+func (c *Controller) hideURL(obj metav1.Object) bool {
+	raw, ok := obj.GetAnnotations()[c.cfg.HideURLAnnotation]
+	if !ok || c.cfg.HideURLAnnotation == "" {
+		return false
+	}
+	hide, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid hide-url annotation",
+			"value", raw)
+		return false
+	}
+	return hide
+}
+
+// httpMethods are the verbs the method annotation accepts.
+var httpMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// method returns the HTTP verb in the method annotation, or "" to keep
+// Gatus' default GET.
+func (c *Controller) method(obj metav1.Object) string {
+	raw, ok := obj.GetAnnotations()[c.cfg.MethodAnnotation]
+	if !ok || c.cfg.MethodAnnotation == "" {
+		return ""
+	}
+	method := strings.ToUpper(strings.TrimSpace(raw))
+	if !slices.Contains(httpMethods, method) {
+		c.warnAnnotation(obj, "ignoring invalid method annotation",
+			"value", raw)
+		return ""
+	}
+	return method
+}
+
+// body returns the request body in the body annotation, sent as written.
+func (c *Controller) body(obj metav1.Object) string {
+	if c.cfg.BodyAnnotation == "" {
+		return ""
+	}
+	return obj.GetAnnotations()[c.cfg.BodyAnnotation]
+}
+
+// scheme overrides the http/https scheme picked by the extractor, for TLS
+// terminated somewhere the sidecar can't see. Returns "" when the URL should
+// be left alone.
+func (c *Controller) scheme(obj metav1.Object) string {
+	raw, ok := obj.GetAnnotations()[c.cfg.SchemeAnnotation]
+	if !ok || c.cfg.SchemeAnnotation == "" {
+		return ""
+	}
+	switch scheme := strings.ToLower(strings.TrimSpace(raw)); scheme {
+	case "http", "https":
+		return scheme
+	default:
+		c.warnAnnotation(obj, "ignoring invalid scheme annotation, want http or https",
+			"value", raw)
+		return ""
+	}
+}
+
+// targetFilter compiles the regex in the annotation named key, or returns
+// nil (no filtering) when it is absent or doesn't compile.
+func (c *Controller) targetFilter(obj metav1.Object, key string) *regexp.Regexp {
+	raw, ok := obj.GetAnnotations()[key]
+	if !ok || key == "" {
+		return nil
+	}
+	re, err := regexp.Compile(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid filter annotation",
+			"annotation", key, "error", err)
+		return nil
+	}
+	return re
+}
+
+// filteredOut reports whether a target's value fails filter. Targets that
+// don't carry the value (single-target mode) are never filtered.
+func filteredOut(filter *regexp.Regexp, value string) bool {
+	return filter != nil && value != "" && !filter.MatchString(value)
+}
+
+// external overrides the resource's external default (see
+// [ExternalDefaulter]), which is false for most kinds.
+func (c *Controller) external(obj metav1.Object) bool {
+	def := false
+	if d, ok := c.resource.(ExternalDefaulter); ok {
+		def = d.ExternalDefault(c.cfg)
+	}
+	raw, ok := obj.GetAnnotations()[c.cfg.ExternalAnnotation]
+	if !ok || c.cfg.ExternalAnnotation == "" {
+		return def
+	}
+	external, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid external annotation",
+			"value", raw)
+		return def
+	}
+	return external
+}
+
+// alerts expands the alert profiles named in the alerts annotation. Unknown
+// names are logged and skipped.
+func (c *Controller) alerts(obj metav1.Object) []any {
+	raw, ok := obj.GetAnnotations()[c.cfg.AlertsAnnotation]
+	if !ok || c.cfg.AlertsAnnotation == "" {
+		return nil
+	}
+	alerts, unknown := c.cfg.AlertProfiles.Lookup(raw)
+	if len(unknown) > 0 {
+		c.warnAnnotation(obj, "ignoring unknown alert profiles",
+			"profiles", unknown)
+	}
+	return alerts
+}
+
+// maintenanceWindows returns --default-maintenance-file's windows followed by
+// those in the maintenance annotation. An invalid annotation is logged and
+// only the defaults apply.
+func (c *Controller) maintenanceWindows(obj metav1.Object) []any {
+	windows := slices.Clone(c.cfg.DefaultMaintenance)
+	raw, ok := obj.GetAnnotations()[c.cfg.MaintenanceAnnotation]
+	if !ok || c.cfg.MaintenanceAnnotation == "" {
+		return windows
+	}
+	own, err := config.ParseMaintenanceWindows([]byte(raw))
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid maintenance annotation",
+			"error", err)
+		return windows
+	}
+	return append(windows, own...)
+}
+
+// bodyConditions expands the body-condition annotation into "[BODY]"
+// conditions (see [gatus.BodyConditions]).
+func (c *Controller) bodyConditions(obj metav1.Object) []string {
+	raw, ok := obj.GetAnnotations()[c.cfg.BodyConditionAnnotation]
+	if !ok || c.cfg.BodyConditionAnnotation == "" {
+		return nil
+	}
+	return gatus.BodyConditions(raw)
+}
+
+// conditions returns the list in the conditions annotation (see
+// [gatus.ParseConditions]), which replaces the default conditions without a
+// full template. An invalid list is logged and the defaults apply.
+func (c *Controller) conditions(obj metav1.Object) []string {
+	raw, ok := obj.GetAnnotations()[c.cfg.ConditionsAnnotation]
+	if !ok || c.cfg.ConditionsAnnotation == "" {
+		return nil
+	}
+	conditions, err := gatus.ParseConditions(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid conditions annotation",
+			"error", err)
+		return nil
+	}
+	return conditions
+}
+
+// guarded overrides the template's "guarded" key, so a genuinely public
+// resource can opt out of a parent-wide DNS probe (or a single one opt in).
+func (c *Controller) guarded(obj metav1.Object, tpl map[string]any) bool {
+	def := gatus.IsGuarded(tpl)
+	raw, ok := obj.GetAnnotations()[c.cfg.GuardedAnnotation]
+	if !ok || c.cfg.GuardedAnnotation == "" {
+		return def
+	}
+	guarded, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.warnAnnotation(obj, "ignoring invalid guarded annotation",
+			"value", raw)
+		return def
+	}
+	return guarded
+}
+
+// check returns the probe type named by the check annotation (see
+// [gatus.ParseCheck]), or "" to keep the resource's own probe.
+func (c *Controller) check(obj metav1.Object) string {
+	raw, ok := obj.GetAnnotations()[c.cfg.CheckAnnotation]
+	if !ok || c.cfg.CheckAnnotation == "" {
+		return ""
+	}
+	check, known := gatus.ParseCheck(raw)
+	if !known {
+		c.warnAnnotation(obj, "ignoring invalid check annotation, want http, tcp, icmp, dns, tls or ws (websocket)",
+			"value", raw)
+		return ""
+	}
+	return check
+}
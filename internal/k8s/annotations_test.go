@@ -0,0 +1,838 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// reconcileOne runs a single reconcile of thing-a (with annotations) through
+// a controller for r and returns the rendered endpoints.
+func reconcileOne(t *testing.T, cfg *config.Config, r Resource, annotations map[string]string) []map[string]any {
+	t.Helper()
+	gvr := r.GVR()
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+	c := NewController(cfg, r, writer, newFakeClient(gvr))
+
+	if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, annotations)); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if writer.Len() == 0 {
+		return nil
+	}
+	return readEndpoints(t, outPath)
+}
+
+func TestController_ConnectTimeout(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		flag        time.Duration
+		want        any
+	}{
+		{"unset omits client", nil, 0, nil},
+		{"flag default", nil, 5 * time.Second, "5s"},
+		{"annotation overrides flag", map[string]string{"timeout": "2s"}, 5 * time.Second, "2s"},
+		{"annotation without flag", map[string]string{"timeout": "1m"}, 0, "1m0s"},
+		{"invalid annotation falls back to flag", map[string]string{"timeout": "soon"}, 5 * time.Second, "5s"},
+		{"template client.timeout wins", map[string]string{"timeout": "2s", "tpl": "client:\n  timeout: 9s\n"}, 5 * time.Second, "9s"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:          30 * time.Second,
+				DefaultConnectTimeout:    tt.flag,
+				TemplateAnnotation:       "tpl",
+				EnabledAnnotation:        "enabled",
+				ConnectTimeoutAnnotation: "timeout",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			client, _ := endpoints[0]["client"].(map[string]any)
+			if got := client["timeout"]; got != tt.want {
+				t.Errorf("client.timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_InsecureTLS(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		flag        bool
+		want        any
+	}{
+		{"off by default", "https://x.example.com", nil, false, nil},
+		{"flag on https", "https://x.example.com", nil, true, true},
+		{"flag skips http", "http://x.example.com", nil, true, nil},
+		{"flag skips tcp", "tcp://x.default.svc:80", nil, true, nil},
+		{"annotation enables", "https://x.example.com", map[string]string{"insecure": "true"}, false, true},
+		{"annotation disables", "https://x.example.com", map[string]string{"insecure": "false"}, true, nil},
+		{"invalid annotation falls back", "https://x.example.com", map[string]string{"insecure": "maybe"}, true, true},
+		{"template wins", "https://x.example.com", map[string]string{"tpl": "client:\n  insecure: false\n"}, true, false},
+		{"guarded dns skipped", "https://x.example.com", map[string]string{"tpl": "guarded: true\n"}, true, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:       30 * time.Second,
+				DefaultInsecureTLS:    tt.flag,
+				TemplateAnnotation:    "tpl",
+				EnabledAnnotation:     "enabled",
+				InsecureTLSAnnotation: "insecure",
+			}
+			r := fakeResource{
+				gvr:       schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				guardHost: "x.example.com",
+				urlFn:     func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			client, hasClient := endpoints[0]["client"].(map[string]any)
+			if tt.want == nil {
+				if hasClient {
+					t.Errorf("client = %v, want no client block", client)
+				}
+				return
+			}
+			if got := client["insecure"]; got != tt.want {
+				t.Errorf("client.insecure = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ClientDNSResolver(t *testing.T) {
+	const resolver = "udp://10.96.0.10:53"
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		flag        string
+		want        any
+	}{
+		{"unset omits it", "https://x.example.com", nil, "", nil},
+		{"flag on https", "https://x.example.com", nil, resolver, resolver},
+		{"flag on http", "http://x.example.com", nil, resolver, resolver},
+		{"flag skips tcp", "tcp://x.default.svc:80", nil, resolver, nil},
+		{"template wins", "https://x.example.com", map[string]string{"tpl": "client:\n  dns-resolver: tcp://1.1.1.1:53\n"}, resolver, "tcp://1.1.1.1:53"},
+		{"guarded dns skipped", "https://x.example.com", map[string]string{"tpl": "guarded: true\n"}, resolver, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				ClientDNSResolver:  tt.flag,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+			}
+			r := fakeResource{
+				gvr:       schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				guardHost: "x.example.com",
+				urlFn:     func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			client, _ := endpoints[0]["client"].(map[string]any)
+			if got := client["dns-resolver"]; got != tt.want {
+				t.Errorf("client.dns-resolver = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_DefaultHeaders(t *testing.T) {
+	headers := map[string]any{"Authorization": "Bearer x", "X-Probe": "gatus"}
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		want        any
+	}{
+		{"https", "https://x.example.com", nil, headers},
+		{"http", "http://x.example.com", nil, headers},
+		{"tcp skipped", "tcp://x.default.svc:80", nil, nil},
+		{"dns check skipped", "https://x.example.com", map[string]string{"check": "dns"}, nil},
+		{"guarded dns skipped", "https://x.example.com", map[string]string{"tpl": "guarded: true\n"}, nil},
+		{"template wins", "https://x.example.com", map[string]string{"tpl": "headers:\n  X-Probe: custom\n"},
+			map[string]any{"X-Probe": "custom"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				DefaultHeaders:     config.Headers{"Authorization": "Bearer x", "X-Probe": "gatus"},
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				CheckAnnotation:    "check",
+			}
+			r := fakeResource{
+				gvr:       schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				guardHost: "x.example.com",
+				urlFn:     func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["headers"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headers = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_MethodAndBody(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		wantMethod  any
+		wantBody    any
+	}{
+		{"defaults to GET", "https://x.example.com", nil, nil, nil},
+		{"post with body", "https://x.example.com", map[string]string{"method": "post", "body": `{"ping":true}`}, "POST", `{"ping":true}`},
+		{"invalid method ignored", "https://x.example.com", map[string]string{"method": "FETCH", "body": "x"}, nil, "x"},
+		{"tcp skipped", "tcp://x.default.svc:80", map[string]string{"method": "POST", "body": "x"}, nil, nil},
+		{"template wins", "http://x.example.com", map[string]string{"method": "POST", "tpl": "method: PUT\n"}, "PUT", nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				MethodAnnotation:   "method",
+				BodyAnnotation:     "body",
+			}
+			r := fakeResource{
+				gvr:   schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				urlFn: func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["method"]; got != tt.wantMethod {
+				t.Errorf("method = %v, want %v", got, tt.wantMethod)
+			}
+			if got := endpoints[0]["body"]; got != tt.wantBody {
+				t.Errorf("body = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestController_SchemeOverride(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		want        string
+	}{
+		{"no annotation", "http://x.example.com/", nil, "http://x.example.com/"},
+		{"http to https", "http://x.example.com/", map[string]string{"scheme": "https"}, "https://x.example.com/"},
+		{"https to http", "https://x.example.com:8443/", map[string]string{"scheme": "HTTP"}, "http://x.example.com:8443/"},
+		{"invalid value ignored", "http://x.example.com/", map[string]string{"scheme": "ftp"}, "http://x.example.com/"},
+		{"tcp untouched", "tcp://x.default.svc:80", map[string]string{"scheme": "https"}, "tcp://x.default.svc:80"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				ProbePaths:         true,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				SchemeAnnotation:   "scheme",
+			}
+			r := fakeResource{
+				gvr:   schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				urlFn: func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["url"]; got != tt.want {
+				t.Errorf("url = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_TargetFilters(t *testing.T) {
+	targets := []Target{
+		{Suffix: "api.example.com", URL: "https://api.example.com", Host: "api.example.com"},
+		{Suffix: "www.example.com", URL: "https://www.example.com", Host: "www.example.com"},
+		{Suffix: "http", URL: "tcp://x.default.svc:80", Port: "http"},
+		{Suffix: "metrics", URL: "tcp://x.default.svc:9090", Port: "metrics"},
+	}
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{"no filters", nil, []string{"thing-a-api.example.com", "thing-a-http", "thing-a-metrics", "thing-a-www.example.com"}},
+		{"host filter", map[string]string{"hosts": `^api\.`}, []string{"thing-a-api.example.com", "thing-a-http", "thing-a-metrics"}},
+		{"port filter", map[string]string{"ports": "^http$"}, []string{"thing-a-api.example.com", "thing-a-http", "thing-a-www.example.com"}},
+		{"both filters", map[string]string{"hosts": "^www", "ports": "metrics"}, []string{"thing-a-metrics", "thing-a-www.example.com"}},
+		{"no host matches", map[string]string{"hosts": "^nope", "ports": "^nope"}, nil},
+		{"invalid regex ignored", map[string]string{"hosts": "(", "ports": "^http$"}, []string{"thing-a-api.example.com", "thing-a-http", "thing-a-www.example.com"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:      30 * time.Second,
+				TemplateAnnotation:   "tpl",
+				EnabledAnnotation:    "enabled",
+				HostFilterAnnotation: "hosts",
+				PortFilterAnnotation: "ports",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeMultiResource{
+				fakeResource: fakeResource{gvr: gvr},
+				targetsFn:    func(metav1.Object) []Target { return slices.Clone(targets) },
+			}
+			var got []string
+			for _, e := range reconcileOne(t, cfg, r, tt.annotations) {
+				got = append(got, e["name"].(string))
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("endpoints = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeExternalResource defaults its endpoints to external-endpoints.
+type fakeExternalResource struct {
+	fakeResource
+}
+
+func (fakeExternalResource) ExternalDefault(*config.Config) bool { return true }
+
+func TestController_External(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	withToken := "token: s3cret\n"
+	cases := []struct {
+		name         string
+		resource     Resource
+		annotations  map[string]string
+		wantProbed   int
+		wantExternal int
+	}{
+		{"probed by default", fakeResource{gvr: gvr}, map[string]string{"tpl": withToken}, 1, 0},
+		{"annotation routes to external", fakeResource{gvr: gvr}, map[string]string{"tpl": withToken, "external": "true"}, 0, 1},
+		{"resource default", fakeExternalResource{fakeResource{gvr: gvr}}, map[string]string{"tpl": withToken}, 0, 1},
+		{"annotation overrides default", fakeExternalResource{fakeResource{gvr: gvr}}, map[string]string{"external": "false"}, 1, 0},
+		{"invalid annotation falls back", fakeExternalResource{fakeResource{gvr: gvr}}, map[string]string{"tpl": withToken, "external": "maybe"}, 0, 1},
+		{"missing token skipped", fakeResource{gvr: gvr}, map[string]string{"external": "true"}, 0, 0},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				ExternalAnnotation: "external",
+			}
+			outPath := filepath.Join(t.TempDir(), "out.yaml")
+			writer := gatus.NewWriter(outPath)
+			c := NewController(cfg, tt.resource, writer, newFakeClient(gvr))
+			if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, tt.annotations)); err != nil {
+				t.Fatalf("seed indexer: %v", err)
+			}
+			if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+				t.Fatalf("reconcile: %v", err)
+			}
+			if _, err := writer.Flush(); err != nil {
+				t.Fatalf("Flush: %v", err)
+			}
+
+			data, err := os.ReadFile(outPath)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			var doc struct {
+				Endpoints []map[string]any `yaml:"endpoints"`
+				External  []map[string]any `yaml:"external-endpoints"`
+			}
+			if err := yaml.Unmarshal(data, &doc); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if len(doc.Endpoints) != tt.wantProbed || len(doc.External) != tt.wantExternal {
+				t.Fatalf("got %d probed / %d external, want %d / %d:\n%s",
+					len(doc.Endpoints), len(doc.External), tt.wantProbed, tt.wantExternal, data)
+			}
+			if tt.wantExternal == 1 {
+				if _, hasURL := doc.External[0]["url"]; hasURL || doc.External[0]["token"] != "s3cret" {
+					t.Errorf("external endpoint = %v", doc.External[0])
+				}
+			}
+		})
+	}
+}
+
+func TestController_AlertProfiles(t *testing.T) {
+	slack := map[string]any{"type": "slack"}
+	pager := map[string]any{"type": "pagerduty"}
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        any
+	}{
+		{"no annotation", nil, nil},
+		{"single profile", map[string]string{"alerts": "chat"}, []any{slack}},
+		{"profiles concatenated", map[string]string{"alerts": "critical,chat"}, []any{pager, slack}},
+		{"unknown profile skipped", map[string]string{"alerts": "nope"}, nil},
+		{"inline alerts win", map[string]string{"alerts": "critical", "tpl": "alerts:\n  - type: email\n"}, []any{map[string]any{"type": "email"}}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				AlertsAnnotation:   "alerts",
+				AlertProfiles:      config.AlertProfiles{"chat": {slack}, "critical": {pager}},
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["alerts"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alerts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_MaintenanceWindows(t *testing.T) {
+	nightly := map[string]any{"start": "02:00", "duration": "1h"}
+	backup := map[string]any{"start": "04:00", "duration": "30m"}
+	cases := []struct {
+		name        string
+		defaults    []any
+		annotations map[string]string
+		want        any
+	}{
+		{"none", nil, nil, nil},
+		{"default only", []any{nightly}, nil, []any{nightly}},
+		{"annotation only", nil, map[string]string{"maint": "start: \"04:00\"\nduration: 30m\n"}, []any{backup}},
+		{"default then annotation", []any{nightly}, map[string]string{"maint": "- start: \"04:00\"\n  duration: 30m\n"}, []any{nightly, backup}},
+		{"invalid annotation keeps default", []any{nightly}, map[string]string{"maint": "start: soon\n"}, []any{nightly}},
+		{"template wins", []any{nightly}, map[string]string{"tpl": "maintenance-windows: []\n"}, []any{}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:       30 * time.Second,
+				TemplateAnnotation:    "tpl",
+				EnabledAnnotation:     "enabled",
+				MaintenanceAnnotation: "maint",
+				DefaultMaintenance:    tt.defaults,
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["maintenance-windows"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("maintenance-windows = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_BodyConditions(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        []any
+	}{
+		{"no annotation", nil, []any{"[STATUS] == 200"}},
+		{"single", map[string]string{"body": `.status == "ok"`}, []any{"[STATUS] == 200", `[BODY].status == "ok"`}},
+		{"multiple", map[string]string{"body": `.status == "ok", .checks.db == "up"`},
+			[]any{"[STATUS] == 200", `[BODY].status == "ok"`, `[BODY].checks.db == "up"`}},
+		{"template conditions replace the default", map[string]string{"body": ".ok == true", "tpl": "conditions: ['[STATUS] < 500']\n"},
+			[]any{"[STATUS] < 500", "[BODY].ok == true"}},
+		{"ignored when guarded", map[string]string{"body": ".ok == true", "tpl": "guarded: true\n"}, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:         30 * time.Second,
+				TemplateAnnotation:      "tpl",
+				EnabledAnnotation:       "enabled",
+				BodyConditionAnnotation: "body",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{gvr: gvr, conditions: []string{"[STATUS] == 200"}}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			got, _ := endpoints[0]["conditions"].([]any)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ConditionsAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        []any
+	}{
+		{"no annotation", nil, []any{"[STATUS] == 200"}},
+		{"single string", map[string]string{"conditions": "[STATUS] == 401"}, []any{"[STATUS] == 401"}},
+		{"list", map[string]string{"conditions": `["[STATUS] < 500", "[RESPONSE_TIME] < 300"]`},
+			[]any{"[STATUS] < 500", "[RESPONSE_TIME] < 300"}},
+		{"invalid keeps the defaults", map[string]string{"conditions": "- [STATUS] == 200"}, []any{"[STATUS] == 200"}},
+		{"body checks still append", map[string]string{"conditions": "[STATUS] == 204", "body": ".ok == true"},
+			[]any{"[STATUS] == 204", "[BODY].ok == true"}},
+		{"template still wins", map[string]string{"conditions": "[STATUS] == 401", "tpl": "conditions: ['[STATUS] < 500']\n"},
+			[]any{"[STATUS] < 500"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:         30 * time.Second,
+				TemplateAnnotation:      "tpl",
+				EnabledAnnotation:       "enabled",
+				BodyConditionAnnotation: "body",
+				ConditionsAnnotation:    "conditions",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{gvr: gvr, conditions: []string{"[STATUS] == 200"}}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			got, _ := endpoints[0]["conditions"].([]any)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_GuardedAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		wantURL     string
+	}{
+		{"template only", map[string]string{"tpl": "guarded: true\n"}, gatus.GuardedProbeURL},
+		{"annotation turns template off", map[string]string{"tpl": "guarded: true\n", "guarded": "false"}, "https://example.com"},
+		{"annotation turns guarded on", map[string]string{"guarded": "true"}, gatus.GuardedProbeURL},
+		{"invalid annotation keeps template", map[string]string{"tpl": "guarded: true\n", "guarded": "nope"}, gatus.GuardedProbeURL},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				GuardedAnnotation:  "guarded",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{gvr: gvr, guardHost: "example.com"}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["url"]; got != tt.wantURL {
+				t.Errorf("url = %v, want %v", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestController_GuardedCondition(t *testing.T) {
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+		GuardedCondition:   "[DNS_RCODE] == NXDOMAIN",
+	}
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	r := fakeResource{gvr: gvr, guardHost: "example.com"}
+	endpoints := reconcileOne(t, cfg, r, map[string]string{"tpl": "guarded: true\n"})
+	if len(endpoints) != 1 {
+		t.Fatalf("got %d endpoints, want 1", len(endpoints))
+	}
+	if got := endpoints[0]["conditions"]; !reflect.DeepEqual(got, []any{"[DNS_RCODE] == NXDOMAIN"}) {
+		t.Errorf("conditions = %v", got)
+	}
+}
+
+func TestController_CheckAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		annotations map[string]string
+		wantURL     string
+		wantConds   []any
+	}{
+		{"icmp on a service", "tcp://switch.network.svc:22", map[string]string{"check": "icmp"},
+			"icmp://switch.network.svc", []any{gatus.ConnectedCondition}},
+		{"icmp on an ingress host", "https://app.example.com/healthz", map[string]string{"check": "ICMP"},
+			"icmp://app.example.com", []any{gatus.ConnectedCondition}},
+		{"http on a service", "tcp://web.default.svc:8080", map[string]string{"check": "http"},
+			"http://web.default.svc:8080", []any{gatus.StatusOKCondition}},
+		{"websocket over TLS", "https://chat.example.com", map[string]string{"check": "websocket"},
+			"wss://chat.example.com", []any{gatus.ConnectedCondition}},
+		{"websocket without TLS", "http://chat.example.com", map[string]string{"check": "websocket"},
+			"ws://chat.example.com", []any{gatus.ConnectedCondition}},
+		{"dns resolves the host", "https://app.example.com", map[string]string{"check": "dns"},
+			gatus.GuardedProbeURL, []any{gatus.DNSNoErrorCondition}},
+		{"wins over guarded", "https://app.example.com", map[string]string{"check": "tls", "tpl": "guarded: true\n"},
+			"tls://app.example.com:443", []any{gatus.ConnectedCondition}},
+		{"unknown type is ignored", "https://app.example.com", map[string]string{"check": "ping"},
+			"https://app.example.com", []any{"[STATUS] == 200"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				CheckAnnotation:    "check",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{
+				gvr:        gvr,
+				conditions: []string{"[STATUS] == 200"},
+				guardHost:  "app.example.com",
+				urlFn:      func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["url"]; got != tt.wantURL {
+				t.Errorf("url = %v, want %v", got, tt.wantURL)
+			}
+			if got, _ := endpoints[0]["conditions"].([]any); !reflect.DeepEqual(got, tt.wantConds) {
+				t.Errorf("conditions = %q, want %q", got, tt.wantConds)
+			}
+		})
+	}
+}
+
+func TestController_TLSCheck(t *testing.T) {
+	cases := []struct {
+		name        string
+		url         string
+		port        int
+		minValidity time.Duration
+		wantURL     string
+		wantConds   []any
+	}{
+		{"tls host", "https://app.example.com/healthz", 0, config.DefaultTLSMinValidity,
+			"tls://app.example.com:443", []any{"[CERTIFICATE_EXPIRATION] > 168h"}},
+		{"custom port and threshold", "https://app.example.com", 8443, 72 * time.Hour,
+			"tls://app.example.com:8443", []any{"[CERTIFICATE_EXPIRATION] > 72h"}},
+		{"no threshold only connects", "https://app.example.com", 0, 0,
+			"tls://app.example.com:443", []any{gatus.ConnectedCondition}},
+		{"plaintext host skipped", "http://app.example.com", 0, config.DefaultTLSMinValidity, "", nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				CheckAnnotation:    "check",
+				TLSCheckPort:       tt.port,
+				TLSMinValidity:     tt.minValidity,
+			}
+			r := fakeResource{
+				gvr:   schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"},
+				urlFn: func(metav1.Object) string { return tt.url },
+			}
+			endpoints := reconcileOne(t, cfg, r, map[string]string{"check": "tls"})
+			if tt.wantURL == "" {
+				if len(endpoints) != 0 {
+					t.Fatalf("got %v, want no endpoints", endpoints)
+				}
+				return
+			}
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["url"]; got != tt.wantURL {
+				t.Errorf("url = %v, want %v", got, tt.wantURL)
+			}
+			if got, _ := endpoints[0]["conditions"].([]any); !reflect.DeepEqual(got, tt.wantConds) {
+				t.Errorf("conditions = %q, want %q", got, tt.wantConds)
+			}
+		})
+	}
+}
+
+func TestController_HideURLAnnotation(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        any
+	}{
+		{"no annotation", nil, nil},
+		{"hidden", map[string]string{"hide": "true"}, map[string]any{"hide-url": true}},
+		{"invalid is ignored", map[string]string{"hide": "maybe"}, nil},
+		{"merges with the template's ui", map[string]string{"hide": "true", "tpl": "ui:\n  hide-hostname: true\n"},
+			map[string]any{"hide-url": true, "hide-hostname": true}},
+		{"template can turn it back off", map[string]string{"hide": "true", "tpl": "ui:\n  hide-url: false\n"},
+			map[string]any{"hide-url": false}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				HideURLAnnotation:  "hide",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["ui"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ui = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_DNSResolver(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+	}{
+		{"guarded", map[string]string{"tpl": "guarded: true\n"}},
+		{"dns check", map[string]string{"check": "dns"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				CheckAnnotation:    "check",
+				DNSResolver:        "9.9.9.9:53",
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{gvr: gvr, guardHost: "example.com"}
+			endpoints := reconcileOne(t, cfg, r, tt.annotations)
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			dns, _ := endpoints[0]["dns"].(map[string]any)
+			if got := endpoints[0]["url"]; got != "9.9.9.9:53" || dns["query-name"] != "example.com" {
+				t.Errorf("url = %v, dns = %v; want example.com queried against 9.9.9.9:53", got, dns)
+			}
+		})
+	}
+}
+
+func TestController_DNSResolverUnset(t *testing.T) {
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+	}
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	r := fakeResource{gvr: gvr, guardHost: "example.com"}
+	endpoints := reconcileOne(t, cfg, r, map[string]string{"tpl": "guarded: true\n"})
+	if len(endpoints) != 1 {
+		t.Fatalf("got %d endpoints, want 1", len(endpoints))
+	}
+	dns, _ := endpoints[0]["dns"].(map[string]any)
+	if _, ok := dns["resolver"]; ok {
+		t.Errorf("dns = %v, want no resolver without --dns-resolver", dns)
+	}
+	if got := endpoints[0]["url"]; got != gatus.GuardedProbeURL {
+		t.Errorf("url = %v, want the %s default", got, gatus.GuardedProbeURL)
+	}
+}
+
+func TestController_InvalidAnnotationWarnsOncePerVersion(t *testing.T) {
+	cfg := &config.Config{
+		DefaultInterval:          30 * time.Second,
+		ConnectTimeoutAnnotation: "timeout",
+		MethodAnnotation:         "method",
+	}
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	c := NewController(cfg, fakeResource{gvr: gvr}, gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml")), newFakeClient(gvr))
+	var buf bytes.Buffer
+	c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+	obj := makeUnstructured(gvr, map[string]string{"timeout": "soon", "method": "FETCH"})
+	obj.SetResourceVersion("1")
+	if err := c.informer.GetIndexer().Add(obj); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	reconcile := func() {
+		t.Helper()
+		if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	count := func(msg string) int { return strings.Count(buf.String(), msg) }
+
+	reconcile()
+	reconcile() // a resync of the same version
+	if count("invalid connect-timeout") != 1 || count("invalid method") != 1 {
+		t.Errorf("want each invalid annotation warned once per version; log: %s", buf.String())
+	}
+
+	obj = obj.DeepCopy()
+	obj.SetResourceVersion("2")
+	if err := c.informer.GetIndexer().Update(obj); err != nil {
+		t.Fatalf("update indexer: %v", err)
+	}
+	reconcile()
+	if count("invalid connect-timeout") != 2 {
+		t.Errorf("a new version should warn again; log: %s", buf.String())
+	}
+
+	c.forgetOutcome("default/thing-a")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.badAnnotations) != 0 {
+		t.Errorf("badAnnotations = %v after the object is forgotten, want empty", c.badAnnotations)
+	}
+}
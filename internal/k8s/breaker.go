@@ -0,0 +1,60 @@
+package k8s
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker is a circuit breaker over watch failures. client-go's reflector
+// already backs off between attempts, but only up to about 30s, so a
+// sustained apiserver outage still sees a steady stream of list/watch
+// requests from every informer. After threshold consecutive failures, each
+// within window of the previous one, the breaker opens and the caller
+// pauses for cooldown; one more failure once it has elapsed re-opens it,
+// and any success closes it again.
+type breaker struct {
+	threshold int
+	window    time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	last     time.Time
+	open     bool
+}
+
+func newBreaker(threshold int, window time.Duration) *breaker {
+	return &breaker{threshold: threshold, window: window, now: time.Now}
+}
+
+// failure records a failed watch and reports whether the caller should pause.
+// It is always false for a nil breaker or a zero threshold (disabled).
+func (b *breaker) failure() bool {
+	if b == nil || b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	// An open breaker stays open however long the pause was.
+	if b.failures > 0 && !b.open && now.Sub(b.last) > b.window {
+		b.failures = 0
+	}
+	b.failures++
+	b.last = now
+	b.open = b.failures >= b.threshold
+	return b.open
+}
+
+// success records a working watch, resetting the count. It reports whether
+// the breaker had been open, so the caller can log it closing.
+func (b *breaker) success() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	wasOpen := b.open
+	b.failures, b.open = 0, false
+	return wasOpen
+}
@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newBreaker(3, time.Minute)
+	b.now = func() time.Time { return now }
+	fail := func(after time.Duration) bool {
+		now = now.Add(after)
+		return b.failure()
+	}
+
+	if fail(0) || fail(10*time.Second) {
+		t.Fatal("tripped before the threshold")
+	}
+	if !fail(10 * time.Second) {
+		t.Fatal("third consecutive failure should trip")
+	}
+	// The retry after the cooldown fails again: still open, even though
+	// the pause outlasted the window.
+	if !fail(5 * time.Minute) {
+		t.Fatal("failure while open should keep the breaker open")
+	}
+	if !b.success() {
+		t.Error("success should report closing an open breaker")
+	}
+	if b.success() {
+		t.Error("success on a closed breaker should report nothing")
+	}
+
+	// Failures further apart than the window aren't consecutive.
+	for range 5 {
+		if fail(2 * time.Minute) {
+			t.Fatal("spread-out failures tripped the breaker")
+		}
+	}
+	// A success in between resets the count.
+	fail(time.Second)
+	fail(time.Second)
+	b.success()
+	if fail(time.Second) {
+		t.Fatal("count should restart after a success")
+	}
+}
+
+func TestBreaker_Disabled(t *testing.T) {
+	b := newBreaker(0, time.Minute)
+	for range 10 {
+		if b.failure() {
+			t.Fatal("a zero threshold should never trip")
+		}
+	}
+	var nilBreaker *breaker
+	if nilBreaker.failure() || nilBreaker.success() {
+		t.Error("a nil breaker should be inert")
+	}
+}
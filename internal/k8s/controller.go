@@ -1,29 +1,37 @@
 package k8s
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-	"net/url"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/gatus"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
 
 const (
 	defaultResync   = 10 * time.Minute
-	defaultWorkers  = 2
 	defaultMaxRetry = 5
 )
 
@@ -35,13 +43,60 @@ type Controller struct {
 	writer   *gatus.Writer
 	fetcher  Fetcher
 	informer cache.SharedIndexInformer
-	queue    workqueue.TypedRateLimitingInterface[string]
-	log      *slog.Logger
+	// parents watches the resource's parent kind with --watch-parents; nil
+	// otherwise.
+	parents cache.SharedIndexInformer
+	// dependencies watches the kind a DependentResource is gated on; nil
+	// otherwise. depsDropped is set when that kind can't be watched, and
+	// lookups of it go to the apiserver instead.
+	dependencies cache.SharedIndexInformer
+	depsDropped  atomic.Bool
+	queue        workqueue.TypedRateLimitingInterface[string]
+	log          *slog.Logger
+	// synced is closed once the initial listing has been reconciled.
+	synced chan struct{}
+	// breaker pauses the informers' watches during sustained failures;
+	// done ends such a pause early on shutdown.
+	breaker *breaker
+	done    <-chan struct{}
+	// stop ends Run early; skipped is set when that was because the kind
+	// can't be watched at all (no RBAC, no CRD) rather than shutdown.
+	stop    context.CancelFunc
+	skipped atomic.Bool
+	// stopParents ends the parent watch alone; parentsDropped is set when
+	// the parent kind can't be watched, so children sync without it.
+	stopParents    context.CancelFunc
+	parentsDropped atomic.Bool
+	stopDeps       context.CancelFunc
+
+	// owned maps an object's cache key to the writer keys it last produced,
+	// so targets that disappear (a removed host, port, ...) are cleaned up.
+	mu    sync.Mutex
+	owned map[string][]string
+	// outcomes holds each object's last reported outcome, guarded by mu;
+	// recorder is nil unless --emit-events is set.
+	outcomes map[string]outcome
+	recorder record.EventRecorder
+	// badTemplates holds the last template error warned about per object,
+	// guarded by mu, so a typo is logged once rather than every resync.
+	// badAnnotations does the same for the other annotations.
+	badTemplates   map[string]string
+	badAnnotations map[string]annotationWarnings
+
+	// stats counts reconcile outcomes since the last summary log.
+	statsMu sync.Mutex
+	stats   reconcileStats
+}
+
+// reconcileStats are the per-window counters behind the "reconcile summary"
+// log line.
+type reconcileStats struct {
+	processed, noURL, disabled, filtered, invalidURL, badTemplate int
 }
 
-func NewController(cfg *config.Config, r Resource, w *gatus.Writer, client dynamic.Interface) *Controller {
+func NewController(cfg *config.Config, r Resource, w *gatus.Writer, client dynamic.Interface, opts ...ControllerOption) *Controller {
 	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		client, defaultResync, cfg.Namespace, nil,
+		client, defaultResync, watchNamespace(cfg, r), listOptions(cfg),
 	)
 	informer := factory.ForResource(r.GVR()).Informer()
 	queue := workqueue.NewTypedRateLimitingQueueWithConfig(
@@ -50,26 +105,38 @@ func NewController(cfg *config.Config, r Resource, w *gatus.Writer, client dynam
 	)
 
 	c := &Controller{
-		cfg:      cfg,
-		resource: r,
-		writer:   w,
-		fetcher:  NewFetcher(client),
-		informer: informer,
-		queue:    queue,
-		log:      slog.With("resource", r.GVR().Resource),
+		cfg:            cfg,
+		resource:       r,
+		writer:         w,
+		fetcher:        newCachedFetcher(client, cfg.ParentCacheTTL, cfg.ParentGetTimeout, cfg.ParentGetRetries),
+		informer:       informer,
+		queue:          queue,
+		log:            slog.With("resource", r.GVR().Resource),
+		synced:         make(chan struct{}),
+		breaker:        newBreaker(cfg.BreakerThreshold, cfg.BreakerWindow),
+		owned:          make(map[string][]string),
+		outcomes:       make(map[string]outcome),
+		badTemplates:   make(map[string]string),
+		badAnnotations: make(map[string]annotationWarnings),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if pr, ok := r.(ParentResource); ok && cfg.WatchParents {
+		c.watchParents(pr, client)
+	}
+	if dr, ok := r.(DependentResource); ok {
+		if gvr, on := dr.DependencyGVR(cfg); on {
+			c.watchDependencies(dr, gvr, client)
+		}
 	}
 
+	_ = informer.SetWatchErrorHandler(c.watchError)
 	_, _ = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: c.enqueue,
-		UpdateFunc: func(_, obj any) {
-			c.enqueue(obj)
-		},
-		DeleteFunc: func(obj any) {
-			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
-				obj = tombstone.Obj
-			}
-			c.enqueue(obj)
-		},
+		AddFunc:    c.onAdd,
+		UpdateFunc: c.onUpdate,
+		DeleteFunc: c.onDelete,
 	})
 
 	return c
@@ -80,12 +147,45 @@ func (c *Controller) Resource() string {
 	return c.resource.GVR().Resource
 }
 
-// Run blocks until ctx is cancelled.
+// Synced returns a channel closed once Run has reconciled the initial
+// listing into the writer.
+func (c *Controller) Synced() <-chan struct{} {
+	return c.synced
+}
+
+// Run blocks until ctx is cancelled. With --once it returns as soon as the
+// initial listing has been reconciled, leaving the flush to the caller.
 func (c *Controller) Run(ctx context.Context) error {
 	c.log.Info("controller starting")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	c.done = ctx.Done()
+	c.stop = cancel
 	go c.informer.Run(ctx.Done())
+	synced := []cache.InformerSynced{c.informer.HasSynced}
+	if c.parents != nil {
+		parentCtx, stopParents := context.WithCancel(ctx)
+		defer stopParents()
+		c.stopParents = stopParents
+		go c.parents.Run(parentCtx.Done())
+		synced = append(synced, func() bool { return c.parentsDropped.Load() || c.parents.HasSynced() })
+	}
+
+	if c.dependencies != nil {
+		depCtx, stopDeps := context.WithCancel(ctx)
+		defer stopDeps()
+		c.stopDeps = stopDeps
+		go c.dependencies.Run(depCtx.Done())
+		synced = append(synced, func() bool { return c.depsDropped.Load() || c.dependencies.HasSynced() })
+	}
 
-	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
+		if c.skipped.Load() {
+			// Skipped, not failed: the other kinds carry on without it.
+			c.queue.ShutDown()
+			close(c.synced)
+			return nil
+		}
 		return fmt.Errorf("cache sync failed for %s", c.Resource())
 	}
 	c.log.Info("informer synced", "count", len(c.informer.GetIndexer().ListKeys()))
@@ -93,14 +193,26 @@ func (c *Controller) Run(ctx context.Context) error {
 	// Drain the queue once before workers start so the file is flushed once,
 	// not N times during initial sync.
 	c.initialReconcile(ctx)
-	if err := c.writer.Flush(); err != nil {
+	c.logSummary()
+	close(c.synced)
+	if c.cfg.Once {
+		c.queue.ShutDown()
+		return ctx.Err()
+	}
+	if _, err := c.writer.Flush(); err != nil {
 		c.log.Error("initial flush failed", "error", err)
 	}
 
 	var wg sync.WaitGroup
-	for range defaultWorkers {
+	for range c.workers() {
 		wg.Go(func() { c.runWorker(ctx) })
 	}
+	if c.cfg.ResyncInterval > 0 {
+		wg.Go(func() { c.runResync(ctx) })
+	}
+	if c.cfg.SummaryInterval > 0 {
+		wg.Go(func() { c.runSummary(ctx) })
+	}
 
 	<-ctx.Done()
 	c.queue.ShutDown()
@@ -108,21 +220,355 @@ func (c *Controller) Run(ctx context.Context) error {
 	return nil
 }
 
-// initialReconcile drains the queue with flush suppressed. Failures are
-// re-queued so the worker loop logs and retries them later.
+// initialReconcile drains the queue with flush suppressed, using
+// --reconcile-workers goroutines so parent lookups overlap. Failures are
+// re-queued so the worker loop logs and retries them later; with --once
+// there is no worker loop, so they are logged and dropped.
 func (c *Controller) initialReconcile(ctx context.Context) {
-	for ctx.Err() == nil && c.queue.Len() > 0 {
+	var mu sync.Mutex
+	// next hands out queued keys until the queue is drained. Nothing else
+	// takes from the queue yet, so under mu a non-zero Len means Get won't
+	// block; keys failed and re-queued meanwhile are left to the workers.
+	next := func() (string, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil || c.queue.Len() == 0 {
+			return "", false
+		}
 		key, shutdown := c.queue.Get()
-		if shutdown {
+		return key, !shutdown
+	}
+	var wg sync.WaitGroup
+	for range c.workers() {
+		wg.Go(func() {
+			for key, ok := next(); ok; key, ok = next() {
+				c.initialReconcileKey(ctx, key)
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (c *Controller) initialReconcileKey(ctx context.Context, key string) {
+	defer c.queue.Done(key)
+	if _, err := c.reconcile(ctx, key, false); err != nil {
+		if c.cfg.Once {
+			c.log.Error("reconcile failed", "key", key, "error", err)
+			c.queue.Forget(key)
+		} else {
+			c.queue.AddRateLimited(key)
+		}
+		return
+	}
+	c.queue.Forget(key)
+}
+
+// workers is --reconcile-workers, or its default for a Config built
+// without Load.
+func (c *Controller) workers() int {
+	if c.cfg.ReconcileWorkers < 1 {
+		return config.DefaultReconcileWorkers
+	}
+	return c.cfg.ReconcileWorkers
+}
+
+// watchParents sets up an informer on r's parent kind across all namespaces
+// (a route's Gateway may live elsewhere) and requeues an object's children
+// whenever it changes. Parent lookups are then served from that informer,
+// so the requeued children see the new template rather than a cached one.
+func (c *Controller) watchParents(r ParentResource, client dynamic.Interface) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		client, defaultResync, metav1.NamespaceAll, listOptions(c.cfg),
+	)
+	c.parents = factory.ForResource(r.ParentGVR()).Informer()
+	c.fetcher = &storeFetcher{gvr: r.ParentGVR(), store: c.parents.GetStore(), next: c.fetcher}
+	_ = c.parents.SetWatchErrorHandler(c.parentWatchError)
+	requeue := func(obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			c.log.Error("derive parent cache key", "error", err)
 			return
 		}
-		if err := c.reconcile(ctx, key, false); err != nil {
-			c.queue.AddRateLimited(key)
-		} else {
-			c.queue.Forget(key)
+		c.requeueChildren(r, key)
+	}
+	_, _ = c.parents.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    requeue,
+		UpdateFunc: func(_, obj any) { requeue(obj) },
+		DeleteFunc: requeue,
+	})
+}
+
+// watchDependencies sets up an informer on gvr, the kind r is gated on, in
+// the controller's namespaces, serves lookups of it from there, and
+// requeues the objects a changed one gates.
+func (c *Controller) watchDependencies(r DependentResource, gvr schema.GroupVersionResource, client dynamic.Interface) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		client, defaultResync, watchNamespace(c.cfg, r), nil,
+	)
+	c.dependencies = factory.ForResource(gvr).Informer()
+	c.fetcher = &storeFetcher{gvr: gvr, store: c.dependencies.GetStore(), next: c.fetcher, bypass: c.depsDropped.Load}
+	_ = c.dependencies.SetWatchErrorHandler(c.dependencyWatchError)
+	requeue := func(obj any) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		dep, err := meta.Accessor(obj)
+		if err != nil {
+			return
+		}
+		for _, key := range r.DependentKeys(dep) {
+			c.queue.Add(key)
+		}
+	}
+	_, _ = c.dependencies.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    requeue,
+		UpdateFunc: func(_, obj any) { requeue(obj) },
+		DeleteFunc: requeue,
+	})
+}
+
+// requeueChildren queues every cached object whose parent is parentKey.
+func (c *Controller) requeueChildren(r ParentResource, parentKey string) {
+	queued := 0
+	for _, item := range c.informer.GetIndexer().List() {
+		u, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		obj, err := r.Convert(u)
+		if err != nil || !slices.Contains(r.ParentKeys(obj), parentKey) {
+			continue
 		}
-		c.queue.Done(key)
+		c.enqueue(u)
+		queued++
+	}
+	if queued > 0 {
+		c.log.Debug("parent changed, requeued children", "parent", parentKey, "children", queued)
+	}
+}
+
+// runResync calls resync every --resync-interval until ctx is done.
+func (c *Controller) runResync(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.ResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.resync()
+		}
+	}
+}
+
+// resync queues every object in the informer cache plus every object the
+// controller still owns endpoints for. The latter covers deletes whose
+// event was missed: the object is no longer cached, so reconcile prunes
+// its endpoints.
+func (c *Controller) resync() {
+	keys := c.informer.GetIndexer().ListKeys()
+	c.mu.Lock()
+	for key := range c.owned {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	for _, key := range keys {
+		c.queue.Add(key)
+	}
+	c.log.Debug("periodic resync queued", "keys", len(keys))
+}
+
+// runSummary logs the reconcile summary every --summary-interval.
+func (c *Controller) runSummary(ctx context.Context) {
+	ticker := time.NewTicker(c.cfg.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.logSummary()
+		}
+	}
+}
+
+// count applies update to the current summary window's counters.
+func (c *Controller) count(update func(*reconcileStats)) {
+	c.statsMu.Lock()
+	update(&c.stats)
+	c.statsMu.Unlock()
+}
+
+// logSummary logs and resets the counters, answering "why is my list
+// empty" without debug logging. Quiet windows log nothing.
+func (c *Controller) logSummary() {
+	c.statsMu.Lock()
+	s := c.stats
+	c.stats = reconcileStats{}
+	c.statsMu.Unlock()
+	if s == (reconcileStats{}) {
+		return
+	}
+	c.log.Info("reconcile summary", "processed", s.processed,
+		"skipped_no_url", s.noURL, "skipped_disabled", s.disabled,
+		"skipped_filtered", s.filtered, "skipped_invalid_url", s.invalidURL,
+		"bad_template", s.badTemplate)
+}
+
+// watchError replaces client-go's klog handler so dropped watches show up in
+// the sidecar's own logs. The reflector reconnects by itself afterwards,
+// re-listing when its resourceVersion has expired; it already requests
+// bookmarks, so expired and cleanly closed watches are routine. Real
+// failures feed the circuit breaker, which blocks the reflector here while
+// it is open.
+func (c *Controller) watchError(_ *cache.Reflector, err error) {
+	c.handleWatchError(err, c.informer.HasSynced, c.skipUnwatchable)
+}
+
+// parentWatchError is watchError for the --watch-parents informer, which
+// drops the parent watch instead of the controller when its kind can't be
+// watched.
+func (c *Controller) parentWatchError(_ *cache.Reflector, err error) {
+	c.handleWatchError(err, c.parents.HasSynced, c.dropParents)
+}
+
+// dependencyWatchError is watchError for the informer watchDependencies
+// sets up, which falls back to apiserver lookups when its kind can't be
+// watched.
+func (c *Controller) dependencyWatchError(_ *cache.Reflector, err error) {
+	c.handleWatchError(err, c.dependencies.HasSynced, c.dropDependencies)
+}
+
+// handleWatchError handles err from an informer that has synced once
+// synced reports true, calling unwatchable for a refusal before that.
+func (c *Controller) handleWatchError(err error, synced func() bool, unwatchable func(msg string, err error)) {
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		apierrors.IsResourceExpired(err), apierrors.IsGone(err):
+		c.log.Debug("watch closed, reconnecting", "error", err)
+		c.watchSucceeded()
+	case apierrors.IsForbidden(err):
+		c.refused(err, "missing RBAC: need list/watch on %s; grant it to the ServiceAccount", synced, unwatchable)
+	case apierrors.IsNotFound(err):
+		c.refused(err, "%s is not served by the apiserver; is its CRD installed?", synced, unwatchable)
+	default:
+		c.log.Warn("watch failed, reconnecting", "error", err)
+		if c.breaker.failure() {
+			c.pauseWatch()
+		}
+	}
+}
+
+// refused handles the apiserver refusing to list or watch a kind, because
+// the ServiceAccount lacks RBAC for it (403) or nothing serves it (404,
+// usually a missing CRD). Before the informer's initial sync, retrying
+// fixes neither, so rather than fail every few seconds unwatchable gets
+// hint, formatted with the kind, and gives the kind up. Once synced the
+// kind was watchable, so a refusal is likely passing (a Role rotated by an
+// upgrade, a CRD being reinstalled): the hint is logged and the reflector
+// retries, paced by the circuit breaker, keeping the endpoints already
+// written.
+func (c *Controller) refused(err error, hint string, synced func() bool, unwatchable func(msg string, err error)) {
+	gr := c.resource.GVR().GroupResource()
+	// The refused kind may be the parent one, named in the error.
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if d := status.Status().Details; d != nil && d.Kind != "" {
+			gr = schema.GroupResource{Group: d.Group, Resource: d.Kind}
+		}
+	}
+	msg := fmt.Sprintf(hint, gr)
+	if synced() {
+		c.log.Warn(msg+"; retrying", "error", err)
+		if c.breaker.failure() {
+			c.pauseWatch()
+		}
+		return
+	}
+	unwatchable(msg, err)
+}
+
+// skipUnwatchable stops the controller, leaving the other kinds running.
+func (c *Controller) skipUnwatchable(msg string, err error) {
+	if !c.skipped.CompareAndSwap(false, true) {
+		return
+	}
+	c.log.Warn(msg+"; skipping this controller", "error", err)
+	if c.stop != nil {
+		c.stop()
+	}
+}
+
+// dropParents stops the parent watch, leaving the controller to sync
+// without it: parent lookups then find nothing, so objects get no parent
+// annotations rather than no endpoints.
+func (c *Controller) dropParents(msg string, err error) {
+	if !c.parentsDropped.CompareAndSwap(false, true) {
+		return
+	}
+	c.log.Warn(msg+"; ignoring parent annotations", "error", err)
+	if c.stopParents != nil {
+		c.stopParents()
+	}
+}
+
+// dropDependencies stops the dependency watch, leaving lookups of that kind
+// to the apiserver as they were without it.
+func (c *Controller) dropDependencies(msg string, err error) {
+	if !c.depsDropped.CompareAndSwap(false, true) {
+		return
+	}
+	c.log.Warn(msg+"; looking it up per reconcile instead", "error", err)
+	if c.stopDeps != nil {
+		c.stopDeps()
+	}
+}
+
+// pauseWatch holds off the reflector for --circuit-break-cooldown, or until
+// shutdown.
+func (c *Controller) pauseWatch() {
+	c.log.Warn("circuit breaker open, pausing watch", "cooldown", c.cfg.BreakerCooldown)
+	select {
+	case <-c.done:
+	case <-time.After(c.cfg.BreakerCooldown):
+		c.log.Info("circuit breaker half-open, retrying watch")
+	}
+}
+
+// watchSucceeded closes the circuit breaker once a watch works again.
+func (c *Controller) watchSucceeded() {
+	if c.breaker.success() {
+		c.log.Info("circuit breaker closed, watch recovered")
+	}
+}
+
+// onAdd, onUpdate and onDelete queue the object an informer event is about.
+// An event the apiserver delivered also shows the watch (or its list)
+// working again; the informer's periodic resync replays unchanged objects
+// from its cache, so those updates don't count, however long the outage.
+func (c *Controller) onAdd(obj any) {
+	c.watchSucceeded()
+	c.enqueue(obj)
+}
+
+func (c *Controller) onUpdate(old, obj any) {
+	oldMeta, err1 := meta.Accessor(old)
+	newMeta, err2 := meta.Accessor(obj)
+	if err1 != nil || err2 != nil || oldMeta.GetResourceVersion() != newMeta.GetResourceVersion() {
+		c.watchSucceeded()
 	}
+	c.enqueue(obj)
+}
+
+func (c *Controller) onDelete(obj any) {
+	c.watchSucceeded()
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	c.enqueue(obj)
 }
 
 func (c *Controller) enqueue(obj any) {
@@ -146,7 +592,7 @@ func (c *Controller) processNext(ctx context.Context) bool {
 	}
 	defer c.queue.Done(key)
 
-	if err := c.reconcile(ctx, key, true); err != nil {
+	if _, err := c.reconcile(ctx, key, true); err != nil {
 		retries := c.queue.NumRequeues(key)
 		if retries < defaultMaxRetry {
 			c.log.Warn("reconcile failed, requeueing",
@@ -161,103 +607,515 @@ func (c *Controller) processNext(ctx context.Context) bool {
 }
 
 // reconcile inspects the informer cache for key and either Upserts or
-// Deletes the corresponding endpoint. flush controls whether the writer
-// rewrites the output file after this call.
-func (c *Controller) reconcile(ctx context.Context, key string, flush bool) error {
+// Deletes the corresponding endpoints, reporting whether that changed the
+// writer's state. flush controls whether the writer rewrites the output file
+// after this call. It reads only the informer cache and the parent fetcher,
+// so tests can drive it by seeding the indexer directly.
+func (c *Controller) reconcile(ctx context.Context, key string, flush bool) (bool, error) {
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		return fmt.Errorf("split key %q: %w", key, err)
+		return false, fmt.Errorf("split key %q: %w", key, err)
 	}
-	endpointKey := makeEndpointKey(name, namespace, c.resource.GVR())
+	baseKey := makeEndpointKey(name, namespace, c.resource.GVR())
 
 	raw, exists, err := c.informer.GetIndexer().GetByKey(key)
 	if err != nil {
-		return fmt.Errorf("get %q: %w", key, err)
+		return false, fmt.Errorf("get %q: %w", key, err)
 	}
 	if !exists {
-		return c.removeEndpoint(endpointKey, namespace, name, "deleted", flush)
+		c.forgetOutcome(key)
+		return c.syncOwned(key, nil, "deleted", flush)
 	}
 
 	u, ok := raw.(*unstructured.Unstructured)
 	if !ok {
-		return fmt.Errorf("unexpected cache type %T", raw)
+		return false, fmt.Errorf("unexpected cache type %T", raw)
 	}
 	obj, err := c.resource.Convert(u)
 	if err != nil {
-		return fmt.Errorf("convert: %w", err)
+		return false, fmt.Errorf("convert: %w", err)
 	}
 
 	if !c.resource.Matches(obj, c.cfg) {
-		return c.removeEndpoint(endpointKey, namespace, name, "not-matched", flush)
+		c.log.Debug("resource not matched by filters", "key", key)
+		if c.cfg.Disabled(obj.GetAnnotations()) {
+			c.count(func(s *reconcileStats) { s.disabled++ })
+			c.report(u, key, outcomeDisabled)
+		} else {
+			c.count(func(s *reconcileStats) { s.filtered++ })
+			c.report(u, key, outcomeFiltered)
+		}
+		return c.syncOwned(key, nil, "not-matched", flush)
+	}
+	if !c.cfg.InShard(obj.GetAnnotations()) {
+		// Another shard's sidecar owns it; report it like any filter so
+		// sibling shards don't each add an Event to the object.
+		c.log.Debug("resource belongs to another shard", "key", key)
+		c.count(func(s *reconcileStats) { s.filtered++ })
+		c.report(u, key, outcomeFiltered)
+		return c.syncOwned(key, nil, "other-shard", flush)
+	}
+	if g, ok := c.resource.(ReadinessGate); ok && !g.Ready(ctx, obj, c.cfg, c.fetcher) {
+		c.log.Debug("resource not ready", "key", key)
+		c.count(func(s *reconcileStats) { s.filtered++ })
+		c.report(u, key, outcomeNotReady)
+		return c.syncOwned(key, nil, "not-ready", flush)
+	}
+	if c.parentDisabled(ctx, obj) {
+		c.log.Debug("resource disabled by its parent", "key", key)
+		c.count(func(s *reconcileStats) { s.disabled++ })
+		c.report(u, key, outcomeParentDisabled)
+		return c.syncOwned(key, nil, "parent-disabled", flush)
+	}
+
+	tpl, err := c.buildTemplate(ctx, obj)
+	c.warnBadTemplate(key, err)
+	if err != nil {
+		c.count(func(s *reconcileStats) { s.badTemplate++ })
+		if !c.cfg.IgnoreBadTemplates {
+			// Retrying can't fix the YAML; the object's next update will.
+			// Whatever it generated before the typo stays meanwhile.
+			c.report(u, key, outcomeBadTemplate)
+			return false, nil
+		}
 	}
 
-	probeURL := c.resource.URL(obj)
-	if probeURL == "" {
+	targets := c.targets(ctx, obj)
+	if url, _ := tpl.object["url"].(string); len(targets) == 0 && url != "" {
+		// Nothing extracted (an Ingress with no rules yet, a --watch-gvr
+		// kind), but the object's own template names the probe.
+		targets = []Target{{URL: url}}
+	}
+	if len(targets) == 0 {
 		// Per-resync per-resource; common for headless Services.
 		c.log.Debug("resource has no derivable URL", "namespace", namespace, "name", name)
-		return c.removeEndpoint(endpointKey, namespace, name, "no-url", flush)
+		c.count(func(s *reconcileStats) { s.noURL++ })
+		c.report(u, key, outcomeNoURL)
+		return c.syncOwned(key, nil, "no-url", flush)
 	}
 
-	merged, err := c.buildTemplate(ctx, obj)
+	external := c.external(obj)
+	// An explicit check type picks the probe outright, guarded included.
+	check := c.check(obj)
+	guarded := check == "" && c.guarded(obj, tpl.merged)
+	keep := make([]string, 0, len(targets))
+	changed := false
+	for _, t := range targets {
+		endpointKey := targetKey(baseKey, t.Suffix)
+		e, err := c.buildEndpoint(obj, t, tpl, check, guarded)
+		if errors.Is(err, errNotTLS) {
+			c.log.Debug("skipping plaintext target for tls check",
+				"namespace", namespace, "name", name, "url", t.URL)
+			continue
+		}
+		if err != nil {
+			c.warnAnnotation(obj, "skipping resource with invalid URL",
+				"url", t.URL, "error", err)
+			c.count(func(s *reconcileStats) { s.invalidURL++ })
+			continue
+		}
+		c.log.Debug("extracted target", "key", endpointKey, "url", e.URL,
+			"check", check, "guarded", guarded, "external", external)
+		upsert := c.upsertEndpoint
+		if external {
+			upsert = c.upsertExternal
+		}
+		stored, updated, err := upsert(obj, endpointKey, e)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || updated
+		if stored {
+			keep = append(keep, endpointKey)
+		}
+	}
+	reason := "stale"
+	if len(keep) == 0 {
+		reason = "invalid"
+		c.report(u, key, outcomeInvalid)
+	} else {
+		c.count(func(s *reconcileStats) { s.processed++ })
+		c.report(u, key, outcomeGenerated(len(keep)))
+	}
+	removed, err := c.syncOwned(key, keep, reason, flush)
+	return changed || removed, err
+}
+
+// upsertEndpoint stores e as a probed endpoint. stored is always true;
+// changed reports whether the writer's copy differed.
+func (c *Controller) upsertEndpoint(obj metav1.Object, key string, e *gatus.Endpoint) (stored, changed bool, err error) {
+	changed, err = c.writer.Upsert(key, e, false)
 	if err != nil {
-		return err
+		return false, false, fmt.Errorf("write after upsert: %w", err)
 	}
+	if changed {
+		c.log.Info("updated endpoint", "namespace", obj.GetNamespace(), "name", obj.GetName(), "url", e.URL)
+	} else {
+		c.log.Debug("endpoint unchanged", "key", key)
+	}
+	return true, changed, nil
+}
 
+// upsertExternal stores e as an external endpoint. stored is false, after
+// logging why, when e lacks what Gatus requires of one (a token).
+func (c *Controller) upsertExternal(obj metav1.Object, key string, e *gatus.Endpoint) (stored, changed bool, err error) {
+	ext, err := gatus.NewExternalEndpoint(e)
+	if err != nil {
+		c.warnAnnotation(obj, "skipping invalid external endpoint",
+			"error", err)
+		return false, false, nil
+	}
+	changed, err = c.writer.UpsertExternal(key, ext, false)
+	if err != nil {
+		return false, false, fmt.Errorf("write after upsert: %w", err)
+	}
+	if changed {
+		c.log.Info("updated external endpoint", "namespace", obj.GetNamespace(), "name", obj.GetName())
+	} else {
+		c.log.Debug("external endpoint unchanged", "key", key)
+	}
+	return true, changed, nil
+}
+
+// targets returns the probe targets for obj: the resource's own fan-out
+// when it implements [MultiTargetResource] (extended by [TargetResolver] if
+// implemented too), else a single unsuffixed target built from
+// URL/GuardHost. Targets without a URL, or rejected by the host/port filter
+// annotations, are dropped.
+func (c *Controller) targets(ctx context.Context, obj metav1.Object) []Target {
+	var targets []Target
+	if m, ok := c.resource.(MultiTargetResource); ok {
+		targets = m.Targets(obj, c.cfg)
+		if r, ok := c.resource.(TargetResolver); ok {
+			targets = r.ResolveTargets(ctx, obj, targets, c.cfg, c.fetcher)
+		}
+		hostFilter := c.targetFilter(obj, c.cfg.HostFilterAnnotation)
+		portFilter := c.targetFilter(obj, c.cfg.PortFilterAnnotation)
+		targets = slices.DeleteFunc(targets, func(t Target) bool {
+			return t.URL == "" || filteredOut(hostFilter, t.Host) || filteredOut(portFilter, t.Port)
+		})
+	} else if probeURL := c.resource.URL(obj); probeURL != "" {
+		targets = []Target{{URL: probeURL, GuardHost: c.resource.GuardHost(obj)}}
+	}
+	// --hostname-rewrite changes only what is probed: host filters and the
+	// DNS guard still see the advertised name.
+	for i, t := range targets {
+		host := urlutil.Hostname(t.URL)
+		if rewritten := c.cfg.HostRewrites.Rewrite(host); rewritten != host {
+			targets[i].URL = urlutil.SetHost(t.URL, rewritten)
+		}
+	}
+	return targets
+}
+
+// errNotTLS rejects a tls check on a plaintext http:// or ws:// target,
+// which has no certificate to monitor.
+var errNotTLS = errors.New("tls check on a plaintext target")
+
+// buildEndpoint renders one target into an Endpoint. It fails when the
+// extracted URL doesn't validate, or with errNotTLS.
+func (c *Controller) buildEndpoint(obj metav1.Object, t Target, tpl templates, check string, guarded bool) (*gatus.Endpoint, error) {
+	probeURL := t.URL
+	// A HostHeader target points at a proxy whose scheme is its own
+	// (--ingress-internal-probe); the annotation describes the host.
+	if scheme := c.scheme(obj); scheme != "" && t.HostHeader == "" {
+		probeURL = urlutil.SetHTTPScheme(probeURL, scheme)
+	}
 	// "path:" beats --probe-paths; "url:" beats both (applied via ApplyTemplate).
-	if override, ok := gatus.PathOverride(merged); ok {
-		probeURL = setURLPath(probeURL, override)
+	if override, ok := gatus.PathOverride(tpl.merged); ok {
+		probeURL = urlutil.SetPath(probeURL, override)
 	} else if !c.cfg.ProbePaths {
-		probeURL = setURLPath(probeURL, "")
+		probeURL = urlutil.SetPath(probeURL, "")
+	}
+
+	// An explicit "url:" replaces the extracted one, so only validate ours.
+	if _, ok := tpl.merged["url"]; !ok {
+		if err := urlutil.Validate(probeURL); err != nil {
+			return nil, err
+		}
+	}
+	probeHost := urlutil.Hostname(probeURL)
+	if check == gatus.CheckTLS && (strings.HasPrefix(probeURL, "http://") || strings.HasPrefix(probeURL, "ws://")) {
+		return nil, errNotTLS
+	}
+	if check != "" {
+		probeURL = urlutil.SetCheckScheme(probeURL, check)
+	}
+	if check == gatus.CheckTLS && c.cfg.TLSCheckPort > 0 {
+		probeURL = urlutil.SetPort(probeURL, c.cfg.TLSCheckPort)
 	}
 
 	e := &gatus.Endpoint{
-		Name:     c.resource.Prefix(c.cfg) + name,
+		Name:     c.resource.Prefix(c.cfg) + c.endpointName(obj),
 		URL:      probeURL,
-		Interval: c.cfg.DefaultInterval.String(),
+		Interval: c.resource.Interval(c.cfg).String(),
 	}
-	if gatus.IsGuarded(merged) {
-		if host := c.resource.GuardHost(obj); host != "" {
-			gatus.ApplyGuardedDNS(host, e)
+	switch {
+	case t.DNSServer != "" && (check == "" || check == gatus.CheckDNS):
+		gatus.ApplyDNSCheck(t.DNSServer, t.DNSQuery, e)
+	case check == gatus.CheckDNS:
+		// Resolve the host itself rather than query a server running there.
+		gatus.ApplyDNSCheck(cmp.Or(c.cfg.DNSResolver, gatus.GuardedProbeURL), probeHost, e)
+	case check == gatus.CheckTLS && c.cfg.TLSMinValidity > 0:
+		e.Conditions = []string{gatus.CertificateExpirationCondition(c.cfg.TLSMinValidity)}
+	case check != "":
+		e.Conditions, _ = gatus.CheckConditions(check)
+	case len(t.Conditions) > 0:
+		e.Conditions = slices.Clone(t.Conditions)
+	case guarded:
+		if t.GuardHost != "" {
+			gatus.ApplyGuardedDNS(c.cfg.DNSResolver, t.GuardHost, c.cfg.GuardedCondition, e)
 		}
-	} else {
+	default:
 		e.Conditions = c.resource.DefaultConditions()
 	}
-	e.ApplyTemplate(merged)
+	c.applyOverrides(obj, e)
+	if t.HostHeader != "" {
+		headers := make(map[string]string, len(c.cfg.DefaultHeaders)+1)
+		maps.Copy(headers, c.cfg.DefaultHeaders)
+		headers["Host"] = t.HostHeader
+		e.SetHeaders(headers)
+	}
+	base := slices.Clone(e.Conditions)
+	e.ApplyTemplate(tpl.merged)
+	if c.cfg.MergeConditions {
+		// The merged template only carries the child's list; rebuild from
+		// the defaults plus each level's own conditions instead.
+		e.Conditions = base
+		e.AppendConditions(gatus.TemplateConditions(tpl.parent))
+		e.AppendConditions(gatus.TemplateConditions(tpl.object))
+	}
+	// Body checks come on top of whichever conditions won; a guarded DNS
+	// probe has no HTTP body to check.
+	if !guarded {
+		e.AppendConditions(c.bodyConditions(obj))
+	}
+	e.Group = resolveGroup(obj, tpl.object, tpl.parent, c.cfg)
+	// Suffix after the template so a templated name still stays unique per
+	// target.
+	if t.Suffix != "" {
+		e.Name += "-" + t.Suffix
+	}
+	if c.cfg.SanitizeNames {
+		e.Name = gatus.SanitizeName(e.Name)
+	}
+	return e, nil
+}
 
-	changed, err := c.writer.Upsert(endpointKey, e, flush)
+// endpointName is the base name for obj's endpoints: metadata.name unless the
+// resource implements [NamedResource].
+func (c *Controller) endpointName(obj metav1.Object) string {
+	if n, ok := c.resource.(NamedResource); ok {
+		if name := n.EndpointName(obj); name != "" {
+			return name
+		}
+	}
+	return obj.GetName()
+}
+
+// templates holds the parent's and the object's parsed template annotations
+// alongside their merge. They are kept apart so callers that care about
+// provenance (see [resolveGroup]) can tell them apart.
+type templates struct {
+	parent, object, merged map[string]any
+}
+
+// buildTemplate parses and merges obj's and its parents' templates. A
+// template that doesn't parse is left out and reported as a
+// [*badTemplateError], the object's own taking precedence.
+func (c *Controller) buildTemplate(ctx context.Context, obj metav1.Object) (templates, error) {
+	parentTpl, parentErr := c.parentTemplate(ctx, obj)
+	value := obj.GetAnnotations()[c.cfg.TemplateAnnotation]
+	objTpl, err := gatus.ParseTemplate(value)
 	if err != nil {
-		return fmt.Errorf("write after upsert: %w", err)
+		err = &badTemplateError{source: "object", value: value, err: err}
+	} else {
+		err = parentErr
 	}
-	if changed {
-		c.log.Info("updated endpoint", "namespace", namespace, "name", name, "url", e.URL)
+	merge := gatus.MergeTemplates
+	if c.cfg.MergeLists {
+		merge = gatus.MergeTemplatesAppendingLists
 	}
-	return nil
+	return templates{
+		parent: parentTpl,
+		object: objTpl,
+		merged: merge(parentTpl, objTpl),
+	}, err
 }
 
-func (c *Controller) buildTemplate(ctx context.Context, obj metav1.Object) (map[string]any, error) {
-	parentAnnotations := c.resource.ParentAnnotations(ctx, obj, c.fetcher)
-	parentTpl, err := gatus.ParseTemplate(parentAnnotations[c.cfg.TemplateAnnotation])
-	if err != nil {
-		return nil, fmt.Errorf("parent template: %w", err)
+// badTemplateError is a template annotation that doesn't parse, along with
+// the annotation as written.
+type badTemplateError struct {
+	source, value string
+	err           error
+}
+
+func (e *badTemplateError) Error() string { return e.source + " template: " + e.err.Error() }
+func (e *badTemplateError) Unwrap() error { return e.err }
+
+// maxSnippet bounds how much of a bad template annotation is logged.
+const maxSnippet = 120
+
+// warnBadTemplate logs err, a [*badTemplateError] or nil, once per object
+// until it changes, quoting the start of the offending annotation.
+func (c *Controller) warnBadTemplate(key string, err error) {
+	c.mu.Lock()
+	last, seen := c.badTemplates[key]
+	if err == nil {
+		delete(c.badTemplates, key)
+	} else {
+		c.badTemplates[key] = err.Error()
 	}
-	objTpl, err := gatus.ParseTemplate(obj.GetAnnotations()[c.cfg.TemplateAnnotation])
-	if err != nil {
-		return nil, fmt.Errorf("object template: %w", err)
+	c.mu.Unlock()
+	var bad *badTemplateError
+	if !errors.As(err, &bad) || (seen && last == err.Error()) {
+		return
 	}
-	return gatus.MergeTemplates(parentTpl, objTpl), nil
+	snippet := bad.value
+	if len(snippet) > maxSnippet {
+		snippet = snippet[:maxSnippet] + "..."
+	}
+	c.log.Warn("invalid template annotation", "key", key, "source", bad.source,
+		"error", bad.err, "snippet", snippet, "ignored", c.cfg.IgnoreBadTemplates)
 }
 
-func (c *Controller) removeEndpoint(key, namespace, name, reason string, flush bool) error {
-	removed, err := c.writer.Delete(key, flush)
-	if err != nil {
-		return fmt.Errorf("write after delete: %w", err)
+// parentAnnotations returns the annotations of each of obj's parents, in
+// order; a single-parent Resource yields at most one map.
+func (c *Controller) parentAnnotations(ctx context.Context, obj metav1.Object) []map[string]string {
+	if mp, ok := c.resource.(MultiParentResource); ok {
+		return mp.AllParentAnnotations(ctx, obj, c.fetcher)
 	}
-	if removed {
-		c.log.Info("removed endpoint", "namespace", namespace, "name", name, "reason", reason)
+	if annotations := c.resource.ParentAnnotations(ctx, obj, c.fetcher); annotations != nil {
+		return []map[string]string{annotations}
 	}
 	return nil
 }
 
+// parentDisabled reports whether obj's parents switch it off: obj doesn't
+// set the enabled annotation itself and every parent sets it falsy. A
+// route on two Gateways is still monitored when only one opts out.
+func (c *Controller) parentDisabled(ctx context.Context, obj metav1.Object) bool {
+	if _, ok := obj.GetAnnotations()[c.cfg.EnabledAnnotation]; ok || c.cfg.EnabledAnnotation == "" {
+		return false
+	}
+	parents := c.parentAnnotations(ctx, obj)
+	return len(parents) > 0 && !slices.ContainsFunc(parents, func(annotations map[string]string) bool {
+		return !c.cfg.Disabled(annotations)
+	})
+}
+
+// parentTemplate parses the parent's template annotation. Several parents'
+// templates are deep-merged, the first parent winning.
+func (c *Controller) parentTemplate(ctx context.Context, obj metav1.Object) (map[string]any, error) {
+	var merged map[string]any
+	for _, annotations := range slices.Backward(c.parentAnnotations(ctx, obj)) {
+		value := annotations[c.cfg.TemplateAnnotation]
+		tpl, err := gatus.ParseTemplate(value)
+		if err != nil {
+			return nil, &badTemplateError{source: "parent", value: value, err: err}
+		}
+		merged = gatus.MergeTemplates(merged, tpl)
+	}
+	return merged, nil
+}
+
+// syncOwned makes keep the exact set of writer keys owned by the object at
+// objKey, deleting whatever it wrote previously that isn't in keep, and
+// reports whether anything was deleted. flush controls whether the writer
+// rewrites the output file afterwards.
+func (c *Controller) syncOwned(objKey string, keep []string, reason string, flush bool) (bool, error) {
+	c.mu.Lock()
+	previous := c.owned[objKey]
+	if len(keep) == 0 {
+		delete(c.owned, objKey)
+	} else {
+		c.owned[objKey] = keep
+	}
+	c.mu.Unlock()
+
+	anyRemoved := false
+	for _, key := range previous {
+		if slices.Contains(keep, key) {
+			continue
+		}
+		removed, err := c.writer.Delete(key, false)
+		if err != nil {
+			return anyRemoved, fmt.Errorf("write after delete: %w", err)
+		}
+		if removed {
+			anyRemoved = true
+			c.log.Info("removed endpoint", "key", key, "reason", reason)
+		}
+	}
+	if !flush {
+		return anyRemoved, nil
+	}
+	if err := c.writer.FlushIfDirty(); err != nil {
+		return anyRemoved, fmt.Errorf("flush: %w", err)
+	}
+	return anyRemoved, nil
+}
+
+// watchNamespace is the namespace r's informer lists and watches: --namespace,
+// or every namespace for cluster-scoped kinds, whose list path can't carry
+// one.
+func watchNamespace(cfg *config.Config, r Resource) string {
+	if cs, ok := r.(ClusterScopedResource); ok && cs.ClusterScoped() {
+		return metav1.NamespaceAll
+	}
+	return cfg.Namespace
+}
+
+// listOptions combines the informers' request tweaks: --watch-timeout on
+// watches and --list-page-size on lists.
+func listOptions(cfg *config.Config) dynamicinformer.TweakListOptionsFunc {
+	timeout, paging := watchTimeout(cfg), listPageSize(cfg)
+	return func(opts *metav1.ListOptions) {
+		if timeout != nil {
+			timeout(opts)
+		}
+		if paging != nil {
+			paging(opts)
+		}
+	}
+}
+
+// listPageSize applies --list-page-size to the informers' list requests,
+// which the reflector pages through by their continue token. Those are only
+// made when the apiserver can't stream the initial state over a watch. The
+// first asks for resource version "0", which the apiserver serves whole from
+// its watch cache whatever the limit, so that is cleared to make it page too.
+func listPageSize(cfg *config.Config) dynamicinformer.TweakListOptionsFunc {
+	if cfg.ListPageSize <= 0 {
+		return nil
+	}
+	return func(opts *metav1.ListOptions) {
+		if opts.TimeoutSeconds != nil || opts.Watch {
+			return
+		}
+		opts.Limit = cfg.ListPageSize
+		if opts.ResourceVersion == "0" {
+			opts.ResourceVersion, opts.ResourceVersionMatch = "", ""
+		}
+	}
+}
+
+// watchTimeout applies --watch-timeout to the informers' watch requests. The
+// reflector only sets TimeoutSeconds on watches, never on lists, so that is
+// what singles them out; it resumes from the last resource version once the
+// apiserver closes the watch.
+func watchTimeout(cfg *config.Config) dynamicinformer.TweakListOptionsFunc {
+	if cfg.WatchTimeout <= 0 {
+		return nil
+	}
+	seconds := int64(cfg.WatchTimeout / time.Second)
+	return func(opts *metav1.ListOptions) {
+		if opts.TimeoutSeconds != nil {
+			opts.TimeoutSeconds = &seconds
+		}
+	}
+}
+
 // makeEndpointKey returns a writer key unique across resource kinds. The
 // "/" separator can't appear in any of the three components (names and
 // namespaces follow DNS rules; resource is a plural identifier).
@@ -265,16 +1123,11 @@ func makeEndpointKey(name, namespace string, gvr schema.GroupVersionResource) st
 	return gvr.Resource + "/" + namespace + "/" + name
 }
 
-// setURLPath replaces rawURL's path with path (empty clears it). rawURL
-// is returned unchanged when it doesn't parse as an absolute URL.
-func setURLPath(rawURL, path string) string {
-	u, err := url.Parse(rawURL)
-	if err != nil || u.Scheme == "" {
-		return rawURL
-	}
-	if path != "" && !strings.HasPrefix(path, "/") {
-		path = "/" + path
+// targetKey extends an object's endpoint key with a target suffix. Suffixes
+// are hostnames or port names, which can't contain "/" either.
+func targetKey(baseKey, suffix string) string {
+	if suffix == "" {
+		return baseKey
 	}
-	u.Path = path
-	return u.String()
+	return baseKey + "/" + suffix
 }
@@ -1,23 +1,36 @@
 package k8s
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/gatus"
 
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // fakeResource is a minimal Resource implementation. Tests configure behavior
@@ -34,6 +47,7 @@ type fakeResource struct {
 
 func (f fakeResource) GVR() schema.GroupVersionResource                          { return f.gvr }
 func (f fakeResource) Prefix(*config.Config) string                              { return f.prefix }
+func (fakeResource) Interval(cfg *config.Config) time.Duration                   { return cfg.DefaultInterval }
 func (f fakeResource) DefaultConditions() []string                               { return f.conditions }
 func (f fakeResource) GuardHost(metav1.Object) string                            { return f.guardHost }
 func (fakeResource) Convert(u *unstructured.Unstructured) (metav1.Object, error) { return u, nil }
@@ -144,6 +158,358 @@ func TestController_ReconcileAddsAndDeletesEndpoint(t *testing.T) {
 	<-done
 }
 
+// clusterResource is a fakeResource for a kind without namespaces.
+type clusterResource struct{ fakeResource }
+
+func (clusterResource) ClusterScoped() bool { return true }
+
+func TestController_ClusterScopedIgnoresNamespace(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := newFakeClient(gvr)
+	obj := makeUnstructured(gvr, nil)
+	obj.SetNamespace("")
+	seed(t, client, gvr, obj)
+
+	// --namespace would hide the object from a namespaced controller.
+	cfg := &config.Config{
+		Namespace:          "apps",
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+	}
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+	c := NewController(cfg, clusterResource{fakeResource{gvr: gvr}}, writer, client)
+
+	ctx := t.Context()
+	go func() { _ = c.Run(ctx) }()
+	if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+		t.Fatalf("expected 1 endpoint, got %d", writer.Len())
+	}
+
+	if err := client.Resource(gvr).Delete(ctx, "thing-a", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if !waitFor(t, func() bool { return writer.Len() == 0 }) {
+		t.Fatalf("expected 0 endpoints, got %d", writer.Len())
+	}
+}
+
+func TestWatchNamespace(t *testing.T) {
+	cfg := &config.Config{Namespace: "apps"}
+	if got := watchNamespace(cfg, fakeResource{}); got != "apps" {
+		t.Errorf("namespaced: %q, want apps", got)
+	}
+	if got := watchNamespace(cfg, clusterResource{}); got != metav1.NamespaceAll {
+		t.Errorf("cluster-scoped: %q, want all namespaces", got)
+	}
+}
+
+func TestWatchTimeout(t *testing.T) {
+	if watchTimeout(&config.Config{}) != nil {
+		t.Error("zero --watch-timeout should keep client-go's default")
+	}
+	tweak := watchTimeout(&config.Config{WatchTimeout: 90 * time.Second})
+	list := metav1.ListOptions{}
+	tweak(&list)
+	if list.TimeoutSeconds != nil {
+		t.Errorf("list request got a timeout: %d", *list.TimeoutSeconds)
+	}
+	reflectorDefault := int64(400)
+	w := metav1.ListOptions{TimeoutSeconds: &reflectorDefault}
+	tweak(&w)
+	if *w.TimeoutSeconds != 90 {
+		t.Errorf("watch TimeoutSeconds = %d, want 90", *w.TimeoutSeconds)
+	}
+}
+
+func TestListPageSize(t *testing.T) {
+	if listPageSize(&config.Config{}) != nil {
+		t.Error("zero --list-page-size should leave paging to client-go")
+	}
+	tweak := listPageSize(&config.Config{ListPageSize: 100})
+	list := metav1.ListOptions{ResourceVersion: "0"}
+	tweak(&list)
+	if list.Limit != 100 || list.ResourceVersion != "" {
+		t.Errorf("list Limit = %d, ResourceVersion = %q; want 100 and empty", list.Limit, list.ResourceVersion)
+	}
+	reflectorDefault := int64(400)
+	w := metav1.ListOptions{TimeoutSeconds: &reflectorDefault, ResourceVersion: "0"}
+	tweak(&w)
+	if w.Limit != 0 || w.ResourceVersion != "0" {
+		t.Errorf("watch request was changed: %+v", w)
+	}
+}
+
+// pagedClient serves a kind's list requests from pages, standing in for an
+// apiserver that honours limit and continue (the fake client drops both)
+// but can't stream the initial list.
+type pagedClient struct {
+	dynamic.Interface
+	pages [][]unstructured.Unstructured
+
+	mu     sync.Mutex
+	limits []int64
+}
+
+// IsWatchListSemanticsUnSupported makes the reflector list rather than
+// stream, as it does against the fake client.
+func (c *pagedClient) IsWatchListSemanticsUnSupported() bool { return true }
+
+func (c *pagedClient) Resource(gvr schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return pagedResource{c.Interface.Resource(gvr), c}
+}
+
+type pagedResource struct {
+	dynamic.NamespaceableResourceInterface
+	client *pagedClient
+}
+
+func (r pagedResource) Namespace(ns string) dynamic.ResourceInterface {
+	return pagedNamespace{r.NamespaceableResourceInterface.Namespace(ns), r.client}
+}
+
+type pagedNamespace struct {
+	dynamic.ResourceInterface
+	client *pagedClient
+}
+
+func (r pagedNamespace) List(_ context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	r.client.mu.Lock()
+	defer r.client.mu.Unlock()
+	r.client.limits = append(r.client.limits, opts.Limit)
+	page, _ := strconv.Atoi(opts.Continue)
+	list := &unstructured.UnstructuredList{Items: r.client.pages[page]}
+	list.SetResourceVersion("1")
+	if page+1 < len(r.client.pages) {
+		list.SetContinue(strconv.Itoa(page + 1))
+	}
+	return list, nil
+}
+
+func TestController_ListsInPages(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	thing := func(name string) unstructured.Unstructured {
+		obj := makeUnstructured(gvr, nil)
+		obj.SetName(name)
+		return *obj
+	}
+	client := &pagedClient{
+		Interface: newFakeClient(gvr),
+		pages: [][]unstructured.Unstructured{
+			{thing("thing-a"), thing("thing-b")},
+			{thing("thing-c")},
+		},
+	}
+
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled", ListPageSize: 2}
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{gvr: gvr}, writer, client)
+	go func() { _ = c.Run(t.Context()) }()
+
+	if !waitFor(t, func() bool { return writer.Len() == 3 }) {
+		t.Fatalf("expected every page to be reconciled, got %d endpoints", writer.Len())
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if len(client.limits) != 2 || client.limits[0] != 2 || client.limits[1] != 2 {
+		t.Errorf("list limits = %v, want two pages of 2", client.limits)
+	}
+}
+
+func TestController_ReconnectsAfterWatchTimeout(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "ThingList"})
+	// Hand out watches the test controls, standing in for the apiserver
+	// closing each one when its timeout expires.
+	watches := make(chan *watch.FakeWatcher, 4)
+	timeouts := make(chan int64, 4)
+	client.PrependWatchReactor("things", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		timeout := int64(-1)
+		if opts := action.(k8stesting.WatchActionImpl).ListOptions; opts.TimeoutSeconds != nil {
+			timeout = *opts.TimeoutSeconds
+		}
+		timeouts <- timeout
+		w := watch.NewFake()
+		watches <- w
+		return true, w, nil
+	})
+
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+		WatchTimeout:       2 * time.Minute,
+	}
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{gvr: gvr}, writer, client)
+	go func() { _ = c.Run(t.Context()) }()
+
+	next := func() *watch.FakeWatcher {
+		t.Helper()
+		select {
+		case w := <-watches:
+			if got := <-timeouts; got != 120 {
+				t.Errorf("watch TimeoutSeconds = %d, want 120", got)
+			}
+			return w
+		case <-time.After(waitTimeout):
+			t.Fatal("no watch request")
+			return nil
+		}
+	}
+	next().Stop()
+	// The informer must come back with a fresh watch that still delivers.
+	next().Add(makeUnstructured(gvr, nil))
+	if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+		t.Fatalf("expected the event on the re-established watch to be reconciled, got %d endpoints", writer.Len())
+	}
+}
+
+func TestController_WatchErrorPausesWhenBreakerOpens(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{
+		DefaultInterval:  30 * time.Second,
+		BreakerThreshold: 2,
+		BreakerWindow:    time.Minute,
+		BreakerCooldown:  time.Hour,
+	}
+	c := NewController(cfg, fakeResource{gvr: gvr}, gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml")), newFakeClient(gvr))
+	done := make(chan struct{})
+	c.done = done
+
+	failed := errors.New("connection refused")
+	c.watchError(nil, failed) // below the threshold: returns at once
+	paused := make(chan struct{})
+	go func() {
+		c.watchError(nil, failed)
+		close(paused)
+	}()
+	select {
+	case <-paused:
+		t.Fatal("watchError returned while the breaker was open")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(done) // shutdown ends the pause
+	select {
+	case <-paused:
+	case <-time.After(waitTimeout):
+		t.Fatal("watchError still paused after shutdown")
+	}
+
+	// A routine close shows the apiserver answering again.
+	c.watchError(nil, io.EOF)
+	if c.breaker.failure() {
+		t.Error("breaker should have been reset by the closed watch")
+	}
+}
+
+func TestController_ResyncDoesNotCloseBreaker(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{BreakerThreshold: 2, BreakerWindow: time.Minute}
+	c := NewController(cfg, fakeResource{gvr: gvr}, nil, newFakeClient(gvr))
+	isOpen := func() bool {
+		c.breaker.mu.Lock()
+		defer c.breaker.mu.Unlock()
+		return c.breaker.open
+	}
+	c.breaker.failure()
+	if !c.breaker.failure() {
+		t.Fatal("breaker should open at the threshold")
+	}
+
+	// The informer's resync replays the cached object unchanged while the
+	// watch is still down; a parent event isn't this watch either.
+	obj := makeUnstructured(gvr, map[string]string{"parent": "p"})
+	obj.SetResourceVersion("5")
+	if err := c.informer.GetIndexer().Add(obj); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	c.onUpdate(obj, obj)
+	c.requeueChildren(childResource{fakeResource{gvr: gvr}, gvr}, "p")
+	if !isOpen() {
+		t.Fatal("a resync or parent event during the outage closed the breaker")
+	}
+	if c.queue.Len() != 1 {
+		t.Errorf("queue has %d keys, want thing-a still queued", c.queue.Len())
+	}
+
+	changed := obj.DeepCopy()
+	changed.SetResourceVersion("6")
+	c.onUpdate(obj, changed)
+	if isOpen() {
+		t.Error("an update from the apiserver should close the breaker")
+	}
+}
+
+// childResource is a fakeResource inheriting from the cluster-scoped parent
+// named in its "parent" annotation.
+type childResource struct {
+	fakeResource
+	parentGVR schema.GroupVersionResource
+}
+
+func (r childResource) ParentGVR() schema.GroupVersionResource { return r.parentGVR }
+func (childResource) ParentKeys(obj metav1.Object) []string {
+	return []string{obj.GetAnnotations()["parent"]}
+}
+func (r childResource) ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher Fetcher) map[string]string {
+	return fetcher.GetAnnotations(ctx, r.parentGVR, "", obj.GetAnnotations()["parent"])
+}
+
+func TestController_WatchParentsRefreshesChildren(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	parentGVR := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "parents"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr:       "ThingList",
+		parentGVR: "ParentList",
+	})
+	parent := &unstructured.Unstructured{}
+	parent.SetGroupVersionKind(schema.GroupVersionKind{Group: "test.io", Version: "v1", Kind: "Parent"})
+	parent.SetName("p")
+	parent.SetAnnotations(map[string]string{"tpl": "interval: 5m\n"})
+	if _, err := client.Resource(parentGVR).Create(context.Background(), parent, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("seed parent: %v", err)
+	}
+	seed(t, client, gvr, makeUnstructured(gvr, map[string]string{"parent": "p"}))
+
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+		WatchParents:       true,
+		// Long enough that only the parent watch can pick up the change.
+		ParentCacheTTL: time.Hour,
+	}
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+	c := NewController(cfg, childResource{fakeResource{gvr: gvr}, parentGVR}, writer, client)
+
+	ctx := t.Context()
+	go func() { _ = c.Run(ctx) }()
+	// interval polls the output file, which may not exist yet; failing
+	// the test from inside waitFor's condition would end it early.
+	interval := func() any {
+		endpoints, err := decodeEndpoints(outPath)
+		if err != nil || len(endpoints) == 0 {
+			return nil
+		}
+		return endpoints[0]["interval"]
+	}
+	if !waitFor(t, func() bool { return interval() == "5m" }) {
+		t.Fatalf("interval = %v, want the parent's 5m", interval())
+	}
+
+	parent.SetAnnotations(map[string]string{"tpl": "interval: 7m\n"})
+	if _, err := client.Resource(parentGVR).Update(ctx, parent, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update parent: %v", err)
+	}
+	if !waitFor(t, func() bool { return interval() == "7m" }) {
+		t.Errorf("interval = %v, want 7m after the parent changed", interval())
+	}
+}
+
 func TestController_DisabledAnnotationRemovesEndpoint(t *testing.T) {
 	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
 	client := newFakeClient(gvr)
@@ -196,7 +562,7 @@ func TestController_MissingURLRemovesEndpoint(t *testing.T) {
 	if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, nil)); err != nil {
 		t.Fatalf("seed indexer: %v", err)
 	}
-	if err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+	if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
 		t.Fatalf("reconcile: %v", err)
 	}
 	if writer.Len() != 0 {
@@ -204,39 +570,224 @@ func TestController_MissingURLRemovesEndpoint(t *testing.T) {
 	}
 }
 
-func TestMakeEndpointKey(t *testing.T) {
-	got := makeEndpointKey("a", "ns", schema.GroupVersionResource{Resource: "ingresses"})
-	want := "ingresses/ns/a"
-	if got != want {
-		t.Errorf("makeEndpointKey() = %q, want %q", got, want)
+func TestController_ReconcileReportsChanges(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{
+		gvr:       gvr,
+		guardHost: "thing-a.example.com",
+		matchesFn: matchesEnabledAnnotation,
+		// An empty "url" annotation stands in for a resource with no
+		// derivable URL.
+		urlFn: func(obj metav1.Object) string {
+			if u, ok := obj.GetAnnotations()["url"]; ok {
+				return u
+			}
+			return "https://thing-a.example.com"
+		},
+	}, writer, newFakeClient(gvr))
+
+	steps := []struct {
+		name        string
+		annotations map[string]string
+		wantChanged bool
+		wantLen     int
+	}{
+		{"added", nil, true, 1},
+		{"unchanged", nil, false, 1},
+		{"guarded", map[string]string{"tpl": "guarded: true"}, true, 1},
+		{"disabled", map[string]string{"enabled": "false"}, true, 0},
+		{"disabled again", map[string]string{"enabled": "false"}, false, 0},
+		{"re-enabled", nil, true, 1},
+		{"no url", map[string]string{"url": ""}, true, 0},
+		{"still no url", map[string]string{"url": ""}, false, 0},
+	}
+	for _, step := range steps {
+		if err := c.informer.GetIndexer().Update(makeUnstructured(gvr, step.annotations)); err != nil {
+			t.Fatalf("%s: seed indexer: %v", step.name, err)
+		}
+		changed, err := c.reconcile(context.Background(), "default/thing-a", false)
+		if err != nil {
+			t.Fatalf("%s: reconcile: %v", step.name, err)
+		}
+		if changed != step.wantChanged {
+			t.Errorf("%s: changed = %v, want %v", step.name, changed, step.wantChanged)
+		}
+		if writer.Len() != step.wantLen {
+			t.Errorf("%s: %d endpoints, want %d", step.name, writer.Len(), step.wantLen)
+		}
+	}
+
+	if err := c.informer.GetIndexer().Update(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	if _, err := c.reconcile(context.Background(), "default/thing-a", false); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := c.informer.GetIndexer().Delete(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("delete from indexer: %v", err)
+	}
+	changed, err := c.reconcile(context.Background(), "default/thing-a", false)
+	if err != nil || !changed || writer.Len() != 0 {
+		t.Errorf("deleted: changed = %v, err = %v, %d endpoints; want true, nil, 0", changed, err, writer.Len())
+	}
+}
+
+func TestController_MergeConditions(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	r := fakeResource{
+		gvr:        gvr,
+		conditions: []string{"[STATUS] == 200"},
+		parentAnnotsFn: func(context.Context, metav1.Object, Fetcher) map[string]string {
+			return map[string]string{"tpl": "conditions:\n  - \"[STATUS] == 200\"\n  - \"[CERTIFICATE_EXPIRATION] > 48h\"\n"}
+		},
+	}
+	child := map[string]string{"tpl": "conditions:\n  - \"[RESPONSE_TIME] < 500\"\n  - \"[CERTIFICATE_EXPIRATION] > 48h\"\n"}
+
+	cases := []struct {
+		name  string
+		merge bool
+		want  []any
+	}{
+		{"replace by default", false, []any{"[RESPONSE_TIME] < 500", "[CERTIFICATE_EXPIRATION] > 48h"}},
+		{"merge appends and dedupes", true, []any{"[STATUS] == 200", "[CERTIFICATE_EXPIRATION] > 48h", "[RESPONSE_TIME] < 500"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				MergeConditions:    tt.merge,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+			}
+			endpoints := reconcileOne(t, cfg, r, child)
+			if len(endpoints) != 1 {
+				t.Fatalf("expected 1 endpoint, got %d", len(endpoints))
+			}
+			if got := endpoints[0]["conditions"]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("conditions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// multiParentResource is a fakeResource whose objects have several parents.
+type multiParentResource struct {
+	fakeResource
+	parents []map[string]string
+}
+
+func (r multiParentResource) AllParentAnnotations(context.Context, metav1.Object, Fetcher) []map[string]string {
+	return r.parents
+}
+
+func TestController_MultiParentTemplatesMergeFirstWins(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	r := multiParentResource{
+		fakeResource: fakeResource{gvr: gvr},
+		parents: []map[string]string{
+			{"tpl": "interval: 1m\nclient:\n  timeout: 5s\n"},
+			{},
+			{"tpl": "interval: 5m\nclient:\n  timeout: 20s\n  insecure: true\ngroup: edge\n"},
+		},
+	}
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	endpoints := reconcileOne(t, cfg, r, nil)
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(endpoints))
+	}
+	ep := endpoints[0]
+	if ep["interval"] != "1m" || ep["group"] != "edge" {
+		t.Errorf("interval = %v, group = %v; want 1m (first parent) and edge (second)", ep["interval"], ep["group"])
+	}
+	want := map[string]any{"timeout": "5s", "insecure": true}
+	if got := ep["client"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("client = %v, want %v", got, want)
 	}
 }
 
-func TestSetURLPath(t *testing.T) {
+func TestController_ParentDisabled(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	off := map[string]string{"enabled": "false"}
 	cases := []struct {
 		name    string
-		rawURL  string
-		newPath string
-		want    string
+		parents []map[string]string
+		child   map[string]string
+		want    int
 	}{
-		{"replace path", "https://x.example.com/api", "/healthz", "https://x.example.com/healthz"},
-		{"strip path", "https://x.example.com/api", "", "https://x.example.com"},
-		{"add path when none", "https://x.example.com", "/alive", "https://x.example.com/alive"},
-		{"non-rooted gets leading slash", "https://x.example.com/api", "alive", "https://x.example.com/alive"},
-		{"preserves port", "https://x.example.com:8443/api", "/healthz", "https://x.example.com:8443/healthz"},
-		{"preserves query", "https://x.example.com/api?q=1", "/healthz", "https://x.example.com/healthz?q=1"},
-		{"unparseable returns as-is", "not a url", "/healthz", "not a url"},
-		{"scheme-less returns as-is", "x.example.com/api", "/healthz", "x.example.com/api"},
+		{"parent disabled, child inherits", []map[string]string{off}, nil, 0},
+		{"child re-enables", []map[string]string{off}, map[string]string{"enabled": "true"}, 1},
+		{"parent enabled", []map[string]string{{"enabled": "true"}}, nil, 1},
+		{"one of two parents disabled", []map[string]string{off, {}}, nil, 1},
+		{"every parent disabled", []map[string]string{off, off}, nil, 0},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := setURLPath(tt.rawURL, tt.newPath); got != tt.want {
-				t.Errorf("setURLPath(%q, %q) = %q, want %q", tt.rawURL, tt.newPath, got, tt.want)
+			r := multiParentResource{fakeResource: fakeResource{gvr: gvr}, parents: tt.parents}
+			cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+			if got := len(reconcileOne(t, cfg, r, tt.child)); got != tt.want {
+				t.Errorf("got %d endpoints, want %d", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestController_ResyncPrunesOrphans(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := newFakeClient(gvr)
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{gvr: gvr}, writer, client)
+
+	if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	// Drop the object from the cache without a delete event, as a missed
+	// watch event would.
+	if err := c.informer.GetIndexer().Delete(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("delete from indexer: %v", err)
+	}
+	if writer.Len() != 1 {
+		t.Fatalf("expected 1 endpoint before resync, got %d", writer.Len())
+	}
+
+	c.resync()
+	if c.queue.Len() != 1 {
+		t.Fatalf("expected the orphan to be queued, queue len %d", c.queue.Len())
+	}
+	c.initialReconcile(context.Background())
+	if writer.Len() != 0 {
+		t.Errorf("expected orphaned endpoint to be pruned, got %d", writer.Len())
+	}
+}
+
+func TestMakeEndpointKey(t *testing.T) {
+	got := makeEndpointKey("a", "ns", schema.GroupVersionResource{Resource: "ingresses"})
+	want := "ingresses/ns/a"
+	if got != want {
+		t.Errorf("makeEndpointKey() = %q, want %q", got, want)
+	}
+}
+
+// Dots are legal in names and namespaces, so a dot-joined key would merge
+// "a.b" in "c" with "a" in "b.c".
+func TestMakeEndpointKey_DottedNamesDontCollide(t *testing.T) {
+	gvr := schema.GroupVersionResource{Resource: "ingresses"}
+	pairs := [][2]string{{"a.b", "c"}, {"a", "b.c"}, {"my.app", "default"}, {"my", "app.default"}}
+	seen := make(map[string][2]string)
+	for _, p := range pairs {
+		key := makeEndpointKey(p[0], p[1], gvr)
+		if prev, ok := seen[key]; ok {
+			t.Errorf("%s/%s and %s/%s share key %q", prev[1], prev[0], p[1], p[0], key)
+		}
+		seen[key] = p
+	}
+}
+
 func TestController_AppliesPrefixToEndpointName(t *testing.T) {
 	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
 	client := newFakeClient(gvr)
@@ -259,7 +810,7 @@ func TestController_AppliesPrefixToEndpointName(t *testing.T) {
 	ctx := t.Context()
 	go func() { _ = c.Run(ctx) }()
 
-	if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+	if !waitForOutput(t, outPath, 1) {
 		t.Fatalf("expected 1 endpoint")
 	}
 
@@ -305,7 +856,7 @@ func TestController_TemplateInheritanceAndGuarded(t *testing.T) {
 	ctx := t.Context()
 	go func() { _ = c.Run(ctx) }()
 
-	if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+	if !waitForOutput(t, outPath, 1) {
 		t.Fatalf("expected 1 endpoint, got %d", writer.Len())
 	}
 
@@ -367,7 +918,7 @@ func TestController_PathOverrideAndProbePathsFlag(t *testing.T) {
 			ctx := t.Context()
 			go func() { _ = c.Run(ctx) }()
 
-			if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+			if !waitForOutput(t, outPath, 1) {
 				t.Fatalf("expected 1 endpoint, got %d", writer.Len())
 			}
 			data, err := os.ReadFile(outPath)
@@ -394,3 +945,823 @@ func waitFor(t *testing.T, cond func() bool) bool {
 	}
 	return cond()
 }
+
+// waitForOutput waits until the output file lists want endpoints. Polling
+// writer.Len() isn't enough: with several initial reconcile workers it
+// counts endpoints well before Run's first flush writes the file.
+func waitForOutput(t *testing.T, path string, want int) bool {
+	t.Helper()
+	return waitFor(t, func() bool {
+		endpoints, err := decodeEndpoints(path)
+		return err == nil && len(endpoints) == want
+	})
+}
+
+// readEndpoints decodes the writer's output file into generic maps.
+func readEndpoints(t *testing.T, path string) []map[string]any {
+	t.Helper()
+	endpoints, err := decodeEndpoints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoints
+}
+
+// decodeEndpoints is readEndpoints without failing the test, for polling a
+// file that may not have been written yet.
+func decodeEndpoints(path string) ([]map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile: %w", err)
+	}
+	var doc struct {
+		Endpoints []map[string]any `yaml:"endpoints"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return doc.Endpoints, nil
+}
+
+func TestController_TemplateGuardedFalseProbesHTTP(t *testing.T) {
+	cases := []struct {
+		name   string
+		parent string
+		object string
+	}{
+		{"object guarded false", "", "guarded: false\n"},
+		{"object overrides parent guarded true", "guarded: true\n", "guarded: false\n"},
+		{"non-bool guarded", "", "guarded: \"yes\"\n"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			r := fakeResource{
+				gvr:        gvr,
+				conditions: []string{"[STATUS] == 200"},
+				guardHost:  "example.com",
+				parentAnnotsFn: func(context.Context, metav1.Object, Fetcher) map[string]string {
+					return map[string]string{"tpl": tt.parent}
+				},
+			}
+			endpoints := reconcileOne(t, cfg, r, map[string]string{"tpl": tt.object})
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			e := endpoints[0]
+			if e["url"] != "https://example.com" || e["dns"] != nil {
+				t.Errorf("expected a plain HTTP probe, got %v", e)
+			}
+			if conds, _ := e["conditions"].([]any); len(conds) != 1 || conds[0] != "[STATUS] == 200" {
+				t.Errorf("conditions = %v", e["conditions"])
+			}
+		})
+	}
+}
+
+func TestController_InvalidURLSkipsEndpoint(t *testing.T) {
+	cases := []struct {
+		name       string
+		url        string
+		annotation string
+		wantCount  int
+	}{
+		{"wildcard host skipped", "https://*.example.com", "", 0},
+		{"unbracketed ipv6 skipped", "tcp://fd00::1:80", "", 0},
+		{"template url replaces invalid extraction", "https://*.example.com", "url: https://www.example.com\n", 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+			writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+			c := NewController(cfg, fakeResource{
+				gvr:   gvr,
+				urlFn: func(metav1.Object) string { return tt.url },
+			}, writer, newFakeClient(gvr))
+
+			ann := map[string]string{}
+			if tt.annotation != "" {
+				ann["tpl"] = tt.annotation
+			}
+			if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, ann)); err != nil {
+				t.Fatalf("seed indexer: %v", err)
+			}
+			if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+				t.Fatalf("reconcile: %v", err)
+			}
+			if writer.Len() != tt.wantCount {
+				t.Errorf("got %d endpoints, want %d", writer.Len(), tt.wantCount)
+			}
+		})
+	}
+}
+
+// fakeMultiResource adds a Targets fan-out to fakeResource.
+type fakeMultiResource struct {
+	fakeResource
+	targetsFn func(metav1.Object) []Target
+}
+
+func (f fakeMultiResource) Targets(obj metav1.Object, _ *config.Config) []Target {
+	return f.targetsFn(obj)
+}
+
+func TestController_MultiTargetFanOutAndCleanup(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+
+	hosts := []string{"a.example.com", "b.example.com", "c.example.com"}
+	r := fakeMultiResource{
+		fakeResource: fakeResource{gvr: gvr},
+		targetsFn: func(metav1.Object) []Target {
+			out := make([]Target, 0, len(hosts))
+			for _, h := range hosts {
+				out = append(out, Target{Suffix: h, URL: "https://" + h, GuardHost: h})
+			}
+			return out
+		},
+	}
+	c := NewController(cfg, r, writer, newFakeClient(gvr))
+	obj := makeUnstructured(gvr, nil)
+	if err := c.informer.GetIndexer().Add(obj); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+
+	reconcile := func() {
+		t.Helper()
+		if _, err := c.reconcile(context.Background(), "default/thing-a", true); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	names := func() []string {
+		t.Helper()
+		var out []string
+		for _, e := range readEndpoints(t, outPath) {
+			out = append(out, e["name"].(string))
+		}
+		return out
+	}
+
+	reconcile()
+	want := []string{"thing-a-a.example.com", "thing-a-b.example.com", "thing-a-c.example.com"}
+	if got := names(); !slices.Equal(got, want) {
+		t.Fatalf("names = %v, want %v", got, want)
+	}
+
+	// Dropping a host removes only its endpoint.
+	hosts = []string{"a.example.com", "c.example.com"}
+	reconcile()
+	want = []string{"thing-a-a.example.com", "thing-a-c.example.com"}
+	if got := names(); !slices.Equal(got, want) {
+		t.Fatalf("after host removal names = %v, want %v", got, want)
+	}
+
+	// Deleting the object removes every per-target endpoint.
+	if err := c.informer.GetIndexer().Delete(obj); err != nil {
+		t.Fatalf("delete from indexer: %v", err)
+	}
+	reconcile()
+	if writer.Len() != 0 {
+		t.Errorf("expected 0 endpoints after delete, got %d", writer.Len())
+	}
+}
+
+// fakeNamedResource overrides the endpoint base name.
+type fakeNamedResource struct {
+	fakeResource
+	name string
+}
+
+func (f fakeNamedResource) EndpointName(metav1.Object) string { return f.name }
+
+func TestController_NamedResource(t *testing.T) {
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	for _, tt := range []struct{ name, want string }{
+		{"backend", "pre-backend"},
+		{"", "pre-thing-a"}, // empty falls back to metadata.name
+	} {
+		r := fakeNamedResource{fakeResource: fakeResource{gvr: gvr, prefix: "pre-"}, name: tt.name}
+		endpoints := reconcileOne(t, cfg, r, nil)
+		if len(endpoints) != 1 || endpoints[0]["name"] != tt.want {
+			t.Errorf("EndpointName %q: got %v, want name %q", tt.name, endpoints, tt.want)
+		}
+	}
+}
+
+type fakeGatedResource struct {
+	fakeResource
+	ready bool
+}
+
+func (f fakeGatedResource) Ready(context.Context, metav1.Object, *config.Config, Fetcher) bool {
+	return f.ready
+}
+
+func TestController_ReadinessGate(t *testing.T) {
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	for _, tt := range []struct {
+		ready bool
+		want  int
+	}{
+		{true, 1},
+		{false, 0},
+	} {
+		r := fakeGatedResource{fakeResource: fakeResource{gvr: gvr}, ready: tt.ready}
+		if endpoints := reconcileOne(t, cfg, r, nil); len(endpoints) != tt.want {
+			t.Errorf("ready=%v: got %d endpoints, want %d", tt.ready, len(endpoints), tt.want)
+		}
+	}
+}
+
+func TestController_Shard(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	for _, tt := range []struct {
+		name        string
+		shard       string
+		unsharded   bool
+		annotations map[string]string
+		want        int
+	}{
+		{"no shard processes everything", "", false, map[string]string{"shard": "us-east"}, 1},
+		{"matching shard", "eu-west", false, map[string]string{"shard": "eu-west"}, 1},
+		{"other shard", "eu-west", false, map[string]string{"shard": "us-east"}, 0},
+		{"unsharded excluded", "eu-west", false, nil, 0},
+		{"unsharded included", "eu-west", true, nil, 1},
+		{"include-unsharded keeps other shards out", "eu-west", true, map[string]string{"shard": "us-east"}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				ShardAnnotation:    "shard",
+				Shard:              tt.shard,
+				IncludeUnsharded:   tt.unsharded,
+			}
+			if endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, tt.annotations); len(endpoints) != tt.want {
+				t.Errorf("got %d endpoints, want %d", len(endpoints), tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetKey(t *testing.T) {
+	if got := targetKey("ingresses/ns/a", ""); got != "ingresses/ns/a" {
+		t.Errorf("targetKey without suffix = %q", got)
+	}
+	if got := targetKey("ingresses/ns/a", "b.example.com"); got != "ingresses/ns/a/b.example.com" {
+		t.Errorf("targetKey with suffix = %q", got)
+	}
+}
+
+func TestController_InitialReconcileWorkers(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	const objects = 12
+
+	// run reconciles the initial listing with workers goroutines and
+	// returns the most reconciles seen in flight at once and the output.
+	run := func(workers int) (int32, []byte) {
+		client := newFakeClient(gvr)
+		for i := range objects {
+			obj := makeUnstructured(gvr, nil)
+			obj.SetName("thing-" + strconv.Itoa(i))
+			seed(t, client, gvr, obj)
+		}
+		var inFlight, peak atomic.Int32
+		r := fakeResource{gvr: gvr, urlFn: func(obj metav1.Object) string {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			// Stands in for a parent lookup's apiserver round trip.
+			time.Sleep(10 * time.Millisecond)
+			return "https://" + obj.GetName() + ".example.com"
+		}}
+		cfg := &config.Config{
+			DefaultInterval:    30 * time.Second,
+			TemplateAnnotation: "tpl",
+			EnabledAnnotation:  "enabled",
+			Once:               true,
+			ReconcileWorkers:   workers,
+		}
+		outPath := filepath.Join(t.TempDir(), "out.yaml")
+		writer := gatus.NewWriter(outPath)
+		if err := NewController(cfg, r, writer, client).Run(t.Context()); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		if _, err := writer.Flush(); err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+		data, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		return peak.Load(), data
+	}
+
+	serialPeak, serial := run(1)
+	parallelPeak, parallel := run(4)
+	if serialPeak != 1 {
+		t.Errorf("1 worker: %d reconciles in flight at once, want 1", serialPeak)
+	}
+	if parallelPeak < 2 || parallelPeak > 4 {
+		t.Errorf("4 workers: %d reconciles in flight at once, want 2-4", parallelPeak)
+	}
+	if !bytes.Equal(serial, parallel) {
+		t.Errorf("output depends on reconcile order:\n1 worker:\n%s\n4 workers:\n%s", serial, parallel)
+	}
+	if n := strings.Count(string(parallel), "- name:"); n != objects {
+		t.Errorf("got %d endpoints, want %d", n, objects)
+	}
+}
+
+func TestController_OnceReturnsAfterInitialList(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := newFakeClient(gvr)
+	seed(t, client, gvr, makeUnstructured(gvr, nil))
+
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+		Once:               true,
+	}
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+	c := NewController(cfg, fakeResource{gvr: gvr}, writer, client)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run(t.Context()) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatal("Run did not return in --once mode")
+	}
+	if writer.Len() != 1 {
+		t.Errorf("expected 1 endpoint, got %d", writer.Len())
+	}
+	select {
+	case <-c.Synced():
+	default:
+		t.Error("Synced should be closed once the initial listing is reconciled")
+	}
+	// The caller owns the single flush in --once mode.
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("controller should not flush in --once mode, stat err=%v", err)
+	}
+}
+
+func TestController_WatchErrorLevels(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cases := []struct {
+		name     string
+		err      error
+		wantWarn bool
+	}{
+		{"closed", io.EOF, false},
+		{"expired", apierrors.NewResourceExpired("too old resource version"), false},
+		{"gone", apierrors.NewGone("gone"), false},
+		{"forbidden", apierrors.NewForbidden(gvr.GroupResource(), "", errors.New("rbac")), true},
+		{"not found", apierrors.NewNotFound(gvr.GroupResource(), ""), true},
+		{"other", errors.New("connection refused"), true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			c := NewController(&config.Config{}, fakeResource{gvr: gvr}, nil, newFakeClient(gvr))
+			c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+			c.watchError(nil, tt.err)
+			if got := strings.Contains(buf.String(), "level=WARN"); got != tt.wantWarn {
+				t.Errorf("warned = %v, want %v; log: %s", got, tt.wantWarn, buf.String())
+			}
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to log to from informer goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestController_UnwatchableKindSkipsController(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "forbidden",
+			err:  apierrors.NewForbidden(gvr.GroupResource(), "", errors.New(`cannot list resource "things"`)),
+			want: "missing RBAC: need list/watch on things.test.io",
+		},
+		{
+			name: "not found",
+			err:  apierrors.NewNotFound(gvr.GroupResource(), ""),
+			want: "things.test.io is not served by the apiserver; is its CRD installed?",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient(gvr).(*fake.FakeDynamicClient)
+			client.PrependReactor("list", "things", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.err
+			})
+			client.PrependWatchReactor("things", func(k8stesting.Action) (bool, watch.Interface, error) {
+				return true, nil, tt.err
+			})
+
+			var buf syncBuffer
+			c := NewController(&config.Config{DefaultInterval: 30 * time.Second}, fakeResource{gvr: gvr},
+				gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml")), client)
+			c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+			ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+			defer cancel()
+			if err := c.Run(ctx); err != nil {
+				t.Fatalf("Run() = %v, want nil for a skipped controller", err)
+			}
+			if ctx.Err() != nil {
+				t.Fatal("Run only returned at the deadline")
+			}
+			select {
+			case <-c.Synced():
+			default:
+				t.Error("Synced should be closed so the first flush isn't held up")
+			}
+			if n := strings.Count(buf.String(), tt.want); n != 1 {
+				t.Errorf("%q logged %d times, want once; log: %s", tt.want, n, buf.String())
+			}
+		})
+	}
+}
+
+func TestController_UnwatchableNamesRefusedKind(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	var buf bytes.Buffer
+	c := NewController(&config.Config{}, fakeResource{gvr: gvr}, nil, newFakeClient(gvr))
+	c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+	parent := schema.GroupResource{Group: "networking.k8s.io", Resource: "ingressclasses"}
+	c.watchError(nil, apierrors.NewForbidden(parent, "", errors.New("rbac")))
+	c.watchError(nil, apierrors.NewNotFound(parent, ""))
+	if n := strings.Count(buf.String(), "level=WARN"); n != 1 {
+		t.Errorf("warned %d times, want once for the first error; log: %s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), "missing RBAC: need list/watch on ingressclasses.networking.k8s.io") {
+		t.Errorf("warning should name the parent kind; log: %s", buf.String())
+	}
+	if !c.skipped.Load() {
+		t.Error("controller should be marked skipped")
+	}
+}
+
+func TestController_UnwatchableParentDropsParentWatch(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	parentGVR := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "parents"}
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"forbidden", apierrors.NewForbidden(parentGVR.GroupResource(), "", errors.New("rbac")), "missing RBAC: need list/watch on parents.test.io"},
+		{"not found", apierrors.NewNotFound(parentGVR.GroupResource(), ""), "parents.test.io is not served by the apiserver"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+				gvr:       "ThingList",
+				parentGVR: "ParentList",
+			})
+			client.PrependReactor("list", "parents", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.err
+			})
+			client.PrependWatchReactor("parents", func(k8stesting.Action) (bool, watch.Interface, error) {
+				return true, nil, tt.err
+			})
+			seed(t, client, gvr, makeUnstructured(gvr, map[string]string{"parent": "p"}))
+
+			cfg := &config.Config{DefaultInterval: 30 * time.Second, WatchParents: true}
+			outPath := filepath.Join(t.TempDir(), "out.yaml")
+			c := NewController(cfg, childResource{fakeResource{gvr: gvr}, parentGVR}, gatus.NewWriter(outPath), client)
+			var buf syncBuffer
+			c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- c.Run(ctx) }()
+			if !waitForOutput(t, outPath, 1) {
+				t.Fatalf("the child kind should still be generated; log: %s", buf.String())
+			}
+			if c.skipped.Load() || !c.parentsDropped.Load() {
+				t.Errorf("skipped = %v, parentsDropped = %v; want only the parent watch dropped", c.skipped.Load(), c.parentsDropped.Load())
+			}
+			if !strings.Contains(buf.String(), tt.want) || !strings.Contains(buf.String(), "ignoring parent annotations") {
+				t.Errorf("want %q and the fallback logged; log: %s", tt.want, buf.String())
+			}
+			select {
+			case err := <-done:
+				t.Fatalf("Run returned %v, want it to keep running", err)
+			default:
+			}
+		})
+	}
+}
+
+func TestController_RefusedAfterSyncKeepsRunning(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cases := []struct {
+		name string
+		err  error
+	}{
+		{"forbidden", apierrors.NewForbidden(gvr.GroupResource(), "", errors.New("rbac"))},
+		{"not found", apierrors.NewNotFound(gvr.GroupResource(), "")},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeClient(gvr)
+			seed(t, client, gvr, makeUnstructured(gvr, nil))
+			writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+			c := NewController(&config.Config{DefaultInterval: 30 * time.Second}, fakeResource{gvr: gvr}, writer, client)
+			var buf syncBuffer
+			c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() { done <- c.Run(ctx) }()
+			if !waitFor(t, func() bool { return writer.Len() == 1 }) {
+				t.Fatalf("expected 1 endpoint, got %d", writer.Len())
+			}
+
+			// A Role rotated mid-upgrade: passing, so keep watching.
+			c.watchError(nil, tt.err)
+			if c.skipped.Load() {
+				t.Fatal("a refusal after the initial sync should not skip the controller")
+			}
+			if !strings.Contains(buf.String(), "retrying") {
+				t.Errorf("want the hint logged with a retry; log: %s", buf.String())
+			}
+			select {
+			case err := <-done:
+				t.Fatalf("Run returned %v after a refusal, want it to keep running", err)
+			default:
+			}
+			if err := client.Resource(gvr).Namespace("default").Delete(ctx, "thing-a", metav1.DeleteOptions{}); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if !waitFor(t, func() bool { return writer.Len() == 0 }) {
+				t.Errorf("later deletes should still apply, got %d endpoints", writer.Len())
+			}
+		})
+	}
+}
+
+func TestController_SanitizeNames(t *testing.T) {
+	cases := []struct {
+		name     string
+		sanitize bool
+		tpl      string
+		want     string
+	}{
+		{"off keeps the raw name", false, "name: My.App_v2\n", "My.App_v2"},
+		{"dots and underscores", true, "name: My.App_v2\n", "my-app-v2"},
+		{"unicode is kept", true, "name: Café_Ünïcode\n", "café-ünïcode"},
+		{"already clean", true, "", "thing-a"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				SanitizeNames:      tt.sanitize,
+			}
+			gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+			endpoints := reconcileOne(t, cfg, fakeResource{gvr: gvr}, map[string]string{"tpl": tt.tpl})
+			if len(endpoints) != 1 {
+				t.Fatalf("got %d endpoints, want 1", len(endpoints))
+			}
+			if got := endpoints[0]["name"]; got != tt.want {
+				t.Errorf("name = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestController_ReconcileSummary(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+	}
+	r := fakeResource{
+		gvr: gvr,
+		matchesFn: func(obj metav1.Object, cfg *config.Config) bool {
+			_, filtered := obj.GetAnnotations()["filtered"]
+			return !filtered && !cfg.Disabled(obj.GetAnnotations())
+		},
+		urlFn: func(obj metav1.Object) string {
+			if u, ok := obj.GetAnnotations()["url"]; ok {
+				return u
+			}
+			return "https://" + obj.GetName() + ".example.com"
+		},
+	}
+	var buf bytes.Buffer
+	c := NewController(cfg, r, gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml")), newFakeClient(gvr))
+	c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+	objects := map[string]map[string]string{
+		"ok-1":     nil,
+		"ok-2":     nil,
+		"disabled": {"enabled": "false"},
+		"filtered": {"filtered": ""},
+		"no-url":   {"url": ""},
+		"bad-url":  {"url": "https://*.example.com"},
+	}
+	for name, annotations := range objects {
+		u := makeUnstructured(gvr, annotations)
+		u.SetName(name)
+		if err := c.informer.GetIndexer().Add(u); err != nil {
+			t.Fatalf("seed indexer: %v", err)
+		}
+		if _, err := c.reconcile(context.Background(), "default/"+name, false); err != nil {
+			t.Fatalf("reconcile %s: %v", name, err)
+		}
+	}
+
+	c.logSummary()
+	want := "processed=2 skipped_no_url=1 skipped_disabled=1 skipped_filtered=1 skipped_invalid_url=1"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("summary log = %q, want it to contain %q", buf.String(), want)
+	}
+	buf.Reset()
+	c.logSummary()
+	if buf.Len() != 0 {
+		t.Errorf("an empty window should log nothing, got %q", buf.String())
+	}
+}
+
+func TestController_BadTemplate(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	bad := map[string]string{"tpl": "interval: [1m\n"}
+	badParent := fakeResource{
+		gvr: gvr,
+		parentAnnotsFn: func(context.Context, metav1.Object, Fetcher) map[string]string {
+			return map[string]string{"tpl": "group: [edge\n"}
+		},
+	}
+	cases := []struct {
+		name        string
+		r           Resource
+		annotations map[string]string
+		ignore      bool
+		want        int
+		interval    any
+	}{
+		{"object template skips", fakeResource{gvr: gvr}, bad, false, 0, nil},
+		{"object template ignored", fakeResource{gvr: gvr}, bad, true, 1, "30s"},
+		{"parent template skips", badParent, map[string]string{"tpl": "interval: 5m\n"}, false, 0, nil},
+		{"parent template ignored", badParent, map[string]string{"tpl": "interval: 5m\n"}, true, 1, "5m"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				IgnoreBadTemplates: tt.ignore,
+			}
+			endpoints := reconcileOne(t, cfg, tt.r, tt.annotations)
+			if len(endpoints) != tt.want {
+				t.Fatalf("got %d endpoints, want %d", len(endpoints), tt.want)
+			}
+			if tt.want == 1 && endpoints[0]["interval"] != tt.interval {
+				t.Errorf("interval = %v, want %v", endpoints[0]["interval"], tt.interval)
+			}
+		})
+	}
+}
+
+func TestController_BadTemplateWarnsOnce(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, TemplateAnnotation: "tpl", EnabledAnnotation: "enabled"}
+	c := NewController(cfg, fakeResource{gvr: gvr}, gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml")), newFakeClient(gvr))
+	var buf bytes.Buffer
+	c.log = slog.New(slog.NewTextHandler(&buf, nil))
+
+	set := func(tpl string) {
+		t.Helper()
+		if err := c.informer.GetIndexer().Update(makeUnstructured(gvr, map[string]string{"tpl": tpl})); err != nil {
+			t.Fatalf("update indexer: %v", err)
+		}
+		if _, err := c.reconcile(context.Background(), "default/thing-a", false); err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+	}
+	long := "interval: [1m\n# " + strings.Repeat("x", 200)
+	set(long)
+	set(long)
+	if got := strings.Count(buf.String(), "invalid template annotation"); got != 1 {
+		t.Errorf("warned %d times for a repeated bad template, want 1:\n%s", got, buf.String())
+	}
+	if strings.Contains(buf.String(), strings.Repeat("x", 200)) {
+		t.Error("the snippet should be truncated")
+	}
+	set("interval: 5m\n")
+	set(long)
+	if got := strings.Count(buf.String(), "invalid template annotation"); got != 2 {
+		t.Errorf("a template broken again after a fix should warn again, warned %d times", got)
+	}
+	if c.stats.badTemplate != 3 {
+		t.Errorf("badTemplate = %d, want 3", c.stats.badTemplate)
+	}
+}
+
+func TestController_HostnameRewrite(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	var rewrites config.HostRewrites
+	for _, rule := range []string{"www.example.com=example.com", `(.+)\.example\.org=$1.lan`} {
+		if err := rewrites.Set(rule); err != nil {
+			t.Fatalf("Set(%q): %v", rule, err)
+		}
+	}
+	cases := []struct{ url, want string }{
+		{"https://www.example.com/healthz", "https://example.com/healthz"},
+		{"https://api.example.org:8443", "https://api.lan:8443"},
+		{"https://other.example.net", "https://other.example.net"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.url, func(t *testing.T) {
+			cfg := &config.Config{
+				DefaultInterval:    30 * time.Second,
+				TemplateAnnotation: "tpl",
+				EnabledAnnotation:  "enabled",
+				ProbePaths:         true,
+				HostRewrites:       rewrites,
+			}
+			r := fakeResource{gvr: gvr, urlFn: func(metav1.Object) string { return tt.url }}
+			endpoints := reconcileOne(t, cfg, r, nil)
+			if len(endpoints) != 1 || endpoints[0]["url"] != tt.want {
+				t.Errorf("endpoints = %v, want url %s", endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlushAfterSync_StuckKindDoesNotBlockWrite(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := newFakeClient(gvr)
+	seed(t, client, gvr, makeUnstructured(gvr, nil))
+	cfg := &config.Config{DefaultInterval: 30 * time.Second}
+	outPath := filepath.Join(t.TempDir(), "out.yaml")
+	writer := gatus.NewWriter(outPath)
+	writer.Hold()
+
+	listed := NewController(cfg, fakeResource{gvr: gvr}, writer, client)
+	// Never run, so its listing never completes.
+	stuck := NewController(cfg, fakeResource{gvr: schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "stuck"}}, writer, client)
+	ctx := t.Context()
+	go func() { _ = listed.Run(ctx) }()
+
+	done := make(chan struct{})
+	go func() {
+		FlushAfterSync(ctx, writer, []*Controller{stuck, listed}, 200*time.Millisecond)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(waitTimeout):
+		t.Fatal("FlushAfterSync still waiting on a kind that never syncs")
+	}
+	if !waitForOutput(t, outPath, 1) {
+		t.Error("the listed kind should be written once the timeout expires")
+	}
+}
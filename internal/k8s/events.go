@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+// ControllerOption configures optional [Controller] behaviour.
+type ControllerOption func(*Controller)
+
+// WithEventRecorder records a Kubernetes Event on a watched object whenever
+// the sidecar's outcome for it changes (--emit-events), so
+// `kubectl describe` shows why it has or lacks an endpoint.
+func WithEventRecorder(recorder record.EventRecorder) ControllerOption {
+	return func(c *Controller) { c.recorder = recorder }
+}
+
+// Event reasons, one per outcome family.
+const (
+	reasonGenerated = "EndpointGenerated"
+	reasonSkipped   = "EndpointSkipped"
+)
+
+// outcome is what reconcile last reported for an object as an Event.
+type outcome struct {
+	eventType, reason, message string
+}
+
+var (
+	outcomeDisabled       = outcome{corev1.EventTypeNormal, reasonSkipped, "gatus-sidecar: skipped: disabled by annotation"}
+	outcomeParentDisabled = outcome{corev1.EventTypeNormal, reasonSkipped, "gatus-sidecar: skipped: disabled by parent annotation"}
+	outcomeFiltered       = outcome{corev1.EventTypeNormal, reasonSkipped, "gatus-sidecar: skipped: no longer matched by filters"}
+	outcomeNotReady       = outcome{corev1.EventTypeNormal, reasonSkipped, "gatus-sidecar: skipped: not ready"}
+	outcomeNoURL          = outcome{corev1.EventTypeWarning, reasonSkipped, "gatus-sidecar: skipped: no hostname or address"}
+	outcomeBadTemplate    = outcome{corev1.EventTypeWarning, reasonSkipped, "gatus-sidecar: skipped: template annotation doesn't parse"}
+	outcomeInvalid        = outcome{corev1.EventTypeWarning, reasonSkipped, "gatus-sidecar: skipped: no valid target"}
+)
+
+// outcomeGenerated reports n endpoints written for the object.
+func outcomeGenerated(n int) outcome {
+	msg := "gatus-sidecar: endpoint created"
+	if n > 1 {
+		msg = fmt.Sprintf("gatus-sidecar: %d endpoints created", n)
+	}
+	return outcome{corev1.EventTypeNormal, reasonGenerated, msg}
+}
+
+// report records o as an Event on u unless it repeats the object's last
+// outcome, keeping every resync from adding another. Objects the sidecar
+// was never told to monitor get no "filtered" Event, or every unannotated
+// resource in the cluster would. The initial listing only notes outcomes:
+// they are remembered in memory alone, so emitting them would repeat an
+// Event on every watched object at each restart.
+func (c *Controller) report(u *unstructured.Unstructured, key string, o outcome) {
+	if c.recorder == nil {
+		return
+	}
+	c.mu.Lock()
+	last, seen := c.outcomes[key]
+	if o == outcomeFiltered && !seen {
+		c.mu.Unlock()
+		return
+	}
+	c.outcomes[key] = o
+	c.mu.Unlock()
+	if seen && last == o {
+		return
+	}
+	select {
+	case <-c.synced:
+	default:
+		return
+	}
+	c.recorder.Event(u, o.eventType, o.reason, o.message)
+}
+
+// forgetOutcome drops a deleted object's last outcome and annotation
+// warnings.
+func (c *Controller) forgetOutcome(key string) {
+	c.mu.Lock()
+	delete(c.outcomes, key)
+	delete(c.badTemplates, key)
+	delete(c.badAnnotations, key)
+	c.mu.Unlock()
+}
@@ -0,0 +1,135 @@
+package k8s
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestController_EmitsEventsOnTransitions(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	cfg := &config.Config{
+		DefaultInterval:    30 * time.Second,
+		TemplateAnnotation: "tpl",
+		EnabledAnnotation:  "enabled",
+	}
+	recorder := record.NewFakeRecorder(16)
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{
+		gvr: gvr,
+		matchesFn: func(obj metav1.Object, _ *config.Config) bool {
+			_, filtered := obj.GetAnnotations()["filtered"]
+			return !filtered && matchesEnabledAnnotation(obj, cfg)
+		},
+		urlFn: func(obj metav1.Object) string {
+			if u, ok := obj.GetAnnotations()["url"]; ok {
+				return u
+			}
+			return "https://thing-a.example.com"
+		},
+	}, writer, newFakeClient(gvr), WithEventRecorder(recorder))
+	close(c.synced) // past the initial listing
+
+	steps := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+	}{
+		{"never matched", map[string]string{"filtered": ""}, nil},
+		{"added", nil, []string{"Normal EndpointGenerated gatus-sidecar: endpoint created"}},
+		{"unchanged", nil, nil},
+		{"no url", map[string]string{"url": ""}, []string{"Warning EndpointSkipped gatus-sidecar: skipped: no hostname or address"}},
+		{"still no url", map[string]string{"url": ""}, nil},
+		{"invalid url", map[string]string{"url": "https://*.example.com"}, []string{"Warning EndpointSkipped gatus-sidecar: skipped: no valid target"}},
+		{"disabled", map[string]string{"enabled": "false"}, []string{"Normal EndpointSkipped gatus-sidecar: skipped: disabled by annotation"}},
+		{"re-enabled", nil, []string{"Normal EndpointGenerated gatus-sidecar: endpoint created"}},
+		{"filtered after matching", map[string]string{"filtered": ""}, []string{"Normal EndpointSkipped gatus-sidecar: skipped: no longer matched by filters"}},
+	}
+	for _, step := range steps {
+		if err := c.informer.GetIndexer().Update(makeUnstructured(gvr, step.annotations)); err != nil {
+			t.Fatalf("%s: seed indexer: %v", step.name, err)
+		}
+		if _, err := c.reconcile(context.Background(), "default/thing-a", false); err != nil {
+			t.Fatalf("%s: reconcile: %v", step.name, err)
+		}
+		if got := drainEvents(recorder); !slices.Equal(got, step.want) {
+			t.Errorf("%s: events = %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	// A deleted and re-created object starts over.
+	if err := c.informer.GetIndexer().Delete(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("delete from indexer: %v", err)
+	}
+	if _, err := c.reconcile(context.Background(), "default/thing-a", false); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if err := c.informer.GetIndexer().Add(makeUnstructured(gvr, nil)); err != nil {
+		t.Fatalf("seed indexer: %v", err)
+	}
+	if _, err := c.reconcile(context.Background(), "default/thing-a", false); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if got := drainEvents(recorder); len(got) != 1 {
+		t.Errorf("re-created: events = %q, want one", got)
+	}
+}
+
+func TestController_NoEventsForInitialListing(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "test.io", Version: "v1", Resource: "things"}
+	client := newFakeClient(gvr)
+	seed(t, client, gvr, makeUnstructured(gvr, nil))
+	cfg := &config.Config{DefaultInterval: 30 * time.Second, EnabledAnnotation: "enabled"}
+	recorder := record.NewFakeRecorder(16)
+	writer := gatus.NewWriter(filepath.Join(t.TempDir(), "out.yaml"))
+	c := NewController(cfg, fakeResource{
+		gvr:       gvr,
+		matchesFn: func(obj metav1.Object, _ *config.Config) bool { return matchesEnabledAnnotation(obj, cfg) },
+	}, writer, client, WithEventRecorder(recorder))
+
+	ctx := t.Context()
+	go func() { _ = c.Run(ctx) }()
+	select {
+	case <-c.Synced():
+	case <-time.After(waitTimeout):
+		t.Fatal("controller never synced")
+	}
+	if got := drainEvents(recorder); len(got) != 0 {
+		t.Errorf("initial listing emitted %q, want nothing, as after every restart", got)
+	}
+
+	// A change after startup is a transition worth an Event.
+	if _, err := client.Resource(gvr).Namespace("default").Update(ctx, makeUnstructured(gvr, map[string]string{"enabled": "false"}), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	var got []string
+	waitFor(t, func() bool {
+		got = append(got, drainEvents(recorder)...)
+		return len(got) > 0
+	})
+	if want := []string{"Normal EndpointSkipped gatus-sidecar: skipped: disabled by annotation"}; !slices.Equal(got, want) {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+}
+
+// drainEvents returns the events recorded so far.
+func drainEvents(r *record.FakeRecorder) []string {
+	var out []string
+	for {
+		select {
+		case e := <-r.Events:
+			out = append(out, e)
+		default:
+			return out
+		}
+	}
+}
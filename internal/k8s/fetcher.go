@@ -3,48 +3,103 @@ package k8s
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"sync"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
 )
 
-// Fetcher resolves another object's annotations on demand. Each Resource
-// implementation receives one to read its parent (Gateway, IngressClass, ...)
-// without a live apiserver hit per reconcile.
+// Fetcher resolves another object on demand. Each Resource implementation
+// receives one to read its parent (Gateway, IngressClass, ...) or a
+// referenced backend without a live apiserver hit per reconcile.
 type Fetcher interface {
 	GetAnnotations(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) map[string]string
+
+	// Get returns the whole object, or nil when it doesn't exist or can't
+	// be read. Callers must not modify it.
+	Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) *unstructured.Unstructured
+
+	// List returns the objects in namespace matching selector, such as the
+	// EndpointSlices of a Service. Unlike Get it reports failures, so a
+	// caller can tell "none" from "couldn't look". Callers must not modify
+	// the objects.
+	List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error)
 }
 
-const defaultFetcherTTL = 30 * time.Second
+// failedLookupTTL caps how long the outcome of a Get that failed
+// transiently is cached, so the next lookup soon after tries again.
+const failedLookupTTL = 5 * time.Second
+
+// getRetryBackoff is the pause before the first retry of a failed Get; it
+// doubles for each one after.
+const getRetryBackoff = 200 * time.Millisecond
 
-// NewFetcher returns a Fetcher safe for concurrent use that caches
-// annotation lookups (including not-found) for ~30s.
-func NewFetcher(client dynamic.Interface) Fetcher {
+// newCachedFetcher returns a Fetcher safe for concurrent use that caches
+// lookups (including not-found) for ttl, --parent-cache-ttl. A zero ttl
+// disables caching: every lookup is a GET.
+func newCachedFetcher(client dynamic.Interface, ttl, getTimeout time.Duration, getRetries int) *cachedFetcher {
 	return &cachedFetcher{
-		client: client,
-		ttl:    defaultFetcherTTL,
-		cache:  make(map[string]fetcherEntry),
+		client:     client,
+		ttl:        ttl,
+		getTimeout: getTimeout,
+		getRetries: getRetries,
+		backoff:    getRetryBackoff,
+		cache:      make(map[string]fetcherEntry),
 	}
 }
 
 type fetcherEntry struct {
-	annotations map[string]string
-	expires     time.Time
+	obj *unstructured.Unstructured
+	// list holds the result of a List; obj is unused then.
+	list    []*unstructured.Unstructured
+	expires time.Time
 }
 
 type cachedFetcher struct {
 	client dynamic.Interface
 	ttl    time.Duration
+	// getTimeout bounds each Get attempt (zero leaves it to ctx), and
+	// getRetries is how many more attempts a transient failure gets.
+	getTimeout time.Duration
+	getRetries int
+	backoff    time.Duration
 
 	mu    sync.RWMutex
 	cache map[string]fetcherEntry
+	// nextSweep is when storeLocked next evicts stale entries.
+	nextSweep time.Time
+}
+
+// storeLocked caches entry under key. At most once per ttl it also evicts
+// entries expired for over a ttl, such as those of deleted Gateways or
+// Services: an entry only just expired is kept, since Get serves its copy
+// when the refresh fails.
+func (f *cachedFetcher) storeLocked(key string, entry fetcherEntry, now time.Time) {
+	f.cache[key] = entry
+	if now.Before(f.nextSweep) {
+		return
+	}
+	f.nextSweep = now.Add(f.ttl)
+	maps.DeleteFunc(f.cache, func(_ string, e fetcherEntry) bool {
+		return now.Sub(e.expires) > f.ttl
+	})
 }
 
 func (f *cachedFetcher) GetAnnotations(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) map[string]string {
+	if obj := f.Get(ctx, gvr, namespace, name); obj != nil {
+		return obj.GetAnnotations()
+	}
+	return nil
+}
+
+func (f *cachedFetcher) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) *unstructured.Unstructured {
 	key := gvr.String() + "/" + namespace + "/" + name
 	now := time.Now()
 
@@ -52,29 +107,172 @@ func (f *cachedFetcher) GetAnnotations(ctx context.Context, gvr schema.GroupVers
 	entry, ok := f.cache[key]
 	f.mu.RUnlock()
 	if ok && now.Before(entry.expires) {
-		return entry.annotations
+		return entry.obj
+	}
+
+	obj, err := f.get(ctx, gvr, namespace, name)
+	ttl := f.ttl
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		// Cache the absence so a missing object doesn't probe per reconcile.
+		obj = nil
+	case ctx.Err() != nil:
+		// Shutting down; nothing worth caching or reporting.
+		return nil
+	case retryable(err) && ok && entry.obj != nil:
+		// Keep the last copy that was read rather than flap every child's
+		// template and enabled state, and look again soon.
+		slog.Warn("fetch object failed; serving the last copy read",
+			"gvr", gvr.String(), "namespace", namespace, "name", name, "error", err)
+		obj = entry.obj
+		ttl = min(ttl, failedLookupTTL)
+	default:
+		// Still cached, so a struggling apiserver isn't asked again by
+		// every child until the entry expires.
+		slog.Warn("fetch object failed; its annotations are ignored until the next lookup",
+			"gvr", gvr.String(), "namespace", namespace, "name", name, "error", err)
+		obj = nil
+		if retryable(err) {
+			ttl = min(ttl, failedLookupTTL)
+		}
 	}
 
+	if ttl <= 0 {
+		return obj
+	}
+	f.mu.Lock()
+	f.storeLocked(key, fetcherEntry{obj: obj, expires: now.Add(ttl)}, now)
+	f.mu.Unlock()
+	return obj
+}
+
+// get reads one object from the apiserver, retrying up to getRetries times
+// with backoff when the error may be transient.
+func (f *cachedFetcher) get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
 	res := f.client.Resource(gvr)
 	var iface dynamic.ResourceInterface = res
 	if namespace != "" {
 		iface = res.Namespace(namespace)
 	}
 
-	var ann map[string]string
-	obj, err := iface.Get(ctx, name, metav1.GetOptions{})
-	switch {
-	case err == nil:
-		ann = obj.GetAnnotations()
-	case apierrors.IsNotFound(err):
-		// Cache the absence so a missing parent doesn't probe per reconcile.
-	default:
-		slog.Debug("fetch parent annotations",
-			"gvr", gvr.String(), "namespace", namespace, "name", name, "error", err)
+	backoff := f.backoff
+	for attempt := 0; ; attempt++ {
+		obj, err := f.getOnce(ctx, iface, name)
+		if err == nil || !retryable(err) || attempt >= f.getRetries {
+			return obj, err
+		}
+		slog.Debug("fetch object failed, retrying",
+			"gvr", gvr.String(), "namespace", namespace, "name", name, "attempt", attempt+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// getOnce is a single Get, bounded by getTimeout when set.
+func (f *cachedFetcher) getOnce(ctx context.Context, iface dynamic.ResourceInterface, name string) (*unstructured.Unstructured, error) {
+	if f.getTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.getTimeout)
+		defer cancel()
 	}
+	return iface.Get(ctx, name, metav1.GetOptions{})
+}
+
+// retryable reports whether a failed Get might succeed when repeated: not
+// when the object is missing or the sidecar isn't allowed to read it.
+func retryable(err error) bool {
+	return !apierrors.IsNotFound(err) && !apierrors.IsForbidden(err) && !apierrors.IsUnauthorized(err)
+}
 
+func (f *cachedFetcher) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	key := gvr.String() + "/" + namespace + "?" + selector.String()
+	now := time.Now()
+
+	f.mu.RLock()
+	entry, ok := f.cache[key]
+	f.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		return entry.list, nil
+	}
+
+	res := f.client.Resource(gvr)
+	var iface dynamic.ResourceInterface = res
+	if namespace != "" {
+		iface = res.Namespace(namespace)
+	}
+	list, err := iface.List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		// Not cached: the next reconcile should try again.
+		return nil, err
+	}
+	items := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+
+	if f.ttl <= 0 {
+		return items, nil
+	}
 	f.mu.Lock()
-	f.cache[key] = fetcherEntry{annotations: ann, expires: now.Add(f.ttl)}
+	f.storeLocked(key, fetcherEntry{list: items, expires: now.Add(f.ttl)}, now)
 	f.mu.Unlock()
-	return ann
+	return items, nil
+}
+
+// storeFetcher serves one GVR's objects from an informer store, which is
+// always current, and defers every other lookup to next, as well as its
+// own while bypass, when set, reports true.
+type storeFetcher struct {
+	gvr    schema.GroupVersionResource
+	store  cache.Store
+	next   Fetcher
+	bypass func() bool
+}
+
+// serves reports whether lookups of gvr are answered from the store.
+func (f *storeFetcher) serves(gvr schema.GroupVersionResource) bool {
+	return gvr == f.gvr && (f.bypass == nil || !f.bypass())
+}
+
+func (f *storeFetcher) GetAnnotations(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) map[string]string {
+	if obj := f.Get(ctx, gvr, namespace, name); obj != nil {
+		return obj.GetAnnotations()
+	}
+	return nil
+}
+
+func (f *storeFetcher) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) *unstructured.Unstructured {
+	if !f.serves(gvr) {
+		return f.next.Get(ctx, gvr, namespace, name)
+	}
+	key := name
+	if namespace != "" {
+		key = namespace + "/" + name
+	}
+	item, ok, err := f.store.GetByKey(key)
+	if err != nil || !ok {
+		return nil
+	}
+	obj, _ := item.(*unstructured.Unstructured)
+	return obj
+}
+
+func (f *storeFetcher) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	if !f.serves(gvr) {
+		return f.next.List(ctx, gvr, namespace, selector)
+	}
+	var out []*unstructured.Unstructured
+	for _, item := range f.store.List() {
+		obj, ok := item.(*unstructured.Unstructured)
+		if !ok || (namespace != "" && obj.GetNamespace() != namespace) || !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		out = append(out, obj)
+	}
+	return out, nil
 }
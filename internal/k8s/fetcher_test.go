@@ -2,9 +2,17 @@ package k8s
 
 import (
 	"context"
+	"errors"
+	"maps"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/home-operations/gatus-sidecar/internal/config"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
@@ -30,7 +38,7 @@ func TestFetcher_CachesAcrossCalls(t *testing.T) {
 		return false, nil, nil
 	})
 
-	f := NewFetcher(client)
+	f := newCachedFetcher(client, config.DefaultParentCacheTTL, config.DefaultParentGetTimeout, config.DefaultParentGetRetries)
 	for range 3 {
 		ann := f.GetAnnotations(context.Background(), gvr, "ns", "cfg")
 		if ann["k"] != "v" {
@@ -42,6 +50,39 @@ func TestFetcher_CachesAcrossCalls(t *testing.T) {
 	}
 }
 
+func TestFetcher_GetSharesCache(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMap"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMapList"), &unstructured.UnstructuredList{})
+
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(gvr.GroupVersion().WithKind("ConfigMap"))
+	cm.SetName("cfg")
+	cm.SetNamespace("ns")
+	cm.SetAnnotations(map[string]string{"k": "v"})
+	_ = unstructured.SetNestedField(cm.Object, "value", "data", "key")
+	client := fake.NewSimpleDynamicClient(scheme, cm)
+
+	var gets int
+	client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+
+	f := newCachedFetcher(client, config.DefaultParentCacheTTL, config.DefaultParentGetTimeout, config.DefaultParentGetRetries)
+	if ann := f.GetAnnotations(context.Background(), gvr, "ns", "cfg"); ann["k"] != "v" {
+		t.Fatalf("annotations = %v, want {k:v}", ann)
+	}
+	obj := f.Get(context.Background(), gvr, "ns", "cfg")
+	if v, _, _ := unstructured.NestedString(obj.Object, "data", "key"); v != "value" {
+		t.Errorf("Get() data.key = %q, want value", v)
+	}
+	if gets != 1 {
+		t.Errorf("apiserver Gets = %d, want 1 (annotations and object share the cache)", gets)
+	}
+}
+
 func TestFetcher_CachesNegativeLookups(t *testing.T) {
 	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
 	scheme := runtime.NewScheme()
@@ -55,7 +96,7 @@ func TestFetcher_CachesNegativeLookups(t *testing.T) {
 		return false, nil, nil
 	})
 
-	f := NewFetcher(client)
+	f := newCachedFetcher(client, config.DefaultParentCacheTTL, config.DefaultParentGetTimeout, config.DefaultParentGetRetries)
 	for range 3 {
 		if ann := f.GetAnnotations(context.Background(), gvr, "ns", "missing"); ann != nil {
 			t.Fatalf("annotations = %v, want nil", ann)
@@ -65,3 +106,239 @@ func TestFetcher_CachesNegativeLookups(t *testing.T) {
 		t.Errorf("apiserver Gets for missing object = %d, want 1 (negative cached)", gets)
 	}
 }
+
+func TestFetcher_TTL(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMap"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMapList"), &unstructured.UnstructuredList{})
+	client := fake.NewSimpleDynamicClient(scheme)
+
+	var gets int
+	client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+
+	cases := []struct {
+		name     string
+		ttl      time.Duration
+		wantGets int
+	}{
+		{"within ttl hits the cache", time.Hour, 1},
+		{"zero ttl disables the cache", 0, 2},
+		{"expired entries are refetched", time.Nanosecond, 2},
+	}
+	for _, tt := range cases {
+		gets = 0
+		f := newCachedFetcher(client, tt.ttl, config.DefaultParentGetTimeout, config.DefaultParentGetRetries)
+		for range 2 {
+			f.GetAnnotations(context.Background(), gvr, "ns", "cfg")
+			time.Sleep(time.Millisecond)
+		}
+		if gets != tt.wantGets {
+			t.Errorf("%s: apiserver Gets = %d, want %d", tt.name, gets, tt.wantGets)
+		}
+	}
+}
+
+func TestFetcher_List(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMap"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMapList"), &unstructured.UnstructuredList{})
+
+	var objs []runtime.Object
+	for _, c := range []struct{ name, namespace, app string }{
+		{"a", "ns", "web"},
+		{"b", "ns", "web"},
+		{"c", "ns", "db"},
+		{"d", "other", "web"},
+	} {
+		cm := &unstructured.Unstructured{}
+		cm.SetGroupVersionKind(gvr.GroupVersion().WithKind("ConfigMap"))
+		cm.SetName(c.name)
+		cm.SetNamespace(c.namespace)
+		cm.SetLabels(map[string]string{"app": c.app})
+		objs = append(objs, cm)
+	}
+	client := fake.NewSimpleDynamicClient(scheme, objs...)
+
+	var lists int
+	client.PrependReactor("list", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		lists++
+		return false, nil, nil
+	})
+
+	f := newCachedFetcher(client, config.DefaultParentCacheTTL, config.DefaultParentGetTimeout, config.DefaultParentGetRetries)
+	selector := labels.SelectorFromSet(labels.Set{"app": "web"})
+	for range 2 {
+		items, err := f.List(context.Background(), gvr, "ns", selector)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		var names []string
+		for _, item := range items {
+			names = append(names, item.GetName())
+		}
+		if !slices.Equal(names, []string{"a", "b"}) {
+			t.Errorf("List() = %v, want [a b]", names)
+		}
+	}
+	if lists != 1 {
+		t.Errorf("apiserver Lists = %d, want 1 (cached)", lists)
+	}
+
+	client.PrependReactor("list", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("boom")
+	})
+	if _, err := newCachedFetcher(client, config.DefaultParentCacheTTL, config.DefaultParentGetTimeout, config.DefaultParentGetRetries).List(context.Background(), gvr, "ns", selector); err == nil {
+		t.Error("List() error = nil, want the apiserver's")
+	}
+}
+
+func TestFetcher_RetriesTransientGet(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMap"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMapList"), &unstructured.UnstructuredList{})
+
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(gvr.GroupVersion().WithKind("ConfigMap"))
+	cm.SetName("cfg")
+	cm.SetNamespace("ns")
+	cm.SetAnnotations(map[string]string{"k": "v"})
+
+	transient := apierrors.NewServerTimeout(gvr.GroupResource(), "get", 1)
+	forbidden := apierrors.NewForbidden(gvr.GroupResource(), "cfg", errors.New("rbac"))
+	cases := []struct {
+		name     string
+		failures int
+		err      error
+		want     map[string]string
+		wantGets int
+	}{
+		{"fails once then succeeds", 1, transient, map[string]string{"k": "v"}, 2},
+		{"succeeds on the last retry", 2, transient, map[string]string{"k": "v"}, 3},
+		{"persistent failure gives up", 5, transient, nil, 3},
+		{"forbidden isn't retried", 5, forbidden, nil, 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(scheme, cm.DeepCopy())
+			var gets int
+			client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+				gets++
+				if gets <= tt.failures {
+					return true, nil, tt.err
+				}
+				return false, nil, nil
+			})
+
+			f := newCachedFetcher(client, time.Hour, time.Second, 2)
+			f.backoff = time.Millisecond
+			if got := f.GetAnnotations(context.Background(), gvr, "ns", "cfg"); !maps.Equal(got, tt.want) {
+				t.Errorf("annotations = %v, want %v", got, tt.want)
+			}
+			if gets != tt.wantGets {
+				t.Errorf("apiserver Gets = %d, want %d", gets, tt.wantGets)
+			}
+			// The outcome, failure included, is cached like any other.
+			f.GetAnnotations(context.Background(), gvr, "ns", "cfg")
+			if gets != tt.wantGets {
+				t.Errorf("apiserver Gets after a second lookup = %d, want %d", gets, tt.wantGets)
+			}
+		})
+	}
+}
+
+func TestFetcher_RetryStopsWithContext(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	var gets int
+	client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		return true, nil, apierrors.NewServerTimeout(gvr.GroupResource(), "get", 1)
+	})
+
+	f := newCachedFetcher(client, time.Hour, time.Second, 5)
+	f.backoff = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if obj := f.Get(ctx, gvr, "ns", "cfg"); obj != nil {
+		t.Errorf("Get() = %v, want nil", obj)
+	}
+	if gets != 1 {
+		t.Errorf("apiserver Gets = %d, want 1 (no retry after cancel)", gets)
+	}
+	// A lookup cut short by shutdown isn't cached.
+	f.backoff = time.Millisecond
+	f.Get(context.Background(), gvr, "ns", "cfg")
+	if gets != 7 {
+		t.Errorf("apiserver Gets after cancel = %d, want 7 (refetched)", gets)
+	}
+}
+
+func TestFetcher_KeepsLastCopyOnFailedRefresh(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMap"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gvr.GroupVersion().WithKind("ConfigMapList"), &unstructured.UnstructuredList{})
+
+	cm := &unstructured.Unstructured{}
+	cm.SetGroupVersionKind(gvr.GroupVersion().WithKind("ConfigMap"))
+	cm.SetName("cfg")
+	cm.SetNamespace("ns")
+	cm.SetAnnotations(map[string]string{"k": "v"})
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want map[string]string
+	}{
+		{"transient failure keeps the last copy", apierrors.NewServerTimeout(gvr.GroupResource(), "get", 1), map[string]string{"k": "v"}},
+		{"forbidden drops it", apierrors.NewForbidden(gvr.GroupResource(), "cfg", errors.New("rbac")), nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleDynamicClient(scheme, cm.DeepCopy())
+			f := newCachedFetcher(client, time.Millisecond, time.Second, 1)
+			f.backoff = time.Millisecond
+			if got := f.GetAnnotations(context.Background(), gvr, "ns", "cfg"); got["k"] != "v" {
+				t.Fatalf("first lookup = %v, want {k:v}", got)
+			}
+
+			client.PrependReactor("get", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, tt.err
+			})
+			time.Sleep(5 * time.Millisecond)
+			if got := f.GetAnnotations(context.Background(), gvr, "ns", "cfg"); !maps.Equal(got, tt.want) {
+				t.Errorf("lookup after a failed refresh = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetcher_EvictsStaleEntries(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "ConfigMapList"})
+	f := newCachedFetcher(client, 10*time.Millisecond, time.Second, 0)
+	cached := func() []string {
+		f.mu.RLock()
+		defer f.mu.RUnlock()
+		return slices.Sorted(maps.Keys(f.cache))
+	}
+
+	// Lookups of objects that are gone, like a deleted Gateway's.
+	f.Get(context.Background(), gvr, "ns", "deleted")
+	if _, err := f.List(context.Background(), gvr, "ns", labels.SelectorFromSet(labels.Set{"svc": "deleted"})); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if got := cached(); len(got) != 2 {
+		t.Fatalf("cache = %v, want both lookups", got)
+	}
+	time.Sleep(30 * time.Millisecond)
+	f.Get(context.Background(), gvr, "ns", "live")
+	if got, want := cached(), []string{"/v1, Resource=configmaps/ns/live"}; !slices.Equal(got, want) {
+		t.Errorf("cache = %v, want only %v once the others are stale", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package k8s
+
+import (
+	"github.com/home-operations/gatus-sidecar/internal/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// groupSource reports the group one source assigns and whether the source
+// set it at all. A set-but-empty group is an explicit opt-out.
+type groupSource func() (string, bool)
+
+// resolveGroup is the single place that decides an endpoint's group. Sources
+// are consulted in order and the first one that sets a group wins, even when
+// it sets it to "" — that clears anything further down the chain:
+//
+//  1. the object's own template annotation
+//  2. the object's group annotation
+//  3. the object's --group-by-label label
+//  4. the parent's template annotation (Gateway, IngressClass)
+//  5. --default-group
+func resolveGroup(obj metav1.Object, objTpl, parentTpl map[string]any, cfg *config.Config) string {
+	sources := []groupSource{
+		templateGroup(objTpl),
+		annotationGroup(obj, cfg.GroupAnnotation),
+		labelGroup(obj, cfg.GroupByLabel),
+		templateGroup(parentTpl),
+		nonEmpty(cfg.DefaultGroup),
+	}
+	for _, source := range sources {
+		if group, ok := source(); ok {
+			return group
+		}
+	}
+	return ""
+}
+
+func templateGroup(tpl map[string]any) groupSource {
+	return func() (string, bool) {
+		group, ok := tpl["group"].(string)
+		return group, ok
+	}
+}
+
+// annotationGroup reads the shorthand group annotation, which spares users
+// a YAML template for the one field they most often set.
+func annotationGroup(obj metav1.Object, key string) groupSource {
+	return func() (string, bool) {
+		if key == "" || obj == nil {
+			return "", false
+		}
+		group, ok := obj.GetAnnotations()[key]
+		return group, ok
+	}
+}
+
+// labelGroup reads the --group-by-label label, grouping by something like
+// app.kubernetes.io/part-of across namespaces. An empty value counts as
+// unset: labels are often stamped out by charts, not chosen per object.
+func labelGroup(obj metav1.Object, key string) groupSource {
+	return func() (string, bool) {
+		if key == "" || obj == nil {
+			return "", false
+		}
+		group := obj.GetLabels()[key]
+		return group, group != ""
+	}
+}
+
+// nonEmpty treats "" as unset, for sources (flags) that can't express an
+// explicit empty group.
+func nonEmpty(group string) groupSource {
+	return func() (string, bool) { return group, group != "" }
+}
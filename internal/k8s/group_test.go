@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResolveGroup(t *testing.T) {
+	cases := []struct {
+		name         string
+		obj, parent  map[string]any
+		annotations  map[string]string
+		labels       map[string]string
+		defaultGroup string
+		want         string
+	}{
+		{"nothing set", nil, nil, nil, nil, "", ""},
+		{"default only", nil, nil, nil, nil, "fallback", "fallback"},
+		{"parent beats default", nil, map[string]any{"group": "gateway"}, nil, nil, "fallback", "gateway"},
+		{"object beats parent", map[string]any{"group": "route"}, map[string]any{"group": "gateway"}, nil, nil, "fallback", "route"},
+		{"object beats default", map[string]any{"group": "route"}, nil, nil, nil, "fallback", "route"},
+		{"explicit empty object clears parent", map[string]any{"group": ""}, map[string]any{"group": "gateway"}, nil, nil, "fallback", ""},
+		{"explicit empty parent clears default", nil, map[string]any{"group": ""}, nil, nil, "fallback", ""},
+		{"non-string object group is ignored", map[string]any{"group": 42}, map[string]any{"group": "gateway"}, nil, nil, "", "gateway"},
+		{"annotation beats parent", nil, map[string]any{"group": "gateway"}, map[string]string{"group": "Infrastructure"}, nil, "fallback", "Infrastructure"},
+		{"annotation beats default", nil, nil, map[string]string{"group": "Infrastructure"}, nil, "fallback", "Infrastructure"},
+		{"object template beats annotation", map[string]any{"group": "route"}, nil, map[string]string{"group": "Infrastructure"}, nil, "", "route"},
+		{"explicit empty annotation clears parent", nil, map[string]any{"group": "gateway"}, map[string]string{"group": ""}, nil, "fallback", ""},
+		{"unrelated keys fall through", map[string]any{"interval": "5s"}, nil, nil, nil, "fallback", "fallback"},
+		{"label beats default", nil, nil, nil, map[string]string{"part-of": "media"}, "fallback", "media"},
+		{"label beats parent", nil, map[string]any{"group": "gateway"}, nil, map[string]string{"part-of": "media"}, "", "media"},
+		{"absent label falls back to parent", nil, map[string]any{"group": "gateway"}, nil, map[string]string{"app": "x"}, "fallback", "gateway"},
+		{"absent label falls back to default", nil, nil, nil, nil, "fallback", "fallback"},
+		{"empty label falls back", nil, nil, nil, map[string]string{"part-of": ""}, "fallback", "fallback"},
+		{"annotation beats label", nil, nil, map[string]string{"group": "Infrastructure"}, map[string]string{"part-of": "media"}, "", "Infrastructure"},
+		{"object template beats label", map[string]any{"group": "route"}, nil, nil, map[string]string{"part-of": "media"}, "", "route"},
+		{"explicit empty object clears label", map[string]any{"group": ""}, nil, nil, map[string]string{"part-of": "media"}, "fallback", ""},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{DefaultGroup: tt.defaultGroup, GroupAnnotation: "group", GroupByLabel: "part-of"}
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations, Labels: tt.labels}
+			if got := resolveGroup(obj, tt.obj, tt.parent, cfg); got != tt.want {
+				t.Errorf("resolveGroup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
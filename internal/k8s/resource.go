@@ -4,6 +4,7 @@ package k8s
 
 import (
 	"context"
+	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 
@@ -22,6 +23,10 @@ type Resource interface {
 	// a Service sharing a metadata.name produce distinct endpoints.
 	Prefix(cfg *config.Config) string
 
+	// Interval is the probe interval for the kind's endpoints, normally
+	// cfg.Interval of its kind.
+	Interval(cfg *config.Config) time.Duration
+
 	Convert(u *unstructured.Unstructured) (metav1.Object, error)
 
 	// Matches reports whether obj passes the per-kind filters (auto flags,
@@ -41,3 +46,117 @@ type Resource interface {
 	// inheritance (Gateway → HTTPRoute, IngressClass → Ingress) or nil.
 	ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher Fetcher) map[string]string
 }
+
+// Target is one probe derived from an object. Suffix tells targets of the
+// same object apart; it is appended to the endpoint name and writer key, and
+// is "" for a resource's single default target.
+type Target struct {
+	Suffix    string
+	URL       string
+	GuardHost string
+
+	// Host and Port are what the host-filter and port-filter annotations
+	// match against. A filter ignores targets that leave its field empty.
+	Host string
+	Port string
+
+	// Conditions, when set, replace the resource's DefaultConditions for
+	// this target, whose URL differs in kind from the rest (a tcp:// probe
+	// of an Ingress's default backend).
+	Conditions []string
+
+	// HostHeader, when set, is sent as the Host header because URL points
+	// at a proxy rather than the host itself (--ingress-internal-probe).
+	HostHeader string
+
+	// DNSServer turns the target into a DNS query for DNSQuery against
+	// this server (an IP or hostname) instead of a probe of URL, which is
+	// then only validated.
+	DNSServer string
+	DNSQuery  string
+}
+
+// MultiTargetResource is implemented by Resources that can fan one object out
+// into several endpoints (one per host, per port, ...). When implemented,
+// Targets replaces URL and GuardHost in the controller.
+type MultiTargetResource interface {
+	Resource
+	Targets(obj metav1.Object, cfg *config.Config) []Target
+}
+
+// TargetResolver is implemented by Resources whose targets can be extended
+// from objects they reference but don't own, such as the Service behind an
+// Ingress path. ResolveTargets receives the extracted targets, before the
+// host and port filters, and returns them with any additions.
+type TargetResolver interface {
+	Resource
+	ResolveTargets(ctx context.Context, obj metav1.Object, targets []Target, cfg *config.Config, fetcher Fetcher) []Target
+}
+
+// ReadinessGate is implemented by Resources that hold an object's endpoints
+// back until something it depends on is ready, such as the backends of a
+// Service. Ready is consulted after Matches; false removes the object's
+// endpoints as a filter would.
+type ReadinessGate interface {
+	Ready(ctx context.Context, obj metav1.Object, cfg *config.Config, fetcher Fetcher) bool
+}
+
+// NamedResource is implemented by Resources whose objects have generated
+// names (EndpointSlices) and should be named after something more stable.
+// EndpointName replaces metadata.name as the base of the endpoint name; the
+// kind prefix and any target suffix are still applied around it.
+type NamedResource interface {
+	Resource
+	EndpointName(obj metav1.Object) string
+}
+
+// ExternalDefaulter is implemented by Resources with a flag that makes their
+// endpoints push-based external-endpoints by default. The per-resource
+// external annotation overrides it either way.
+type ExternalDefaulter interface {
+	Resource
+	ExternalDefault(cfg *config.Config) bool
+}
+
+// ClusterScopedResource is implemented by Resources whose objects have no
+// namespace (IngressClass, GatewayClass). Their controller watches across
+// the whole cluster, ignoring --namespace, which can't select them.
+type ClusterScopedResource interface {
+	Resource
+	ClusterScoped() bool
+}
+
+// ParentResource is implemented by Resources that inherit a template from a
+// parent object. With --watch-parents the controller watches ParentGVR and
+// requeues every object whose ParentKeys name a parent that changed.
+type ParentResource interface {
+	Resource
+	ParentGVR() schema.GroupVersionResource
+	// ParentKeys returns the informer cache keys of obj's parents
+	// ("namespace/name", or "name" when cluster-scoped), or nil for none.
+	ParentKeys(obj metav1.Object) []string
+}
+
+// DependentResource is implemented by Resources gated on objects of another
+// kind, such as a Service on its EndpointSlices under --require-backends.
+// When DependencyGVR reports true, the controller watches that kind in the
+// namespaces it watches, serves lookups of it from that watch, and requeues
+// the objects DependentKeys names whenever one changes, so a gate opens and
+// closes without waiting for the gated object to change.
+type DependentResource interface {
+	Resource
+	DependencyGVR(cfg *config.Config) (schema.GroupVersionResource, bool)
+	// DependentKeys returns the informer cache keys of the objects dep
+	// gates, or nil for none.
+	DependentKeys(dep metav1.Object) []string
+}
+
+// MultiParentResource is implemented by Resources whose objects can have
+// several parents, such as an HTTPRoute attached to two Gateways. Their
+// templates are deep-merged with the first parent winning a conflict,
+// rather than taken from ParentAnnotations.
+type MultiParentResource interface {
+	Resource
+	// AllParentAnnotations returns each parent's annotations, in order.
+	AllParentAnnotations(ctx context.Context, obj metav1.Object, fetcher Fetcher) []map[string]string
+}
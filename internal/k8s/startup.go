@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+)
+
+// FlushAfterSync releases w's startup hold once every controller has
+// reconciled its initial listing, writing the combined state in one flush.
+// That write is built purely from the live cluster, so endpoints for
+// resources deleted while the sidecar was down are dropped even if no
+// controller had anything to delete.
+//
+// A positive timeout bounds the wait, so one kind whose listing hangs (API
+// timeouts, a broken conversion webhook) doesn't keep every other kind out
+// of the file: once it expires the hold is lifted with the kinds listed so
+// far, and each pending kind is written when its own listing completes.
+func FlushAfterSync(ctx context.Context, w *gatus.Writer, controllers []*Controller, timeout time.Duration) {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for i, c := range controllers {
+		select {
+		case <-c.Synced():
+		case <-ctx.Done():
+			return
+		case <-expired:
+			var pending []string
+			for _, c := range controllers[i:] {
+				select {
+				case <-c.Synced():
+				default:
+					pending = append(pending, c.Resource())
+				}
+			}
+			slog.Warn("initial listing still incomplete; writing the kinds listed so far",
+				"timeout", timeout, "pending", pending)
+			if err := w.Release(); err != nil {
+				slog.Error("initial flush failed", "error", err)
+			}
+			return
+		}
+	}
+	if err := w.Release(); err != nil {
+		slog.Error("initial flush failed", "error", err)
+		return
+	}
+	slog.Info("initial listing complete", "controllers", len(controllers), "endpoints", w.Len())
+}
@@ -0,0 +1,172 @@
+package resources
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var endpointSliceGVR = schema.GroupVersionResource{
+	Group:    "discovery.k8s.io",
+	Version:  "v1",
+	Resource: "endpointslices",
+}
+
+// EndpointSlice monitors the pods behind a Service directly, one endpoint
+// per ready address and port. It exists for headless Services, whose .svc
+// name has no single address to probe.
+type EndpointSlice struct{}
+
+func (EndpointSlice) GVR() schema.GroupVersionResource { return endpointSliceGVR }
+
+func (EndpointSlice) Prefix(cfg *config.Config) string { return cfg.Prefix(config.KindEndpointSlice) }
+func (EndpointSlice) Interval(cfg *config.Config) time.Duration {
+	return cfg.Interval(config.KindEndpointSlice)
+}
+
+func (EndpointSlice) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
+	return convertTo[discoveryv1.EndpointSlice](u)
+}
+
+// Matches skips slices not owned by a Service (no service-name label), since
+// there is nothing stable to name their endpoints after.
+func (EndpointSlice) Matches(obj metav1.Object, cfg *config.Config) bool {
+	if _, ok := obj.(*discoveryv1.EndpointSlice); !ok {
+		return false
+	}
+	if sliceServiceName(obj) == "" {
+		return false
+	}
+	return matchesAnnotation(obj, cfg.AutoEnabled(config.KindEndpointSlice), cfg)
+}
+
+// EndpointName names endpoints after the owning Service rather than the
+// slice's generated name.
+func (EndpointSlice) EndpointName(obj metav1.Object) string { return sliceServiceName(obj) }
+
+// URL probes the first ready address on the first port; see
+// [EndpointSlice.Targets] for the rest.
+func (s EndpointSlice) URL(obj metav1.Object) string {
+	targets := s.Targets(obj, nil)
+	if len(targets) == 0 {
+		return ""
+	}
+	return targets[0].URL
+}
+
+// Targets yields one target per ready address and port, suffixed with the
+// pod name (or address when there's no pod reference) and, for multi-port
+// slices, the port. Addresses that go unready or disappear drop out of the
+// list and the controller removes their endpoints.
+func (EndpointSlice) Targets(obj metav1.Object, _ *config.Config) []k8s.Target {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil
+	}
+	var out []k8s.Target
+	for _, ep := range slice.Endpoints {
+		if !endpointReady(ep) || len(ep.Addresses) == 0 {
+			continue
+		}
+		for _, port := range slice.Ports {
+			if port.Port == nil {
+				continue
+			}
+			out = append(out, k8s.Target{
+				Suffix: endpointSuffix(ep, port, len(slice.Ports) > 1),
+				URL:    endpointPortURL(ep.Addresses[0], port),
+			})
+		}
+	}
+	return out
+}
+
+func (EndpointSlice) DefaultConditions() []string { return tcpDefaultConditions }
+
+// Pod addresses have no meaningful guarded mode.
+func (EndpointSlice) GuardHost(metav1.Object) string { return "" }
+
+// ParentAnnotations returns the owning Service's annotations, so a template
+// on the Service applies to every pod endpoint behind it.
+func (EndpointSlice) ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher k8s.Fetcher) map[string]string {
+	name := sliceServiceName(obj)
+	if name == "" {
+		return nil
+	}
+	return fetcher.GetAnnotations(ctx, serviceGVR, obj.GetNamespace(), name)
+}
+
+func sliceServiceName(obj metav1.Object) string {
+	return obj.GetLabels()[discoveryv1.LabelServiceName]
+}
+
+// endpointReady treats a nil ready condition as ready, per the
+// EndpointConditions API contract.
+func endpointReady(ep discoveryv1.Endpoint) bool {
+	return ep.Conditions.Ready == nil || *ep.Conditions.Ready
+}
+
+// readyBackends counts the distinct ready Pods (or addresses, for
+// endpoints without a Pod) across the EndpointSlices of the named Service.
+// A dual-stack Pod, listed once per IP family, counts once.
+func readyBackends(ctx context.Context, fetcher k8s.Fetcher, namespace, service string) (int, error) {
+	items, err := fetcher.List(ctx, endpointSliceGVR, namespace,
+		labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: service}))
+	if err != nil {
+		return 0, err
+	}
+	ready := make(map[string]bool)
+	for _, u := range items {
+		obj, err := convertTo[discoveryv1.EndpointSlice](u)
+		if err != nil {
+			continue
+		}
+		for _, ep := range obj.(*discoveryv1.EndpointSlice).Endpoints {
+			if !endpointReady(ep) || len(ep.Addresses) == 0 {
+				continue
+			}
+			id := ep.Addresses[0]
+			if ep.TargetRef != nil && ep.TargetRef.Name != "" {
+				id = ep.TargetRef.Namespace + "/" + ep.TargetRef.Name
+			}
+			ready[id] = true
+		}
+	}
+	return len(ready), nil
+}
+
+func endpointSuffix(ep discoveryv1.Endpoint, port discoveryv1.EndpointPort, withPort bool) string {
+	suffix := strings.NewReplacer(".", "-", ":", "-").Replace(ep.Addresses[0])
+	if ep.TargetRef != nil && ep.TargetRef.Name != "" {
+		suffix = ep.TargetRef.Name
+	}
+	if withPort {
+		name := strconv.Itoa(int(*port.Port))
+		if port.Name != nil && *port.Name != "" {
+			name = *port.Name
+		}
+		suffix += "-" + name
+	}
+	return suffix
+}
+
+// endpointPortURL builds <protocol>://<address>:<port>, bracketing IPv6.
+func endpointPortURL(address string, port discoveryv1.EndpointPort) string {
+	protocol := corev1.ProtocolTCP
+	if port.Protocol != nil && *port.Protocol != "" {
+		protocol = *port.Protocol
+	}
+	return urlutil.HostPortURL(strings.ToLower(string(protocol)), address, int(*port.Port))
+}
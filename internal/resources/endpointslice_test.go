@@ -0,0 +1,177 @@
+package resources
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func makeSlice(service string, ports []discoveryv1.EndpointPort, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	labels := map[string]string{}
+	if service != "" {
+		labels[discoveryv1.LabelServiceName] = service
+	}
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: service + "-x7k2p", Namespace: "db", Labels: labels},
+		Ports:      ports,
+		Endpoints:  endpoints,
+	}
+}
+
+func slicePort(name string, port int32) discoveryv1.EndpointPort {
+	return discoveryv1.EndpointPort{Name: &name, Port: &port}
+}
+
+func podEndpoint(pod, address string, ready *bool) discoveryv1.Endpoint {
+	ep := discoveryv1.Endpoint{
+		Addresses:  []string{address},
+		Conditions: discoveryv1.EndpointConditions{Ready: ready},
+	}
+	if pod != "" {
+		ep.TargetRef = &corev1.ObjectReference{Kind: "Pod", Name: pod}
+	}
+	return ep
+}
+
+func TestEndpointSlice_Targets(t *testing.T) {
+	t.Parallel()
+	ready, notReady := true, false
+	udp := corev1.ProtocolUDP
+	dnsPort := slicePort("dns", 53)
+	dnsPort.Protocol = &udp
+
+	cases := []struct {
+		name  string
+		slice *discoveryv1.EndpointSlice
+		want  []k8s.Target
+	}{
+		{
+			name: "single port named by pod",
+			slice: makeSlice("pg", []discoveryv1.EndpointPort{slicePort("", 5432)},
+				podEndpoint("pg-0", "10.1.0.4", &ready),
+				podEndpoint("pg-1", "10.1.0.5", nil),
+			),
+			want: []k8s.Target{
+				{Suffix: "pg-0", URL: "tcp://10.1.0.4:5432"},
+				{Suffix: "pg-1", URL: "tcp://10.1.0.5:5432"},
+			},
+		},
+		{
+			name: "unready endpoints skipped",
+			slice: makeSlice("pg", []discoveryv1.EndpointPort{slicePort("", 5432)},
+				podEndpoint("pg-0", "10.1.0.4", &notReady),
+				podEndpoint("pg-1", "10.1.0.5", &ready),
+			),
+			want: []k8s.Target{{Suffix: "pg-1", URL: "tcp://10.1.0.5:5432"}},
+		},
+		{
+			name: "multi port adds port suffix",
+			slice: makeSlice("dns", []discoveryv1.EndpointPort{dnsPort, slicePort("", 9153)},
+				podEndpoint("", "fd00::7", nil),
+			),
+			want: []k8s.Target{
+				{Suffix: "fd00--7-dns", URL: "udp://[fd00::7]:53"},
+				{Suffix: "fd00--7-9153", URL: "tcp://[fd00::7]:9153"},
+			},
+		},
+		{
+			name:  "no ready addresses",
+			slice: makeSlice("pg", []discoveryv1.EndpointPort{slicePort("", 5432)}),
+			want:  nil,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (EndpointSlice{}).Targets(tt.slice, &config.Config{}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Targets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpointSlice_URLAndName(t *testing.T) {
+	t.Parallel()
+	slice := makeSlice("pg", []discoveryv1.EndpointPort{slicePort("", 5432)}, podEndpoint("pg-0", "10.1.0.4", nil))
+	if got := (EndpointSlice{}).URL(slice); got != "tcp://10.1.0.4:5432" {
+		t.Errorf("URL() = %q", got)
+	}
+	if got := (EndpointSlice{}).EndpointName(slice); got != "pg" {
+		t.Errorf("EndpointName() = %q, want pg", got)
+	}
+	if got := (EndpointSlice{}).URL(&corev1.Pod{}); got != "" {
+		t.Errorf("URL() on wrong type = %q", got)
+	}
+}
+
+func TestEndpointSlice_Matches(t *testing.T) {
+	t.Parallel()
+	auto := &config.Config{Kinds: autoEnabled(config.KindEndpointSlice)}
+	if !(EndpointSlice{}).Matches(makeSlice("pg", nil), auto) {
+		t.Error("auto mode should match a Service-owned slice")
+	}
+	if (EndpointSlice{}).Matches(makeSlice("", nil), auto) {
+		t.Error("slices without a service-name label should not match")
+	}
+	if (EndpointSlice{}).Matches(makeSlice("pg", nil), &config.Config{EnabledAnnotation: "x", TemplateAnnotation: "y"}) {
+		t.Error("no auto + no annotations should not match")
+	}
+}
+
+func TestReadyBackends(t *testing.T) {
+	t.Parallel()
+	sliceGVK := endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")
+	unready := false
+	// A dual-stack Service: each Pod appears in the IPv4 and the IPv6 slice.
+	v4 := makeSlice("pg", nil,
+		podEndpoint("pg-0", "10.0.0.1", nil),
+		podEndpoint("pg-1", "10.0.0.2", &unready),
+		podEndpoint("", "10.0.0.9", nil))
+	v6 := makeSlice("pg", nil,
+		podEndpoint("pg-0", "fd00::1", nil),
+		podEndpoint("pg-1", "fd00::2", &unready))
+	v6.Name = "pg-v6"
+	other := makeSlice("redis", nil, podEndpoint("redis-0", "10.0.1.1", nil))
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{endpointSliceGVR: "EndpointSliceList"},
+		toUnstructured(t, v4, sliceGVK), toUnstructured(t, v6, sliceGVK), toUnstructured(t, other, sliceGVK))
+	fetcher := newClientFetcher(client)
+
+	for service, want := range map[string]int{"pg": 2, "redis": 1, "missing": 0} {
+		got, err := readyBackends(context.Background(), fetcher, "db", service)
+		if err != nil || got != want {
+			t.Errorf("readyBackends(%s) = %d, %v; want %d", service, got, err, want)
+		}
+	}
+}
+
+func TestEndpointSlice_ParentAnnotations(t *testing.T) {
+	t.Parallel()
+	svc := &unstructured.Unstructured{}
+	svc.SetAPIVersion("v1")
+	svc.SetKind("Service")
+	svc.SetNamespace("db")
+	svc.SetName("pg")
+	svc.SetAnnotations(map[string]string{"gatus.home-operations.com/endpoint": "group: data"})
+
+	fetcher := newClientFetcher(fake.NewSimpleDynamicClient(runtime.NewScheme(), svc))
+	got := (EndpointSlice{}).ParentAnnotations(context.Background(), makeSlice("pg", nil), fetcher)
+	if got["gatus.home-operations.com/endpoint"] != "group: data" {
+		t.Errorf("ParentAnnotations() = %v", got)
+	}
+	if got := (EndpointSlice{}).ParentAnnotations(context.Background(), makeSlice("", nil), fetcher); got != nil {
+		t.Errorf("ParentAnnotations() without service = %v, want nil", got)
+	}
+}
@@ -0,0 +1,133 @@
+package resources
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// Gateway monitors the listeners of a Gateway API Gateway directly, one
+// tcp:// endpoint per listener on the Gateway's assigned address. It
+// complements HTTPRoute monitoring by catching outages at the Gateway
+// (load balancer) level. Version is as for [HTTPRoute].
+type Gateway struct {
+	Version string
+}
+
+func (g Gateway) GVR() schema.GroupVersionResource { return gatewayAPIGVR("gateways", g.Version) }
+
+func (Gateway) Prefix(cfg *config.Config) string          { return cfg.Prefix(config.KindGateway) }
+func (Gateway) Interval(cfg *config.Config) time.Duration { return cfg.Interval(config.KindGateway) }
+
+func (Gateway) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
+	return convertTo[gatewayv1.Gateway](u)
+}
+
+func (Gateway) Matches(obj metav1.Object, cfg *config.Config) bool {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return false
+	}
+	if !gatewayMatches(gw.Namespace, gw.Name, cfg) {
+		return false
+	}
+	return matchesAnnotation(obj, cfg.AutoEnabled(config.KindGateway), cfg)
+}
+
+// URL probes the first listener; see [Gateway.Targets] for the rest.
+func (Gateway) URL(obj metav1.Object) string {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok || len(gw.Spec.Listeners) == 0 {
+		return ""
+	}
+	return gatewayListenerURL(gw, gw.Spec.Listeners[0])
+}
+
+// Targets yields one target per listener, suffixed with the listener name so
+// endpoints come out as <gateway>-<listener>. Until the Gateway has an
+// address in its status every URL is empty and nothing is emitted; the
+// status update that assigns one re-triggers reconciliation.
+//
+// With --gateway-all-addresses it yields one target per address and
+// listener instead, named <gateway>-<listener>-<address>, so a dual-stack
+// or multi-LB Gateway losing a single address is noticed.
+func (Gateway) Targets(obj metav1.Object, cfg *config.Config) []k8s.Target {
+	gw, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return nil
+	}
+	if cfg.GatewayAllAddresses {
+		addrs := gatewayAddresses(gw)
+		out := make([]k8s.Target, 0, len(addrs)*len(gw.Spec.Listeners))
+		for _, addr := range addrs {
+			for _, l := range gw.Spec.Listeners {
+				out = append(out, k8s.Target{
+					Suffix: string(l.Name) + "-" + addr,
+					URL:    listenerURL(addr, l),
+				})
+			}
+		}
+		return out
+	}
+	out := make([]k8s.Target, 0, len(gw.Spec.Listeners))
+	for _, l := range gw.Spec.Listeners {
+		out = append(out, k8s.Target{
+			Suffix: string(l.Name),
+			URL:    gatewayListenerURL(gw, l),
+		})
+	}
+	return out
+}
+
+func (Gateway) DefaultConditions() []string { return tcpDefaultConditions }
+
+// Listener hostnames may be wildcards, so there is no host to guard.
+func (Gateway) GuardHost(metav1.Object) string { return "" }
+
+func (Gateway) ParentAnnotations(context.Context, metav1.Object, k8s.Fetcher) map[string]string {
+	return nil
+}
+
+// gatewayListenerURL builds <protocol>://<address>:<port> for a listener, or
+// "" while the Gateway has no usable address.
+func gatewayListenerURL(gw *gatewayv1.Gateway, l gatewayv1.Listener) string {
+	addrs := gatewayAddresses(gw)
+	if len(addrs) == 0 {
+		return ""
+	}
+	return listenerURL(addrs[0], l)
+}
+
+// listenerURL builds <protocol>://<addr>:<port> for a listener.
+func listenerURL(addr string, l gatewayv1.Listener) string {
+	scheme := "tcp"
+	if l.Protocol == gatewayv1.UDPProtocolType {
+		scheme = "udp"
+	}
+	return urlutil.HostPortURL(scheme, addr, int(l.Port))
+}
+
+// gatewayAddresses returns the IP and hostname addresses in the Gateway's
+// status, in order and without duplicates. Implementation-specific (named)
+// addresses can't be probed.
+func gatewayAddresses(gw *gatewayv1.Gateway) []string {
+	var out []string
+	for _, a := range gw.Status.Addresses {
+		if a.Value == "" || slices.Contains(out, a.Value) {
+			continue
+		}
+		if a.Type == nil || *a.Type == gatewayv1.IPAddressType || *a.Type == gatewayv1.HostnameAddressType {
+			out = append(out, a.Value)
+		}
+	}
+	return out
+}
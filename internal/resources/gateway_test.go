@@ -0,0 +1,149 @@
+package resources
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func makeGateway(name string, addresses []gatewayv1.GatewayStatusAddress, listeners ...gatewayv1.Listener) *gatewayv1.Gateway {
+	return &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "network"},
+		Spec:       gatewayv1.GatewaySpec{Listeners: listeners},
+		Status:     gatewayv1.GatewayStatus{Addresses: addresses},
+	}
+}
+
+func gatewayIP(value string) []gatewayv1.GatewayStatusAddress {
+	ipType := gatewayv1.IPAddressType
+	return []gatewayv1.GatewayStatusAddress{{Type: &ipType, Value: value}}
+}
+
+func TestGateway_URL(t *testing.T) {
+	t.Parallel()
+	https := gatewayv1.Listener{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType}
+	dns := gatewayv1.Listener{Name: "dns", Port: 53, Protocol: gatewayv1.UDPProtocolType}
+	hostType := gatewayv1.HostnameAddressType
+	namedType := gatewayv1.NamedAddressType
+
+	cases := []struct {
+		name string
+		in   metav1.Object
+		want string
+	}{
+		{"ipv4", makeGateway("gw", gatewayIP("10.0.0.1"), https), "tcp://10.0.0.1:443"},
+		{"ipv6 bracketed", makeGateway("gw", gatewayIP("fd00::1"), https), "tcp://[fd00::1]:443"},
+		{"untyped address", makeGateway("gw", []gatewayv1.GatewayStatusAddress{{Value: "10.0.0.2"}}, https), "tcp://10.0.0.2:443"},
+		{"hostname", makeGateway("gw", []gatewayv1.GatewayStatusAddress{{Type: &hostType, Value: "lb.example.com"}}, https), "tcp://lb.example.com:443"},
+		{"named address skipped", makeGateway("gw", []gatewayv1.GatewayStatusAddress{
+			{Type: &namedType, Value: "my-lb"},
+			{Type: &hostType, Value: "lb.example.com"},
+		}, https), "tcp://lb.example.com:443"},
+		{"udp listener", makeGateway("gw", gatewayIP("10.0.0.1"), dns), "udp://10.0.0.1:53"},
+		{"no address yet", makeGateway("gw", nil, https), ""},
+		{"no listeners", makeGateway("gw", gatewayIP("10.0.0.1")), ""},
+		{"wrong type", &corev1.Pod{}, ""},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (Gateway{}).URL(tt.in); got != tt.want {
+				t.Errorf("URL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGateway_Targets(t *testing.T) {
+	t.Parallel()
+	gw := makeGateway("internal", gatewayIP("10.0.0.1"),
+		gatewayv1.Listener{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+		gatewayv1.Listener{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType},
+	)
+	want := []k8s.Target{
+		{Suffix: "http", URL: "tcp://10.0.0.1:80"},
+		{Suffix: "https", URL: "tcp://10.0.0.1:443"},
+	}
+	if got := (Gateway{}).Targets(gw, &config.Config{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets() = %+v, want %+v", got, want)
+	}
+
+	// Without an address every target is empty; the controller drops them.
+	pending := makeGateway("internal", nil, gatewayv1.Listener{Name: "http", Port: 80})
+	for _, target := range (Gateway{}).Targets(pending, &config.Config{}) {
+		if target.URL != "" {
+			t.Errorf("pending gateway target URL = %q, want \"\"", target.URL)
+		}
+	}
+}
+
+func TestGateway_TargetsAllAddresses(t *testing.T) {
+	t.Parallel()
+	ipType := gatewayv1.IPAddressType
+	namedType := gatewayv1.NamedAddressType
+	gw := makeGateway("internal", []gatewayv1.GatewayStatusAddress{
+		{Type: &ipType, Value: "10.0.0.1"},
+		{Type: &namedType, Value: "my-lb"},
+		{Type: &ipType, Value: "fd00::1"},
+		{Type: &ipType, Value: "10.0.0.1"},
+	},
+		gatewayv1.Listener{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+		gatewayv1.Listener{Name: "dns", Port: 53, Protocol: gatewayv1.UDPProtocolType},
+	)
+	want := []k8s.Target{
+		{Suffix: "http-10.0.0.1", URL: "tcp://10.0.0.1:80"},
+		{Suffix: "dns-10.0.0.1", URL: "udp://10.0.0.1:53"},
+		{Suffix: "http-fd00::1", URL: "tcp://[fd00::1]:80"},
+		{Suffix: "dns-fd00::1", URL: "udp://[fd00::1]:53"},
+	}
+	cfg := &config.Config{GatewayAllAddresses: true}
+	if got := (Gateway{}).Targets(gw, cfg); !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets() = %+v, want %+v", got, want)
+	}
+	if got := (Gateway{}).Targets(makeGateway("internal", nil, gatewayv1.Listener{Name: "http", Port: 80}), cfg); len(got) != 0 {
+		t.Errorf("pending gateway Targets() = %+v, want none", got)
+	}
+}
+
+func TestGateway_Matches(t *testing.T) {
+	t.Parallel()
+	gw := makeGateway("internal", nil)
+	auto := &config.Config{Kinds: autoEnabled(config.KindGateway)}
+	if !(Gateway{}).Matches(gw, auto) {
+		t.Error("auto mode should match")
+	}
+	if (Gateway{}).Matches(gw, &config.Config{EnabledAnnotation: "x", TemplateAnnotation: "y"}) {
+		t.Error("no auto + no annotations should not match")
+	}
+	filtered := &config.Config{Kinds: autoEnabled(config.KindGateway), GatewayNames: config.StringSet{"external"}}
+	if (Gateway{}).Matches(gw, filtered) {
+		t.Error("--gateway-name should filter out other gateways")
+	}
+	if (Gateway{}).Matches(gw, &config.Config{Kinds: autoEnabled(config.KindGateway), GatewayNamespaces: config.StringSet{"infra"}}) {
+		t.Error("--gateway-namespace should filter out gateways in other namespaces")
+	}
+	if (Gateway{}).Matches(&corev1.Pod{}, auto) {
+		t.Error("wrong type should not match")
+	}
+}
+
+func TestGateway_GuardHostAndParentAnnotations_NoOps(t *testing.T) {
+	t.Parallel()
+	gw := makeGateway("internal", gatewayIP("10.0.0.1"))
+	if got := (Gateway{}).GuardHost(gw); got != "" {
+		t.Errorf("GuardHost() = %q, want \"\"", got)
+	}
+	if ann := (Gateway{}).ParentAnnotations(context.Background(), gw, nil); ann != nil {
+		t.Errorf("ParentAnnotations should always return nil, got %v", ann)
+	}
+	if got := (Gateway{}).DefaultConditions(); len(got) != 1 || got[0] != "[CONNECTED] == true" {
+		t.Errorf("DefaultConditions() = %v", got)
+	}
+}
@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"slices"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// gatewayAPIVersions are tried newest first. Older clusters serve
+// HTTPRoutes only at v1beta1, which converts into the v1 structs unchanged.
+var gatewayAPIVersions = []string{config.GatewayAPIV1, config.GatewayAPIV1Beta1}
+
+// UsesGatewayAPI reports whether r watches a Gateway API kind, and so needs
+// --gateway-api-version resolved first.
+func UsesGatewayAPI(r k8s.Resource) bool {
+	return r.GVR().Group == gatewayAPIGroup
+}
+
+// ResolveGatewayAPIVersion returns the newest Gateway API version d reports
+// serving HTTPRoutes at. With neither served (or discovery failing) it
+// returns v1, so the watch fails as loudly as it would have anyway.
+func ResolveGatewayAPIVersion(d discovery.ServerResourcesInterface) string {
+	for _, version := range gatewayAPIVersions {
+		list, err := d.ServerResourcesForGroupVersion(gatewayAPIGroup + "/" + version)
+		if err != nil {
+			continue
+		}
+		if slices.ContainsFunc(list.APIResources, func(r metav1.APIResource) bool { return r.Name == httpRouteGVR.Resource }) {
+			return version
+		}
+	}
+	return config.GatewayAPIV1
+}
@@ -0,0 +1,63 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResolveGatewayAPIVersion(t *testing.T) {
+	served := func(version string, resources ...string) *metav1.APIResourceList {
+		list := &metav1.APIResourceList{GroupVersion: gatewayAPIGroup + "/" + version}
+		for _, r := range resources {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: r})
+		}
+		return list
+	}
+	cases := []struct {
+		name   string
+		served []*metav1.APIResourceList
+		want   string
+	}{
+		{"v1 and v1beta1", []*metav1.APIResourceList{served("v1beta1", "httproutes", "gateways"), served("v1", "httproutes", "gateways")}, config.GatewayAPIV1},
+		{"v1beta1 only", []*metav1.APIResourceList{served("v1beta1", "httproutes", "gateways")}, config.GatewayAPIV1Beta1},
+		{"v1 without httproutes", []*metav1.APIResourceList{served("v1", "gateways"), served("v1beta1", "httproutes")}, config.GatewayAPIV1Beta1},
+		{"not installed", nil, config.GatewayAPIV1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: tt.served}}
+			if got := ResolveGatewayAPIVersion(d); got != tt.want {
+				t.Errorf("ResolveGatewayAPIVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAll_GatewayAPIVersion(t *testing.T) {
+	cfg := &config.Config{GatewayAPIVersion: config.GatewayAPIV1Beta1}
+	cfg.Kinds = map[string]*config.KindConfig{
+		config.KindHTTPRoute: {Enable: true},
+		config.KindGateway:   {Enable: true},
+	}
+	watched := 0
+	for _, r := range All(cfg) {
+		if !UsesGatewayAPI(r) {
+			continue
+		}
+		watched++
+		if got := r.GVR().Version; got != config.GatewayAPIV1Beta1 {
+			t.Errorf("%s: version %q, want v1beta1", r.GVR().Resource, got)
+		}
+		if pr, ok := r.(HTTPRoute); ok && pr.ParentGVR().Version != config.GatewayAPIV1Beta1 {
+			t.Errorf("parent gateway version %q, want v1beta1", pr.ParentGVR().Version)
+		}
+	}
+	if watched != 2 {
+		t.Errorf("got %d Gateway API kinds, want 2", watched)
+	}
+}
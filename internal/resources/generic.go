@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// Generic watches a kind named by --watch-gvr, such as a bespoke Website
+// CRD. It knows nothing of the kind's spec, so only annotated objects are
+// picked up and the controller probes the url their template sets.
+type Generic struct {
+	Kind schema.GroupVersionResource
+	// Cluster marks a cluster-scoped kind, watched across the cluster
+	// whatever --namespace says.
+	Cluster bool
+}
+
+func newGeneric(gvr config.GVR) Generic {
+	return Generic{
+		Kind:    schema.GroupVersionResource{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource},
+		Cluster: gvr.ClusterScoped,
+	}
+}
+
+func (g Generic) GVR() schema.GroupVersionResource { return g.Kind }
+
+// ClusterScoped implements [k8s.ClusterScopedResource].
+func (g Generic) ClusterScoped() bool { return g.Cluster }
+
+func (Generic) Prefix(*config.Config) string              { return "" }
+func (Generic) Interval(cfg *config.Config) time.Duration { return cfg.DefaultInterval }
+
+func (Generic) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
+	return u, nil
+}
+
+func (Generic) Matches(obj metav1.Object, cfg *config.Config) bool {
+	return matchesAnnotation(obj, false, cfg)
+}
+
+// URL extracts nothing; the template's url stands in for it.
+func (Generic) URL(metav1.Object) string { return "" }
+
+func (Generic) DefaultConditions() []string { return httpDefaultConditions }
+
+func (Generic) GuardHost(metav1.Object) string { return "" }
+
+func (Generic) ParentAnnotations(context.Context, metav1.Object, k8s.Fetcher) map[string]string {
+	return nil
+}
+
+// ResolveWatchGVRScopes marks the --watch-gvr kinds d reports as
+// cluster-scoped, so --namespace doesn't narrow their watch to a namespace
+// none of their objects can be in. Kinds d doesn't know stay namespaced;
+// their watch fails as it would have anyway.
+func ResolveWatchGVRScopes(d discovery.ServerResourcesInterface, gvrs config.GVRs) {
+	for i, gvr := range gvrs {
+		groupVersion := schema.GroupVersion{Group: gvr.Group, Version: gvr.Version}.String()
+		list, err := d.ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if r.Name == gvr.Resource {
+				gvrs[i].ClusterScoped = !r.Namespaced
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package resources
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var websiteGVR = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "websites"}
+
+func makeWebsite(name string, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind("Website")
+	u.SetName(name)
+	u.SetNamespace("default")
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestGeneric_Matches(t *testing.T) {
+	t.Parallel()
+	cfg := &config.Config{EnabledAnnotation: config.DefaultEnabledAnnotation, TemplateAnnotation: config.DefaultTemplateAnnotation}
+	g := newGeneric(config.GVR{Group: "example.com", Version: "v1", Resource: "websites"})
+	if g.GVR() != websiteGVR {
+		t.Errorf("GVR() = %v", g.GVR())
+	}
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{"template", map[string]string{config.DefaultTemplateAnnotation: "url: https://shop.example.com\n"}, true},
+		{"enabled", map[string]string{config.DefaultEnabledAnnotation: "true"}, true},
+		{"unannotated", nil, false},
+		{"disabled", map[string]string{config.DefaultEnabledAnnotation: "false", config.DefaultTemplateAnnotation: "url: https://x\n"}, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := g.Matches(makeWebsite("shop", tt.annotations), cfg); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntegration_GenericCustomResource(t *testing.T) {
+	t.Parallel()
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{websiteGVR: "WebsiteList"})
+	for _, obj := range []*unstructured.Unstructured{
+		makeWebsite("shop", map[string]string{config.DefaultTemplateAnnotation: "url: https://shop.example.com/health\ngroup: sites\n"}),
+		makeWebsite("blog", map[string]string{config.DefaultEnabledAnnotation: "true"}),
+		makeWebsite("ignored", nil),
+	} {
+		if _, err := client.Resource(websiteGVR).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("seed %s: %v", obj.GetName(), err)
+		}
+	}
+
+	args := []string{"--no-default-controllers", "--watch-gvr=example.com/v1/websites"}
+	cfg, err := config.Load("test", args, io.Discard)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	all := All(cfg)
+	if len(all) != 1 || all[0].GVR() != websiteGVR {
+		t.Fatalf("All() = %v, want the websites kind alone", all)
+	}
+	endpoints := generate(t, args, all[0], client)
+	if len(endpoints) != 1 {
+		t.Fatalf("expected only the Website whose template sets url, got %v", endpoints)
+	}
+	if e := endpoints[0]; e["name"] != "shop" || e["url"] != "https://shop.example.com/health" || e["group"] != "sites" {
+		t.Errorf("endpoint = %v", e)
+	}
+}
+
+func TestResolveWatchGVRScopes(t *testing.T) {
+	t.Parallel()
+	d := &discoveryfake.FakeDiscovery{Fake: &k8stesting.Fake{Resources: []*metav1.APIResourceList{{
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "websites", Namespaced: true},
+			{Name: "sites", Namespaced: false},
+		},
+	}}}}
+	gvrs := config.GVRs{
+		{Group: "example.com", Version: "v1", Resource: "websites"},
+		{Group: "example.com", Version: "v1", Resource: "sites"},
+		{Group: "other.io", Version: "v1", Resource: "unknowns"},
+	}
+	ResolveWatchGVRScopes(d, gvrs)
+	for i, want := range []bool{false, true, false} {
+		if gvrs[i].ClusterScoped != want {
+			t.Errorf("%s: ClusterScoped = %v, want %v", gvrs[i], gvrs[i].ClusterScoped, want)
+		}
+	}
+
+	cfg := &config.Config{NoDefaultControllers: true, WatchGVRs: gvrs}
+	for _, r := range All(cfg) {
+		cs, ok := r.(k8s.ClusterScopedResource)
+		if !ok {
+			t.Fatalf("%s: Generic doesn't implement k8s.ClusterScopedResource", r.GVR())
+		}
+		if want := r.GVR().Resource == "sites"; cs.ClusterScoped() != want {
+			t.Errorf("%s: ClusterScoped() = %v, want %v", r.GVR(), cs.ClusterScoped(), want)
+		}
+	}
+}
+
+func TestIntegration_GenericClusterScopedIgnoresNamespace(t *testing.T) {
+	t.Parallel()
+	siteGVR := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "sites"}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{siteGVR: "SiteList"})
+	site := &unstructured.Unstructured{}
+	site.SetAPIVersion("example.com/v1")
+	site.SetKind("Site")
+	site.SetName("shop")
+	site.SetAnnotations(map[string]string{config.DefaultTemplateAnnotation: "url: https://shop.example.com\n"})
+	if _, err := client.Resource(siteGVR).Create(context.Background(), site, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	args := []string{"--no-default-controllers", "--namespace=apps", "--watch-gvr=example.com/v1/sites"}
+	if endpoints := generate(t, args, Generic{Kind: siteGVR}, client); len(endpoints) != 0 {
+		t.Errorf("namespaced watch of a cluster-scoped kind found %v, want nothing", endpoints)
+	}
+	endpoints := generate(t, args, Generic{Kind: siteGVR, Cluster: true}, client)
+	if len(endpoints) != 1 || endpoints[0]["name"] != "shop" {
+		t.Errorf("cluster-scoped watch got %v, want the shop Site", endpoints)
+	}
+}
@@ -1,36 +1,50 @@
 package resources
 
 import (
+	"cmp"
 	"context"
 	"slices"
+	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
+const gatewayAPIGroup = "gateway.networking.k8s.io"
+
 var (
-	httpRouteGVR = schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "httproutes",
-	}
-	gatewayGVR = schema.GroupVersionResource{
-		Group:    "gateway.networking.k8s.io",
-		Version:  "v1",
-		Resource: "gateways",
-	}
+	httpRouteGVR = gatewayAPIGVR("httproutes", "")
+	gatewayGVR   = gatewayAPIGVR("gateways", "")
 )
 
-type HTTPRoute struct{}
+// gatewayAPIGVR returns the Gateway API resource at version, v1 when empty.
+func gatewayAPIGVR(resource, version string) schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    gatewayAPIGroup,
+		Version:  cmp.Or(version, config.GatewayAPIV1),
+		Resource: resource,
+	}
+}
 
-func (HTTPRoute) GVR() schema.GroupVersionResource { return httpRouteGVR }
+// HTTPRoute monitors Gateway API HTTPRoutes. Version picks the served API
+// version (see --gateway-api-version); empty means v1.
+type HTTPRoute struct {
+	Version string
+}
+
+func (h HTTPRoute) GVR() schema.GroupVersionResource { return gatewayAPIGVR("httproutes", h.Version) }
 
 func (HTTPRoute) Prefix(cfg *config.Config) string { return cfg.Prefix(config.KindHTTPRoute) }
+func (HTTPRoute) Interval(cfg *config.Config) time.Duration {
+	return cfg.Interval(config.KindHTTPRoute)
+}
 
 func (HTTPRoute) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
 	return convertTo[gatewayv1.HTTPRoute](u)
@@ -41,12 +55,19 @@ func (HTTPRoute) Matches(obj metav1.Object, cfg *config.Config) bool {
 	if !ok {
 		return false
 	}
-	if len(cfg.GatewayNames) > 0 && !httpRouteReferencesAnyGateway(route, cfg.GatewayNames) {
+	if (len(cfg.GatewayNames) > 0 || len(cfg.GatewayNamespaces) > 0) && !httpRouteReferencesAnyGateway(route, cfg) {
 		return false
 	}
 	return matchesAnnotation(obj, cfg.AutoEnabled(config.KindHTTPRoute), cfg)
 }
 
+// Ready implements [k8s.ReadinessGate] for --require-ready-status: a route
+// no Gateway has accepted yet would only report DOWN.
+func (HTTPRoute) Ready(_ context.Context, obj metav1.Object, cfg *config.Config, _ k8s.Fetcher) bool {
+	route, ok := obj.(*gatewayv1.HTTPRoute)
+	return !ok || !cfg.RequireReadyStatus || httpRouteAccepted(route, cfg)
+}
+
 func (HTTPRoute) URL(obj metav1.Object) string {
 	route, ok := obj.(*gatewayv1.HTTPRoute)
 	if !ok {
@@ -56,7 +77,7 @@ func (HTTPRoute) URL(obj metav1.Object) string {
 	if host == "" {
 		return ""
 	}
-	return formatURL(host, firstHTTPRoutePath(route), true)
+	return urlutil.BuildHostURL(host, firstHTTPRoutePath(route), true)
 }
 
 func (HTTPRoute) DefaultConditions() []string { return httpDefaultConditions }
@@ -69,27 +90,81 @@ func (HTTPRoute) GuardHost(obj metav1.Object) string {
 	return firstHTTPRouteHostname(route)
 }
 
-func (HTTPRoute) ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher k8s.Fetcher) map[string]string {
-	route, ok := obj.(*gatewayv1.HTTPRoute)
-	if !ok || len(route.Spec.ParentRefs) == 0 {
-		return nil
-	}
-	parent := route.Spec.ParentRefs[0]
-	if parent.Kind != nil && *parent.Kind != "Gateway" {
-		return nil
+// ParentAnnotations merges the annotations of every parent Gateway, the
+// first parentRef winning a key several set. The controller deep-merges
+// their templates instead, via [HTTPRoute.AllParentAnnotations].
+func (h HTTPRoute) ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher k8s.Fetcher) map[string]string {
+	var out map[string]string
+	for _, annotations := range h.AllParentAnnotations(ctx, obj, fetcher) {
+		if out == nil {
+			out = make(map[string]string, len(annotations))
+		}
+		for k, v := range annotations {
+			if _, ok := out[k]; !ok {
+				out[k] = v
+			}
+		}
 	}
+	return out
+}
 
-	gvr := gatewayGVR
-	if parent.Group != nil {
-		gvr.Group = string(*parent.Group)
+// AllParentAnnotations implements [k8s.MultiParentResource]: one map per
+// parent Gateway, in parentRef order.
+func (h HTTPRoute) AllParentAnnotations(ctx context.Context, obj metav1.Object, fetcher k8s.Fetcher) []map[string]string {
+	var out []map[string]string
+	for _, p := range h.parents(obj) {
+		if annotations := fetcher.GetAnnotations(ctx, p.gvr, p.namespace, p.name); annotations != nil {
+			out = append(out, annotations)
+		}
 	}
+	return out
+}
 
-	namespace := route.GetNamespace()
-	if parent.Namespace != nil {
-		namespace = string(*parent.Namespace)
+// ParentGVR implements [k8s.ParentResource].
+func (h HTTPRoute) ParentGVR() schema.GroupVersionResource {
+	return gatewayAPIGVR("gateways", h.Version)
+}
+
+// ParentKeys implements [k8s.ParentResource]. Parents of another API group
+// than the watched Gateways have no key.
+func (h HTTPRoute) ParentKeys(obj metav1.Object) []string {
+	var keys []string
+	for _, p := range h.parents(obj) {
+		if p.gvr == h.ParentGVR() {
+			keys = append(keys, p.namespace+"/"+p.name)
+		}
 	}
+	return keys
+}
 
-	return fetcher.GetAnnotations(ctx, gvr, namespace, string(parent.Name))
+// gatewayRef is a parentRef resolved to the Gateway it names.
+type gatewayRef struct {
+	gvr             schema.GroupVersionResource
+	namespace, name string
+}
+
+// parents resolves the route's parentRefs that name a Gateway, in order,
+// defaulting each namespace to the route's.
+func (h HTTPRoute) parents(obj metav1.Object) []gatewayRef {
+	route, ok := obj.(*gatewayv1.HTTPRoute)
+	if !ok {
+		return nil
+	}
+	var refs []gatewayRef
+	for _, parent := range route.Spec.ParentRefs {
+		if parent.Kind != nil && *parent.Kind != "Gateway" {
+			continue
+		}
+		ref := gatewayRef{gvr: h.ParentGVR(), namespace: route.GetNamespace(), name: string(parent.Name)}
+		if parent.Group != nil {
+			ref.gvr.Group = string(*parent.Group)
+		}
+		if parent.Namespace != nil {
+			ref.namespace = string(*parent.Namespace)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
 }
 
 func firstHTTPRouteHostname(route *gatewayv1.HTTPRoute) string {
@@ -118,8 +193,34 @@ func firstHTTPRoutePath(route *gatewayv1.HTTPRoute) string {
 	return ""
 }
 
-func httpRouteReferencesAnyGateway(route *gatewayv1.HTTPRoute, names []string) bool {
-	return slices.ContainsFunc(route.Spec.ParentRefs, func(p gatewayv1.ParentReference) bool {
-		return slices.Contains(names, string(p.Name))
+// httpRouteReferencesAnyGateway reports whether one of the route's
+// Gateway parentRefs passes both --gateway-name and --gateway-namespace.
+func httpRouteReferencesAnyGateway(route *gatewayv1.HTTPRoute, cfg *config.Config) bool {
+	return slices.ContainsFunc(HTTPRoute{}.parents(route), func(p gatewayRef) bool {
+		return gatewayMatches(p.namespace, p.name, cfg)
+	})
+}
+
+// httpRouteAccepted reports whether a Gateway passing --gateway-name and
+// --gateway-namespace lists the route in status.parents with Accepted=True.
+// Parents that aren't Gateways, such as mesh Services, don't count.
+func httpRouteAccepted(route *gatewayv1.HTTPRoute, cfg *config.Config) bool {
+	return slices.ContainsFunc(route.Status.Parents, func(p gatewayv1.RouteParentStatus) bool {
+		if p.ParentRef.Kind != nil && *p.ParentRef.Kind != "Gateway" {
+			return false
+		}
+		namespace := route.GetNamespace()
+		if p.ParentRef.Namespace != nil {
+			namespace = string(*p.ParentRef.Namespace)
+		}
+		return gatewayMatches(namespace, string(p.ParentRef.Name), cfg) &&
+			meta.IsStatusConditionTrue(p.Conditions, string(gatewayv1.RouteConditionAccepted))
 	})
 }
+
+// gatewayMatches applies --gateway-name and --gateway-namespace to one
+// Gateway; an empty set doesn't filter.
+func gatewayMatches(namespace, name string, cfg *config.Config) bool {
+	return (len(cfg.GatewayNames) == 0 || cfg.GatewayNames.Contains(name)) &&
+		(len(cfg.GatewayNamespaces) == 0 || cfg.GatewayNamespaces.Contains(namespace))
+}
@@ -2,10 +2,11 @@ package resources
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
-	"github.com/home-operations/gatus-sidecar/internal/k8s"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +26,21 @@ func makeRoute(name string, hostnames []gatewayv1.Hostname, parentRefs []gateway
 	}
 }
 
+// withParentStatus records gateway's Accepted condition in route's
+// status.parents, as the Gateway controller would.
+func withParentStatus(route *gatewayv1.HTTPRoute, gateway gatewayv1.ObjectName, accepted metav1.ConditionStatus) *gatewayv1.HTTPRoute {
+	route.Status.Parents = append(route.Status.Parents, gatewayv1.RouteParentStatus{
+		ParentRef:      gatewayv1.ParentReference{Name: gateway},
+		ControllerName: "example.com/gateway-controller",
+		Conditions: []metav1.Condition{{
+			Type:   string(gatewayv1.RouteConditionAccepted),
+			Status: accepted,
+			Reason: string(gatewayv1.RouteReasonAccepted),
+		}},
+	})
+	return route
+}
+
 func TestHTTPRoute_URL(t *testing.T) {
 	t.Parallel()
 	exact := gatewayv1.PathMatchExact
@@ -50,6 +66,7 @@ func TestHTTPRoute_URL(t *testing.T) {
 		{"https default", makeRoute("a", []gatewayv1.Hostname{"api.example.com"}, nil, nil), "https://api.example.com"},
 		{"http prefix preserved", makeRoute("a", []gatewayv1.Hostname{"http://api"}, nil, nil), "http://api"},
 		{"https prefix preserved", makeRoute("a", []gatewayv1.Hostname{"https://api"}, nil, nil), "https://api"},
+		{"http-prefixed hostname", makeRoute("a", []gatewayv1.Hostname{"http-test.domain.com"}, nil, nil), "https://http-test.domain.com"},
 		{"no hostnames", &gatewayv1.HTTPRoute{}, ""},
 		{"wrong type", &corev1.Pod{}, ""},
 		{"exact path appended", pathRoute("api.example.com", &exact, "/v1/health"), "https://api.example.com/v1/health"},
@@ -70,6 +87,7 @@ func TestHTTPRoute_URL(t *testing.T) {
 func TestHTTPRoute_Matches(t *testing.T) {
 	t.Parallel()
 	gw := gatewayv1.ObjectName("gw")
+	infra := gatewayv1.Namespace("infra")
 	cases := []struct {
 		name string
 		obj  metav1.Object
@@ -106,6 +124,34 @@ func TestHTTPRoute_Matches(t *testing.T) {
 			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), GatewayNames: config.StringSet{"a", "b"}},
 			want: false,
 		},
+		{
+			name: "second of several parents matches",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: "internal"}, {Name: gw, Namespace: &infra}}, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), GatewayNames: config.StringSet{"gw"}},
+			want: true,
+		},
+		{
+			name: "gateway namespace filter match",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw, Namespace: &infra}}, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), GatewayNamespaces: config.StringSet{"infra"}},
+			want: true,
+		},
+		{
+			name: "gateway namespace defaults to the route's",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}}, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), GatewayNamespaces: config.StringSet{"infra"}},
+			want: false,
+		},
+		{
+			name: "name and namespace must match the same parent",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}, {Name: "internal", Namespace: &infra}}, nil),
+			cfg: &config.Config{
+				Kinds:             autoEnabled(config.KindHTTPRoute),
+				GatewayNames:      config.StringSet{"gw"},
+				GatewayNamespaces: config.StringSet{"infra"},
+			},
+			want: false,
+		},
 		{
 			name: "no auto, annotation present",
 			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, nil, map[string]string{config.DefaultEnabledAnnotation: "true"}),
@@ -132,6 +178,58 @@ func TestHTTPRoute_Matches(t *testing.T) {
 	}
 }
 
+func TestHTTPRoute_Ready(t *testing.T) {
+	t.Parallel()
+	gw := gatewayv1.ObjectName("gw")
+	cases := []struct {
+		name string
+		obj  metav1.Object
+		cfg  *config.Config
+		want bool
+	}{
+		{
+			name: "not required",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}}, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute)},
+			want: true,
+		},
+		{
+			name: "no parent status yet",
+			obj:  makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}}, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), RequireReadyStatus: true},
+			want: false,
+		},
+		{
+			name: "not accepted",
+			obj:  withParentStatus(makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}}, nil), gw, metav1.ConditionFalse),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), RequireReadyStatus: true},
+			want: false,
+		},
+		{
+			name: "accepted",
+			obj:  withParentStatus(makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}}, nil), gw, metav1.ConditionTrue),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), RequireReadyStatus: true},
+			want: true,
+		},
+		{
+			name: "accepted only by a filtered-out gateway",
+			obj: withParentStatus(withParentStatus(
+				makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: gw}, {Name: "internal"}}, nil),
+				gw, metav1.ConditionFalse), "internal", metav1.ConditionTrue),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindHTTPRoute), GatewayNames: config.StringSet{"gw"}, RequireReadyStatus: true},
+			want: false,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (HTTPRoute{}).Ready(context.Background(), tt.obj, tt.cfg, nil); got != tt.want {
+				t.Errorf("Ready() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHTTPRoute_DefaultConditionsAndGuardHost(t *testing.T) {
 	t.Parallel()
 	if got := (HTTPRoute{}).DefaultConditions(); len(got) != 1 || got[0] != "[STATUS] == 200" {
@@ -164,29 +262,90 @@ func TestHTTPRoute_ParentAnnotations(t *testing.T) {
 	}
 
 	route := makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: "gw"}}, nil)
-	ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, k8s.NewFetcher(client))
+	ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, newClientFetcher(client))
 	if ann["parent"] != "annotation" {
 		t.Errorf("got %v", ann)
 	}
 }
 
+func TestHTTPRoute_ParentAnnotations_SeveralGateways(t *testing.T) {
+	t.Parallel()
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(gatewayGVR.GroupVersion().WithKind("Gateway"), &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(gatewayGVR.GroupVersion().WithKind("GatewayList"), &unstructured.UnstructuredList{})
+	client := fake.NewSimpleDynamicClient(scheme)
+
+	for name, annotations := range map[string]map[string]string{
+		"internal": {"shared": "internal", "only-internal": "yes"},
+		"external": {"shared": "external", "only-external": "yes"},
+	} {
+		gw := &unstructured.Unstructured{}
+		gw.SetAPIVersion("gateway.networking.k8s.io/v1")
+		gw.SetKind("Gateway")
+		gw.SetName(name)
+		gw.SetNamespace("default")
+		gw.SetAnnotations(annotations)
+		if _, err := client.Resource(gatewayGVR).Namespace("default").Create(context.Background(), gw, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("seed gateway: %v", err)
+		}
+	}
+
+	route := makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: "internal"}, {Name: "missing"}, {Name: "external"}}, nil)
+	fetcher := newClientFetcher(client)
+	if all := (HTTPRoute{}).AllParentAnnotations(context.Background(), route, fetcher); len(all) != 2 || all[0]["shared"] != "internal" || all[1]["shared"] != "external" {
+		t.Errorf("AllParentAnnotations() = %v, want internal then external", all)
+	}
+	want := map[string]string{"shared": "internal", "only-internal": "yes", "only-external": "yes"}
+	if got := (HTTPRoute{}).ParentAnnotations(context.Background(), route, fetcher); !maps.Equal(got, want) {
+		t.Errorf("ParentAnnotations() = %v, want %v", got, want)
+	}
+}
+
 func TestHTTPRoute_ParentAnnotations_NoParents(t *testing.T) {
 	t.Parallel()
 	scheme := runtime.NewScheme()
 	client := fake.NewSimpleDynamicClient(scheme)
 	route := makeRoute("r", []gatewayv1.Hostname{"x"}, nil, nil)
-	if ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, k8s.NewFetcher(client)); ann != nil {
+	if ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, newClientFetcher(client)); ann != nil {
 		t.Errorf("got %v, want nil", ann)
 	}
 }
 
+func TestHTTPRoute_ParentKeys(t *testing.T) {
+	t.Parallel()
+	other := gatewayv1.Namespace("infra")
+	kind := gatewayv1.Kind("Service")
+	group := gatewayv1.Group("example.io")
+	cases := []struct {
+		name string
+		refs []gatewayv1.ParentReference
+		want []string
+	}{
+		{"same namespace", []gatewayv1.ParentReference{{Name: "gw"}}, []string{"default/gw"}},
+		{"other namespace", []gatewayv1.ParentReference{{Name: "gw", Namespace: &other}}, []string{"infra/gw"}},
+		{"several gateways", []gatewayv1.ParentReference{{Name: "a"}, {Name: "b", Namespace: &other}}, []string{"default/a", "infra/b"}},
+		{"no parents", nil, nil},
+		{"non-gateway kind", []gatewayv1.ParentReference{{Name: "svc", Kind: &kind}}, nil},
+		{"other api group", []gatewayv1.ParentReference{{Name: "gw", Group: &group}}, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			route := makeRoute("r", []gatewayv1.Hostname{"x"}, tt.refs, nil)
+			if got := (HTTPRoute{}).ParentKeys(route); !slices.Equal(got, tt.want) {
+				t.Errorf("ParentKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestHTTPRoute_ParentAnnotations_NonGatewayKind(t *testing.T) {
 	t.Parallel()
 	scheme := runtime.NewScheme()
 	client := fake.NewSimpleDynamicClient(scheme)
 	kind := gatewayv1.Kind("Service")
 	route := makeRoute("r", []gatewayv1.Hostname{"x"}, []gatewayv1.ParentReference{{Name: "svc", Kind: &kind}}, nil)
-	if ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, k8s.NewFetcher(client)); ann != nil {
+	if ann := (HTTPRoute{}).ParentAnnotations(context.Background(), route, newClientFetcher(client)); ann != nil {
 		t.Errorf("got %v, want nil", ann)
 	}
 }
@@ -3,11 +3,15 @@ package resources
 import (
 	"context"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -33,7 +37,8 @@ type Ingress struct{}
 
 func (Ingress) GVR() schema.GroupVersionResource { return ingressGVR }
 
-func (Ingress) Prefix(cfg *config.Config) string { return cfg.Prefix(config.KindIngress) }
+func (Ingress) Prefix(cfg *config.Config) string          { return cfg.Prefix(config.KindIngress) }
+func (Ingress) Interval(cfg *config.Config) time.Duration { return cfg.Interval(config.KindIngress) }
 
 func (Ingress) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
 	return convertTo[networkingv1.Ingress](u)
@@ -50,6 +55,14 @@ func (Ingress) Matches(obj metav1.Object, cfg *config.Config) bool {
 	return matchesAnnotation(obj, cfg.AutoEnabled(config.KindIngress), cfg)
 }
 
+// Ready implements [k8s.ReadinessGate] for --require-ready-status: until the
+// controller publishes an address nothing routes to the Ingress, and the
+// status update that adds one requeues it.
+func (Ingress) Ready(_ context.Context, obj metav1.Object, cfg *config.Config, _ k8s.Fetcher) bool {
+	ing, ok := obj.(*networkingv1.Ingress)
+	return !ok || !cfg.RequireReadyStatus || len(ing.Status.LoadBalancer.Ingress) > 0
+}
+
 func (Ingress) URL(obj metav1.Object) string {
 	ing, ok := obj.(*networkingv1.Ingress)
 	if !ok {
@@ -59,7 +72,151 @@ func (Ingress) URL(obj metav1.Object) string {
 	if host == "" {
 		return ""
 	}
-	return formatURL(host, path, ingressUsesTLS(ing, host))
+	return urlutil.BuildHostURL(host, path, ingressUsesTLS(ing, host))
+}
+
+// Targets yields one target per distinct rule host under
+// --ingress-all-hosts, suffixed with the host. Otherwise it is the single
+// first-host target URL/GuardHost would produce. With
+// --ingress-internal-probe each is redirected to the ingress controller, and
+// with --ingress-default-backend an Ingress without rule hosts falls back to
+// its default backend.
+func (i Ingress) Targets(obj metav1.Object, cfg *config.Config) []k8s.Target {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil
+	}
+	var out []k8s.Target
+	if !cfg.IngressAllHosts {
+		out = []k8s.Target{{URL: i.URL(obj), GuardHost: i.GuardHost(obj)}}
+	} else {
+		for _, hp := range ingressHostPaths(ing) {
+			out = append(out, k8s.Target{
+				Suffix:    hp.host,
+				URL:       urlutil.BuildHostURL(hp.host, hp.path, ingressUsesTLS(ing, hp.host)),
+				GuardHost: hp.host,
+				Host:      hp.host,
+			})
+		}
+	}
+	if cfg.IngressInternalProbe != "" {
+		for n, t := range out {
+			if t.URL != "" {
+				out[n].HostHeader = urlutil.Hostname(t.URL)
+				out[n].URL = urlutil.SetOrigin(t.URL, cfg.IngressInternalProbe)
+			}
+		}
+	}
+	if cfg.IngressDefaultBackend && (len(out) == 0 || out[0].URL == "") {
+		if t, ok := defaultBackendTarget(ing); ok {
+			return []k8s.Target{t}
+		}
+	}
+	return out
+}
+
+// defaultBackendTarget connects to the Service behind ing's default
+// backend. Resource backends, and named ports that would need the Service
+// looked up, yield none.
+func defaultBackendTarget(ing *networkingv1.Ingress) (k8s.Target, bool) {
+	b := ing.Spec.DefaultBackend
+	if b == nil || b.Service == nil || b.Service.Port.Number == 0 {
+		return k8s.Target{}, false
+	}
+	host := b.Service.Name + "." + ing.Namespace + ".svc"
+	return k8s.Target{
+		URL:        urlutil.HostPortURL("tcp", host, int(b.Service.Port.Number)),
+		Conditions: tcpDefaultConditions,
+	}, true
+}
+
+// ResolveTargets implements [k8s.TargetResolver]: under
+// --ingress-resolve-backend each host target is followed by a TCP check of
+// the Service behind that host's first path, suffixed "backend", so a
+// failing probe tells the ingress apart from the workload behind it.
+func (Ingress) ResolveTargets(ctx context.Context, obj metav1.Object, targets []k8s.Target, cfg *config.Config, fetcher k8s.Fetcher) []k8s.Target {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok || !cfg.IngressResolveBackend {
+		return targets
+	}
+	out := make([]k8s.Target, 0, 2*len(targets))
+	for _, t := range targets {
+		out = append(out, t)
+		if t.URL == "" || t.GuardHost == "" {
+			continue
+		}
+		backend, ok := resolveIngressBackend(ctx, ing, t.GuardHost, fetcher)
+		if !ok {
+			continue
+		}
+		backend.Suffix = "backend"
+		if t.Suffix != "" {
+			backend.Suffix = t.Suffix + "-backend"
+		}
+		backend.Host = t.Host
+		out = append(out, backend)
+	}
+	return out
+}
+
+// resolveIngressBackend looks up the Service behind host's first path and
+// returns a TCP target for the port it routes to. Resource backends,
+// missing Services, unknown or non-TCP ports and unknown Service types
+// yield none; an ExternalName Service is checked at its external name.
+func resolveIngressBackend(ctx context.Context, ing *networkingv1.Ingress, host string, fetcher k8s.Fetcher) (k8s.Target, bool) {
+	backend := firstIngressServiceBackend(ing, host)
+	if backend == nil {
+		return k8s.Target{}, false
+	}
+	u := fetcher.Get(ctx, serviceGVR, ing.Namespace, backend.Name)
+	if u == nil {
+		return k8s.Target{}, false
+	}
+	converted, err := convertTo[corev1.Service](u)
+	if err != nil {
+		return k8s.Target{}, false
+	}
+	svc := converted.(*corev1.Service)
+	idx := slices.IndexFunc(svc.Spec.Ports, func(p corev1.ServicePort) bool {
+		if backend.Port.Name != "" {
+			return p.Name == backend.Port.Name
+		}
+		return p.Port == backend.Port.Number
+	})
+	if idx < 0 {
+		return k8s.Target{}, false
+	}
+	port := svc.Spec.Ports[idx]
+	if port.Protocol != "" && port.Protocol != corev1.ProtocolTCP {
+		return k8s.Target{}, false
+	}
+	var addr string
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeExternalName:
+		addr = svc.Spec.ExternalName
+	case "", corev1.ServiceTypeClusterIP, corev1.ServiceTypeNodePort, corev1.ServiceTypeLoadBalancer:
+		addr = svc.Name + "." + svc.Namespace + ".svc"
+	}
+	if addr == "" {
+		return k8s.Target{}, false
+	}
+	return k8s.Target{
+		URL:        urlutil.HostPortURL("tcp", addr, int(port.Port)),
+		Port:       strconv.Itoa(int(port.Port)),
+		Conditions: tcpDefaultConditions,
+	}, true
+}
+
+// firstIngressServiceBackend returns the Service backend of the first path
+// under the first rule for host, or nil when it routes elsewhere.
+func firstIngressServiceBackend(ing *networkingv1.Ingress, host string) *networkingv1.IngressServiceBackend {
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host != host || rule.HTTP == nil || len(rule.HTTP.Paths) == 0 {
+			continue
+		}
+		return rule.HTTP.Paths[0].Backend.Service
+	}
+	return nil
 }
 
 func (Ingress) DefaultConditions() []string { return httpDefaultConditions }
@@ -85,6 +242,22 @@ func (Ingress) ParentAnnotations(ctx context.Context, obj metav1.Object, fetcher
 	return fetcher.GetAnnotations(ctx, ingressClassGVR, "", className)
 }
 
+// ParentGVR implements [k8s.ParentResource].
+func (Ingress) ParentGVR() schema.GroupVersionResource { return ingressClassGVR }
+
+// ParentKeys implements [k8s.ParentResource]: IngressClasses are
+// cluster-scoped, so the key is the class name.
+func (Ingress) ParentKeys(obj metav1.Object) []string {
+	ing, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil
+	}
+	if class := ingressClassOf(ing); class != "" {
+		return []string{class}
+	}
+	return nil
+}
+
 // firstIngressHostAndPath returns the first non-empty hostname and the first
 // probable path under it. Path is "" when the rule has no usable path.
 func firstIngressHostAndPath(ing *networkingv1.Ingress) (string, string) {
@@ -104,6 +277,38 @@ func firstIngressHostAndPath(ing *networkingv1.Ingress) (string, string) {
 	return "", ""
 }
 
+type hostPath struct {
+	host, path string
+}
+
+// ingressHostPaths returns every distinct rule host in order, each with the
+// first probable path found across all rules for that host.
+func ingressHostPaths(ing *networkingv1.Ingress) []hostPath {
+	var out []hostPath
+	index := make(map[string]int)
+	for _, rule := range ing.Spec.Rules {
+		if rule.Host == "" {
+			continue
+		}
+		i, seen := index[rule.Host]
+		if !seen {
+			i = len(out)
+			index[rule.Host] = i
+			out = append(out, hostPath{host: rule.Host})
+		}
+		if out[i].path != "" || rule.HTTP == nil {
+			continue
+		}
+		for _, p := range rule.HTTP.Paths {
+			if isProbablePath(p.Path) {
+				out[i].path = p.Path
+				break
+			}
+		}
+	}
+	return out
+}
+
 // isProbablePath rejects empty, root, and non-rooted values
 // (ImplementationSpecific paths from some controllers can be regex-like).
 func isProbablePath(p string) bool {
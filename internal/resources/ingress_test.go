@@ -2,6 +2,8 @@ package resources
 
 import (
 	"context"
+	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
@@ -56,6 +58,7 @@ func TestIngress_URL(t *testing.T) {
 		{"https with tls", makeIngress("example.com", true, nil, nil), "https://example.com"},
 		{"already prefixed", makeIngress("http://x.com", false, nil, nil), "http://x.com"},
 		{"http-prefix-not-url", makeIngress("http-debug.com", false, nil, nil), "http://http-debug.com"},
+		{"http-prefixed hostname with tls", makeIngress("http-test.domain.com", true, nil, nil), "https://http-test.domain.com"},
 		{"no rules", &networkingv1.Ingress{}, ""},
 		{"wrong type", &corev1.Pod{}, ""},
 		{
@@ -94,6 +97,13 @@ func TestIngress_URL(t *testing.T) {
 	}
 }
 
+// withLoadBalancer publishes an address in ing's status, as the ingress
+// controller would once it serves the Ingress.
+func withLoadBalancer(ing *networkingv1.Ingress) *networkingv1.Ingress {
+	ing.Status.LoadBalancer.Ingress = []networkingv1.IngressLoadBalancerIngress{{IP: "192.0.2.10"}}
+	return ing
+}
+
 func TestIngress_Matches(t *testing.T) {
 	t.Parallel()
 	nginx := "nginx"
@@ -167,6 +177,43 @@ func TestIngress_Matches(t *testing.T) {
 	}
 }
 
+func TestIngress_Ready(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name string
+		obj  metav1.Object
+		cfg  *config.Config
+		want bool
+	}{
+		{
+			name: "not required",
+			obj:  makeIngress("x", false, nil, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindIngress)},
+			want: true,
+		},
+		{
+			name: "no load-balancer address",
+			obj:  makeIngress("x", false, nil, nil),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindIngress), RequireReadyStatus: true},
+			want: false,
+		},
+		{
+			name: "load-balancer address",
+			obj:  withLoadBalancer(makeIngress("x", false, nil, nil)),
+			cfg:  &config.Config{Kinds: autoEnabled(config.KindIngress), RequireReadyStatus: true},
+			want: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (Ingress{}).Ready(context.Background(), tt.obj, tt.cfg, nil); got != tt.want {
+				t.Errorf("Ready() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIngress_DefaultConditions(t *testing.T) {
 	t.Parallel()
 	got := (Ingress{}).DefaultConditions()
@@ -230,19 +277,269 @@ func TestIngress_ParentAnnotations(t *testing.T) {
 	}
 
 	ing := makeIngress("x", false, &className, nil)
-	ann := (Ingress{}).ParentAnnotations(context.Background(), ing, k8s.NewFetcher(client))
+	ann := (Ingress{}).ParentAnnotations(context.Background(), ing, newClientFetcher(client))
 	if ann["parent"] != "annotation" {
 		t.Errorf("ParentAnnotations = %v, want {parent: annotation}", ann)
 	}
 }
 
+func TestIngress_ParentKeys(t *testing.T) {
+	t.Parallel()
+	class := "nginx"
+	if got := (Ingress{}).ParentKeys(makeIngress("x", false, &class, nil)); !slices.Equal(got, []string{"nginx"}) {
+		t.Errorf("ParentKeys() = %q, want [nginx]", got)
+	}
+	if got := (Ingress{}).ParentKeys(makeIngress("x", false, nil, nil)); got != nil {
+		t.Errorf("ParentKeys(no class) = %q, want nil", got)
+	}
+}
+
 func TestIngress_ParentAnnotations_Missing(t *testing.T) {
 	t.Parallel()
 	scheme := runtime.NewScheme()
 	client := fake.NewSimpleDynamicClient(scheme)
 	ing := makeIngress("x", false, nil, nil)
 
-	if ann := (Ingress{}).ParentAnnotations(context.Background(), ing, k8s.NewFetcher(client)); ann != nil {
+	if ann := (Ingress{}).ParentAnnotations(context.Background(), ing, newClientFetcher(client)); ann != nil {
 		t.Errorf("ParentAnnotations(no class) = %v, want nil", ann)
 	}
 }
+
+func TestIngress_Targets(t *testing.T) {
+	t.Parallel()
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ing", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"b.example.com"}}},
+			Rules: []networkingv1.IngressRule{
+				{Host: "a.example.com"},
+				{Host: "b.example.com", IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{Path: "/api"}}},
+				}},
+				{Host: ""},
+				{Host: "a.example.com", IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{Path: "/late"}}},
+				}},
+				{Host: "c.example.com"},
+			},
+		},
+	}
+
+	t.Run("all hosts", func(t *testing.T) {
+		t.Parallel()
+		got := (Ingress{}).Targets(ing, &config.Config{IngressAllHosts: true})
+		want := []k8s.Target{
+			{Suffix: "a.example.com", URL: "http://a.example.com/late", GuardHost: "a.example.com", Host: "a.example.com"},
+			{Suffix: "b.example.com", URL: "https://b.example.com/api", GuardHost: "b.example.com", Host: "b.example.com"},
+			{Suffix: "c.example.com", URL: "http://c.example.com", GuardHost: "c.example.com", Host: "c.example.com"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+	})
+
+	t.Run("first host by default", func(t *testing.T) {
+		t.Parallel()
+		got := (Ingress{}).Targets(ing, &config.Config{})
+		want := []k8s.Target{{URL: "http://a.example.com", GuardHost: "a.example.com"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("internal probe", func(t *testing.T) {
+		t.Parallel()
+		got := (Ingress{}).Targets(ing, &config.Config{IngressAllHosts: true, IngressInternalProbe: "http://ingress-nginx.ingress.svc"})
+		want := []k8s.Target{
+			{Suffix: "a.example.com", URL: "http://ingress-nginx.ingress.svc/late", GuardHost: "a.example.com", Host: "a.example.com", HostHeader: "a.example.com"},
+			{Suffix: "b.example.com", URL: "http://ingress-nginx.ingress.svc/api", GuardHost: "b.example.com", Host: "b.example.com", HostHeader: "b.example.com"},
+			{Suffix: "c.example.com", URL: "http://ingress-nginx.ingress.svc", GuardHost: "c.example.com", Host: "c.example.com", HostHeader: "c.example.com"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		t.Parallel()
+		if got := (Ingress{}).Targets(&corev1.Pod{}, &config.Config{IngressAllHosts: true}); got != nil {
+			t.Errorf("Targets(non-ingress) = %+v, want nil", got)
+		}
+	})
+}
+
+func TestIngress_DefaultBackend(t *testing.T) {
+	t.Parallel()
+	withBackend := func(backend *networkingv1.IngressBackend) *networkingv1.Ingress {
+		ing := makeIngress("", false, nil, nil)
+		ing.Spec.Rules = nil
+		ing.Spec.DefaultBackend = backend
+		return ing
+	}
+	apiGroup := "example.com"
+	cases := []struct {
+		name string
+		ing  *networkingv1.Ingress
+		cfg  *config.Config
+		want []k8s.Target
+	}{
+		{
+			name: "service backend",
+			ing: withBackend(&networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "catch-all", Port: networkingv1.ServiceBackendPort{Number: 8080},
+			}}),
+			cfg:  &config.Config{IngressDefaultBackend: true},
+			want: []k8s.Target{{URL: "tcp://catch-all.default.svc:8080", Conditions: []string{conditionConnected}}},
+		},
+		{
+			name: "service backend with all hosts",
+			ing: withBackend(&networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "catch-all", Port: networkingv1.ServiceBackendPort{Number: 80},
+			}}),
+			cfg:  &config.Config{IngressDefaultBackend: true, IngressAllHosts: true},
+			want: []k8s.Target{{URL: "tcp://catch-all.default.svc:80", Conditions: []string{conditionConnected}}},
+		},
+		{
+			name: "flag unset",
+			ing: withBackend(&networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "catch-all", Port: networkingv1.ServiceBackendPort{Number: 8080},
+			}}),
+			cfg:  &config.Config{},
+			want: []k8s.Target{{}},
+		},
+		{
+			name: "named port",
+			ing: withBackend(&networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "catch-all", Port: networkingv1.ServiceBackendPort{Name: "http"},
+			}}),
+			cfg:  &config.Config{IngressDefaultBackend: true},
+			want: []k8s.Target{{}},
+		},
+		{
+			name: "resource backend",
+			ing: withBackend(&networkingv1.IngressBackend{Resource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup, Kind: "StorageBucket", Name: "static",
+			}}),
+			cfg:  &config.Config{IngressDefaultBackend: true},
+			want: []k8s.Target{{}},
+		},
+		{
+			name: "rule hosts win",
+			ing: func() *networkingv1.Ingress {
+				ing := makeIngress("app.example.com", false, nil, nil)
+				ing.Spec.DefaultBackend = &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+					Name: "catch-all", Port: networkingv1.ServiceBackendPort{Number: 8080},
+				}}
+				return ing
+			}(),
+			cfg:  &config.Config{IngressDefaultBackend: true},
+			want: []k8s.Target{{URL: "http://app.example.com", GuardHost: "app.example.com"}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (Ingress{}).Targets(tt.ing, tt.cfg); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Targets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngress_ResolveTargets(t *testing.T) {
+	t.Parallel()
+	backendIngress := func(port networkingv1.ServiceBackendPort) *networkingv1.Ingress {
+		ing := makeIngressWithPaths("app.example.com", true, nil, nil, []string{"/"})
+		ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service = &networkingv1.IngressServiceBackend{Name: "web", Port: port}
+		return ing
+	}
+	service := func(typ corev1.ServiceType, ports ...corev1.ServicePort) *unstructured.Unstructured {
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Type: typ, Ports: ports, ExternalName: "web.example.net"},
+		}
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(svc)
+		if err != nil {
+			t.Fatalf("ToUnstructured: %v", err)
+		}
+		obj := &unstructured.Unstructured{Object: u}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("Service")
+		return obj
+	}
+	httpPort := corev1.ServicePort{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP}
+	cfg := &config.Config{IngressResolveBackend: true}
+	frontend := k8s.Target{URL: "https://app.example.com", GuardHost: "app.example.com"}
+
+	cases := []struct {
+		name string
+		ing  *networkingv1.Ingress
+		svc  *unstructured.Unstructured
+		cfg  *config.Config
+		want []k8s.Target
+	}{
+		{
+			name: "port number",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 8080}),
+			svc:  service(corev1.ServiceTypeClusterIP, httpPort),
+			cfg:  cfg,
+			want: []k8s.Target{frontend, {Suffix: "backend", URL: "tcp://web.default.svc:8080", Port: "8080", Conditions: []string{conditionConnected}}},
+		},
+		{
+			name: "port name",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Name: "http"}),
+			svc:  service(corev1.ServiceTypeLoadBalancer, corev1.ServicePort{Name: "metrics", Port: 9090}, httpPort),
+			cfg:  cfg,
+			want: []k8s.Target{frontend, {Suffix: "backend", URL: "tcp://web.default.svc:8080", Port: "8080", Conditions: []string{conditionConnected}}},
+		},
+		{
+			name: "external name",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 8080}),
+			svc:  service(corev1.ServiceTypeExternalName, httpPort),
+			cfg:  cfg,
+			want: []k8s.Target{frontend, {Suffix: "backend", URL: "tcp://web.example.net:8080", Port: "8080", Conditions: []string{conditionConnected}}},
+		},
+		{
+			name: "flag unset",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 8080}),
+			svc:  service(corev1.ServiceTypeClusterIP, httpPort),
+			cfg:  &config.Config{},
+			want: []k8s.Target{frontend},
+		},
+		{
+			name: "unknown port",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 9999}),
+			svc:  service(corev1.ServiceTypeClusterIP, httpPort),
+			cfg:  cfg,
+			want: []k8s.Target{frontend},
+		},
+		{
+			name: "udp port",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 8080}),
+			svc:  service(corev1.ServiceTypeClusterIP, corev1.ServicePort{Port: 8080, Protocol: corev1.ProtocolUDP}),
+			cfg:  cfg,
+			want: []k8s.Target{frontend},
+		},
+		{
+			name: "missing service",
+			ing:  backendIngress(networkingv1.ServiceBackendPort{Number: 8080}),
+			cfg:  cfg,
+			want: []k8s.Target{frontend},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var objs []runtime.Object
+			if tt.svc != nil {
+				objs = append(objs, tt.svc)
+			}
+			fetcher := newClientFetcher(fake.NewSimpleDynamicClient(runtime.NewScheme(), objs...))
+			targets := (Ingress{}).Targets(tt.ing, tt.cfg)
+			got := (Ingress{}).ResolveTargets(context.Background(), tt.ing, targets, tt.cfg, fetcher)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveTargets() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,11 @@ package resources
 import (
 	"context"
 	"regexp"
+	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -29,6 +31,9 @@ type IngressRoute struct{}
 func (IngressRoute) GVR() schema.GroupVersionResource { return ingressRouteGVR }
 
 func (IngressRoute) Prefix(cfg *config.Config) string { return cfg.Prefix(config.KindIngressRoute) }
+func (IngressRoute) Interval(cfg *config.Config) time.Duration {
+	return cfg.Interval(config.KindIngressRoute)
+}
 
 func (IngressRoute) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
 	return u, nil
@@ -50,7 +55,7 @@ func (IngressRoute) URL(obj metav1.Object) string {
 	if host == "" {
 		return ""
 	}
-	return formatURL(host, path, ingressRouteHasTLS(u))
+	return urlutil.BuildHostURL(host, path, ingressRouteHasTLS(u))
 }
 
 func (IngressRoute) DefaultConditions() []string { return httpDefaultConditions }
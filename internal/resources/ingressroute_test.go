@@ -36,6 +36,7 @@ func TestIngressRoute_URL(t *testing.T) {
 	}{
 		{"http", makeIngressRoute("example.com", false), "http://example.com"},
 		{"https", makeIngressRoute("secure.example.com", true), "https://secure.example.com"},
+		{"http-prefixed hostname", makeIngressRoute("http-test.domain.com", true), "https://http-test.domain.com"},
 		{"empty unstructured", &unstructured.Unstructured{}, ""},
 		{
 			name: "host with PathPrefix",
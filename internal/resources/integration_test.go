@@ -0,0 +1,541 @@
+package resources
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// harnessKinds are the list kinds the fake client needs to serve the
+// informers and parent lookups exercised below.
+var harnessKinds = map[schema.GroupVersionResource]string{
+	ingressGVR:       "IngressList",
+	ingressClassGVR:  "IngressClassList",
+	httpRouteGVR:     "HTTPRouteList",
+	gatewayGVR:       "GatewayList",
+	serviceGVR:       "ServiceList",
+	endpointSliceGVR: "EndpointSliceList",
+}
+
+// toUnstructured converts a typed object for seeding the fake client.
+func toUnstructured(t *testing.T, obj runtime.Object, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	t.Helper()
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatalf("ToUnstructured: %v", err)
+	}
+	u := &unstructured.Unstructured{Object: data}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+func newHarnessClient(t *testing.T, objs ...*unstructured.Unstructured) dynamic.Interface {
+	t.Helper()
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), harnessKinds)
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		var gvr schema.GroupVersionResource
+		for r, list := range harnessKinds {
+			if r.Group == gvk.Group && r.Version == gvk.Version && list == gvk.Kind+"List" {
+				gvr = r
+			}
+		}
+		if _, err := client.Resource(gvr).Namespace(obj.GetNamespace()).Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("seed %s %s: %v", gvk.Kind, obj.GetName(), err)
+		}
+	}
+	return client
+}
+
+// clientFetcher is a k8s.Fetcher reading straight from client, uncached,
+// for tests of a Resource's lookups.
+type clientFetcher struct{ client dynamic.Interface }
+
+func newClientFetcher(client dynamic.Interface) k8s.Fetcher { return clientFetcher{client} }
+
+func (f clientFetcher) resource(gvr schema.GroupVersionResource, namespace string) dynamic.ResourceInterface {
+	if namespace == "" {
+		return f.client.Resource(gvr)
+	}
+	return f.client.Resource(gvr).Namespace(namespace)
+}
+
+func (f clientFetcher) GetAnnotations(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) map[string]string {
+	if obj := f.Get(ctx, gvr, namespace, name); obj != nil {
+		return obj.GetAnnotations()
+	}
+	return nil
+}
+
+func (f clientFetcher) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) *unstructured.Unstructured {
+	obj, err := f.resource(gvr, namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+	return obj
+}
+
+func (f clientFetcher) List(ctx context.Context, gvr schema.GroupVersionResource, namespace string, selector labels.Selector) ([]*unstructured.Unstructured, error) {
+	list, err := f.resource(gvr, namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		items[i] = &list.Items[i]
+	}
+	return items, nil
+}
+
+// generate runs a --once controller for r against client and returns the
+// endpoints written to the output file.
+func generate(t *testing.T, args []string, r k8s.Resource, client dynamic.Interface) []map[string]any {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), "out.yaml")
+	cfg, err := config.Load("test", append(args, "--once", "--output="+out), io.Discard)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	writer := gatus.NewWriter(cfg.Output)
+	if err := k8s.NewController(cfg, r, writer, client).Run(t.Context()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, err := writer.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var doc struct {
+		Endpoints []map[string]any `yaml:"endpoints"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return doc.Endpoints
+}
+
+func TestIntegration_IngressInheritsIngressClassTemplate(t *testing.T) {
+	t.Parallel()
+	class := "nginx"
+	parent := &unstructured.Unstructured{}
+	parent.SetGroupVersionKind(ingressClassGVR.GroupVersion().WithKind("IngressClass"))
+	parent.SetName(class)
+	parent.SetAnnotations(map[string]string{config.DefaultTemplateAnnotation: "group: edge\ninterval: 5m\n"})
+
+	annotated := makeIngressWithPaths("app.example.com", true, &class,
+		map[string]string{config.DefaultTemplateAnnotation: "interval: 30s\n"}, []string{"/healthz"})
+	ignored := makeIngress("other.example.com", false, nil, nil)
+	ignored.Name = "ignored"
+	ingressGVK := ingressGVR.GroupVersion().WithKind("Ingress")
+
+	client := newHarnessClient(t, parent,
+		toUnstructured(t, annotated, ingressGVK),
+		toUnstructured(t, ignored, ingressGVK))
+	endpoints := generate(t, nil, Ingress{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected only the annotated Ingress, got %v", endpoints)
+	}
+	e := endpoints[0]
+	for key, want := range map[string]any{
+		"name":       "ing",
+		"url":        "https://app.example.com/healthz",
+		"group":      "edge",
+		"interval":   "30s",
+		"managed-by": gatus.DefaultManagedBy,
+	} {
+		if e[key] != want {
+			t.Errorf("%s = %v, want %v", key, e[key], want)
+		}
+	}
+	if conds, _ := e["conditions"].([]any); len(conds) != 1 || conds[0] != conditionHTTPOK {
+		t.Errorf("conditions = %v", e["conditions"])
+	}
+}
+
+func TestIntegration_HTTPRouteGuardedByGateway(t *testing.T) {
+	t.Parallel()
+	gw := &unstructured.Unstructured{}
+	gw.SetGroupVersionKind(gatewayGVR.GroupVersion().WithKind("Gateway"))
+	gw.SetNamespace("default")
+	gw.SetName("external")
+	gw.SetAnnotations(map[string]string{config.DefaultTemplateAnnotation: "guarded: true\n"})
+
+	route := makeRoute("web", []gatewayv1.Hostname{"web.example.com"},
+		[]gatewayv1.ParentReference{{Name: "external"}}, nil)
+	client := newHarnessClient(t, gw,
+		toUnstructured(t, route, httpRouteGVR.GroupVersion().WithKind("HTTPRoute")))
+	endpoints := generate(t, []string{"--auto-httproute"}, HTTPRoute{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", endpoints)
+	}
+	dns, _ := endpoints[0]["dns"].(map[string]any)
+	if dns["query-name"] != "web.example.com" {
+		t.Errorf("expected a guarded DNS probe for web.example.com, got %v", endpoints[0])
+	}
+}
+
+func TestIntegration_RequireReadyStatus(t *testing.T) {
+	t.Parallel()
+	routeGVK := httpRouteGVR.GroupVersion().WithKind("HTTPRoute")
+	parents := []gatewayv1.ParentReference{{Name: "external"}}
+	ready := withParentStatus(makeRoute("ready", []gatewayv1.Hostname{"ready.example.com"}, parents, nil),
+		"external", metav1.ConditionTrue)
+	rejected := withParentStatus(makeRoute("rejected", []gatewayv1.Hostname{"rejected.example.com"}, parents, nil),
+		"external", metav1.ConditionFalse)
+	pending := makeRoute("pending", []gatewayv1.Hostname{"pending.example.com"}, parents, nil)
+
+	client := newHarnessClient(t,
+		toUnstructured(t, ready, routeGVK),
+		toUnstructured(t, rejected, routeGVK),
+		toUnstructured(t, pending, routeGVK))
+
+	if endpoints := generate(t, []string{"--auto-httproute"}, HTTPRoute{}, client); len(endpoints) != 3 {
+		t.Errorf("without --require-ready-status got %d endpoints, want 3", len(endpoints))
+	}
+	endpoints := generate(t, []string{"--auto-httproute", "--require-ready-status"}, HTTPRoute{}, client)
+	if len(endpoints) != 1 || endpoints[0]["name"] != "ready" {
+		t.Errorf("with --require-ready-status got %v, want only the accepted route", endpoints)
+	}
+}
+
+func TestIntegration_RequireBackends(t *testing.T) {
+	t.Parallel()
+	serviceGVK := serviceGVR.GroupVersion().WithKind("Service")
+	sliceGVK := endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")
+	unready := false
+	served := makeSlice("web", nil, podEndpoint("web-0", "10.0.0.1", nil))
+	idle := makeSlice("idle", nil, podEndpoint("idle-0", "10.0.0.2", &unready))
+
+	client := newHarnessClient(t,
+		toUnstructured(t, makeService("web", "db", 80, corev1.ProtocolTCP), serviceGVK),
+		toUnstructured(t, makeService("idle", "db", 80, corev1.ProtocolTCP), serviceGVK),
+		toUnstructured(t, makeService("empty", "db", 80, corev1.ProtocolTCP), serviceGVK),
+		toUnstructured(t, served, sliceGVK),
+		toUnstructured(t, idle, sliceGVK))
+
+	if endpoints := generate(t, []string{"--auto-service"}, Service{}, client); len(endpoints) != 3 {
+		t.Errorf("without --require-backends got %d endpoints, want 3", len(endpoints))
+	}
+	endpoints := generate(t, []string{"--auto-service", "--require-backends"}, Service{}, client)
+	if len(endpoints) != 1 || endpoints[0]["name"] != "web" {
+		t.Errorf("with --require-backends got %v, want only the Service with a ready backend", endpoints)
+	}
+}
+
+func TestIntegration_RequireBackendsFollowsSlices(t *testing.T) {
+	t.Parallel()
+	serviceGVK := serviceGVR.GroupVersion().WithKind("Service")
+	sliceGVK := endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")
+	unready := false
+	slice := makeSlice("web", nil, podEndpoint("web-0", "10.0.0.1", &unready))
+	client := newHarnessClient(t,
+		toUnstructured(t, makeService("web", "db", 80, corev1.ProtocolTCP), serviceGVK),
+		toUnstructured(t, slice, sliceGVK))
+
+	out := filepath.Join(t.TempDir(), "out.yaml")
+	cfg, err := config.Load("test", []string{"--auto-service", "--require-backends", "--output=" + out}, io.Discard)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	c := k8s.NewController(cfg, Service{}, gatus.NewWriter(cfg.Output), client)
+	go func() { _ = c.Run(t.Context()) }()
+
+	// count polls the output file, which doesn't exist until the first flush.
+	count := func() int {
+		data, err := os.ReadFile(out)
+		if err != nil {
+			return -1
+		}
+		var doc struct {
+			Endpoints []map[string]any `yaml:"endpoints"`
+		}
+		if yaml.Unmarshal(data, &doc) != nil {
+			return -1
+		}
+		return len(doc.Endpoints)
+	}
+	waitCount := func(want int, why string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for count() != want && time.Now().Before(deadline) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if got := count(); got != want {
+			t.Fatalf("%s: got %d endpoints, want %d", why, got, want)
+		}
+	}
+	waitCount(0, "no ready backend")
+
+	// No --resync-interval and no Service change: the slice event alone
+	// must bring the endpoint in, and take it out again.
+	slice.Endpoints[0].Conditions.Ready = nil
+	sliceClient := client.Resource(endpointSliceGVR).Namespace(slice.Namespace)
+	if _, err := sliceClient.Update(t.Context(), toUnstructured(t, slice, sliceGVK), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update slice: %v", err)
+	}
+	waitCount(1, "backend became ready")
+	if err := sliceClient.Delete(t.Context(), slice.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete slice: %v", err)
+	}
+	waitCount(0, "backend went away")
+}
+
+func TestIntegration_UDPDNSServiceGetsDNSCheck(t *testing.T) {
+	t.Parallel()
+	svc := makeService("kube-dns", "kube-system", 53, corev1.ProtocolUDP)
+	svc.Spec.ClusterIP = "10.96.0.10"
+	client := newHarnessClient(t, toUnstructured(t, svc, serviceGVR.GroupVersion().WithKind("Service")))
+	endpoints := generate(t, []string{"--auto-service"}, Service{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", endpoints)
+	}
+	e := endpoints[0]
+	dns, _ := e["dns"].(map[string]any)
+	if e["url"] != "10.96.0.10" || dns["query-name"] != config.DefaultServiceDNSQuery {
+		t.Errorf("expected a DNS query against the cluster IP, got %v", e)
+	}
+	if conds, _ := e["conditions"].([]any); len(conds) != 1 || conds[0] != gatus.DNSNoErrorCondition {
+		t.Errorf("conditions = %v", e["conditions"])
+	}
+}
+
+func TestIntegration_ServiceConditionsPerProtocol(t *testing.T) {
+	t.Parallel()
+	tcp := makeService("web", "default", 8080, corev1.ProtocolTCP)
+	udp := makeService("syslog", "default", 514, corev1.ProtocolUDP)
+	client := newHarnessClient(t,
+		toUnstructured(t, tcp, serviceGVR.GroupVersion().WithKind("Service")),
+		toUnstructured(t, udp, serviceGVR.GroupVersion().WithKind("Service")))
+	endpoints := generate(t, []string{"--auto-service", "--udp-condition=[RESPONSE_TIME] < 500"}, Service{}, client)
+
+	want := map[string][]any{
+		"web":    {"[CONNECTED] == true"},
+		"syslog": {"[RESPONSE_TIME] < 500"},
+	}
+	if len(endpoints) != len(want) {
+		t.Fatalf("expected %d endpoints, got %v", len(want), endpoints)
+	}
+	for _, e := range endpoints {
+		name, _ := e["name"].(string)
+		if !reflect.DeepEqual(e["conditions"], want[name]) {
+			t.Errorf("%s conditions = %v, want %v", name, e["conditions"], want[name])
+		}
+	}
+}
+
+func TestIntegration_PerKindInterval(t *testing.T) {
+	t.Parallel()
+	svc := makeService("web", "default", 8080, corev1.ProtocolTCP)
+	ing := makeIngress("app.example.com", true, nil, nil)
+	client := newHarnessClient(t,
+		toUnstructured(t, svc, serviceGVR.GroupVersion().WithKind("Service")),
+		toUnstructured(t, ing, ingressGVR.GroupVersion().WithKind("Ingress")))
+	args := []string{"--auto-service", "--auto-ingress", "--default-interval=2m", "--service-interval=30s", "--ingress-interval=5m"}
+
+	for _, tt := range []struct {
+		r    k8s.Resource
+		want string
+	}{
+		{Service{}, "30s"},
+		{Ingress{}, "5m0s"},
+	} {
+		endpoints := generate(t, args, tt.r, client)
+		if len(endpoints) != 1 || endpoints[0]["interval"] != tt.want {
+			t.Errorf("%s: expected one endpoint with interval %s, got %v", tt.r.GVR().Resource, tt.want, endpoints)
+		}
+	}
+}
+
+func TestIntegration_IngressWithoutHostUsesTemplateURL(t *testing.T) {
+	t.Parallel()
+	ingressGVK := ingressGVR.GroupVersion().WithKind("Ingress")
+	pending := makeIngress("", false, nil,
+		map[string]string{config.DefaultTemplateAnnotation: "url: https://pending.example.com/healthz\n"})
+	bare := makeIngress("", false, nil, map[string]string{config.DefaultEnabledAnnotation: "true"})
+	bare.Name = "bare"
+
+	client := newHarnessClient(t, toUnstructured(t, pending, ingressGVK), toUnstructured(t, bare, ingressGVK))
+	endpoints := generate(t, nil, Ingress{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected only the Ingress whose template sets url, got %v", endpoints)
+	}
+	if e := endpoints[0]; e["name"] != "ing" || e["url"] != "https://pending.example.com/healthz" {
+		t.Errorf("endpoint = %v", e)
+	}
+}
+
+func TestIntegration_IngressInternalProbe(t *testing.T) {
+	t.Parallel()
+	ing := makeIngressWithPaths("app.example.com", true, nil, map[string]string{config.DefaultEnabledAnnotation: "true"}, []string{"/healthz"})
+	client := newHarnessClient(t, toUnstructured(t, ing, ingressGVR.GroupVersion().WithKind("Ingress")))
+	endpoints := generate(t, []string{
+		"--ingress-internal-probe=http://ingress-nginx-controller.ingress-nginx.svc:8080",
+		"--default-headers=X-Probe: gatus",
+	}, Ingress{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", endpoints)
+	}
+	e := endpoints[0]
+	if e["url"] != "http://ingress-nginx-controller.ingress-nginx.svc:8080/healthz" {
+		t.Errorf("url = %v", e["url"])
+	}
+	want := map[string]any{"Host": "app.example.com", "X-Probe": "gatus"}
+	if !reflect.DeepEqual(e["headers"], want) {
+		t.Errorf("headers = %v, want %v", e["headers"], want)
+	}
+}
+
+func TestIntegration_IngressInternalProbeKeepsOriginScheme(t *testing.T) {
+	t.Parallel()
+	ing := makeIngressWithPaths("app.example.com", false, nil, map[string]string{
+		config.DefaultEnabledAnnotation: "true",
+		config.DefaultSchemeAnnotation:  "https",
+	}, []string{"/healthz"})
+	client := newHarnessClient(t, toUnstructured(t, ing, ingressGVR.GroupVersion().WithKind("Ingress")))
+	endpoints := generate(t, []string{"--ingress-internal-probe=http://ingress-nginx-controller.ingress-nginx.svc"}, Ingress{}, client)
+
+	if len(endpoints) != 1 {
+		t.Fatalf("expected 1 endpoint, got %v", endpoints)
+	}
+	// The scheme annotation is about app.example.com, not the plain-http
+	// controller Service the probe is sent to.
+	if got := endpoints[0]["url"]; got != "http://ingress-nginx-controller.ingress-nginx.svc/healthz" {
+		t.Errorf("url = %v", got)
+	}
+}
+
+func TestIntegration_IngressResolveBackend(t *testing.T) {
+	t.Parallel()
+	ing := makeIngressWithPaths("app.example.com", true, nil, map[string]string{config.DefaultEnabledAnnotation: "true"}, []string{"/"})
+	ing.Spec.Rules[0].HTTP.Paths[0].Backend.Service = &networkingv1.IngressServiceBackend{
+		Name: "web", Port: networkingv1.ServiceBackendPort{Name: "http"},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "http", Port: 8080}}},
+	}
+	client := newHarnessClient(t,
+		toUnstructured(t, ing, ingressGVR.GroupVersion().WithKind("Ingress")),
+		toUnstructured(t, svc, serviceGVR.GroupVersion().WithKind("Service")),
+	)
+	endpoints := generate(t, []string{"--ingress-resolve-backend"}, Ingress{}, client)
+
+	if len(endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints, got %v", endpoints)
+	}
+	byName := make(map[string]map[string]any, len(endpoints))
+	for _, e := range endpoints {
+		byName[e["name"].(string)] = e
+	}
+	backend, ok := byName["ing-backend"]
+	if !ok {
+		t.Fatalf("no ing-backend endpoint in %v", endpoints)
+	}
+	if backend["url"] != "tcp://web.default.svc:8080" {
+		t.Errorf("backend url = %v", backend["url"])
+	}
+	if want := []any{"[CONNECTED] == true"}; !reflect.DeepEqual(backend["conditions"], want) {
+		t.Errorf("backend conditions = %v, want %v", backend["conditions"], want)
+	}
+	if byName["ing"]["url"] != "https://app.example.com" {
+		t.Errorf("frontend url = %v", byName["ing"]["url"])
+	}
+}
+
+func TestIntegration_GatewayAllAddressesCleanup(t *testing.T) {
+	t.Parallel()
+	ipType := gatewayv1.IPAddressType
+	gw := makeGateway("edge", []gatewayv1.GatewayStatusAddress{
+		{Type: &ipType, Value: "10.0.0.1"},
+		{Type: &ipType, Value: "10.0.0.2"},
+	},
+		gatewayv1.Listener{Name: "http", Port: 80, Protocol: gatewayv1.HTTPProtocolType},
+		gatewayv1.Listener{Name: "https", Port: 443, Protocol: gatewayv1.HTTPSProtocolType},
+	)
+	gatewayGVK := gatewayGVR.GroupVersion().WithKind("Gateway")
+	client := newHarnessClient(t, toUnstructured(t, gw, gatewayGVK))
+
+	out := filepath.Join(t.TempDir(), "out.yaml")
+	cfg, err := config.Load("test", []string{"--auto-gateway", "--gateway-all-addresses", "--output=" + out}, io.Discard)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	c := k8s.NewController(cfg, Gateway{}, gatus.NewWriter(cfg.Output), client)
+	go func() { _ = c.Run(t.Context()) }()
+
+	// names polls the output file, which doesn't exist until the first flush.
+	names := func() []string {
+		data, err := os.ReadFile(out)
+		if err != nil {
+			return nil
+		}
+		var doc struct {
+			Endpoints []map[string]any `yaml:"endpoints"`
+		}
+		if yaml.Unmarshal(data, &doc) != nil {
+			return nil
+		}
+		var got []string
+		for _, e := range doc.Endpoints {
+			got = append(got, e["name"].(string)+" "+e["url"].(string))
+		}
+		slices.Sort(got)
+		return got
+	}
+	waitNames := func(want []string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !slices.Equal(names(), want) && time.Now().Before(deadline) {
+			time.Sleep(20 * time.Millisecond)
+		}
+		if got := names(); !slices.Equal(got, want) {
+			t.Fatalf("endpoints = %v, want %v", got, want)
+		}
+	}
+
+	waitNames([]string{
+		"edge-http-10.0.0.1 tcp://10.0.0.1:80",
+		"edge-http-10.0.0.2 tcp://10.0.0.2:80",
+		"edge-https-10.0.0.1 tcp://10.0.0.1:443",
+		"edge-https-10.0.0.2 tcp://10.0.0.2:443",
+	})
+
+	// The second load balancer goes away: its endpoints must go with it.
+	gw.Status.Addresses = gw.Status.Addresses[:1]
+	if _, err := client.Resource(gatewayGVR).Namespace(gw.Namespace).Update(t.Context(), toUnstructured(t, gw, gatewayGVK), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update gateway: %v", err)
+	}
+	waitNames([]string{
+		"edge-http-10.0.0.1 tcp://10.0.0.1:80",
+		"edge-https-10.0.0.1 tcp://10.0.0.1:443",
+	})
+}
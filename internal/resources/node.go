@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var nodeGVR = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "nodes"}
+
+// UsesNodeAddress reports whether r probes NodePorts, so needs
+// --service-nodeport-probe=auto resolved by [ResolveNodeAddress] first.
+func UsesNodeAddress(r k8s.Resource) bool {
+	return r.GVR() == serviceGVR
+}
+
+// nodeAddressPreference orders the address types a NodePort probe from
+// inside the cluster is most likely to reach.
+var nodeAddressPreference = []corev1.NodeAddressType{
+	corev1.NodeInternalIP,
+	corev1.NodeExternalIP,
+	corev1.NodeHostName,
+}
+
+// ResolveNodeAddress returns the first Node's address for
+// --service-nodeport-probe=auto, preferring its InternalIP. It fails when
+// Nodes can't be listed or none has an address, since every NodePort probe
+// would be wrong otherwise.
+func ResolveNodeAddress(ctx context.Context, client dynamic.Interface) (string, error) {
+	list, err := client.Resource(nodeGVR).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("list nodes: %w", err)
+	}
+	if len(list.Items) == 0 {
+		return "", errors.New("no nodes found")
+	}
+	var node corev1.Node
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.Items[0].Object, &node); err != nil {
+		return "", fmt.Errorf("convert node: %w", err)
+	}
+	for _, typ := range nodeAddressPreference {
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == typ && addr.Address != "" {
+				return addr.Address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("node %s has no address", node.Name)
+}
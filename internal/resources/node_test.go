@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestResolveNodeAddress(t *testing.T) {
+	t.Parallel()
+	node := func(addrs ...corev1.NodeAddress) runtime.Object {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Status: corev1.NodeStatus{Addresses: addrs}}
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(n)
+		if err != nil {
+			t.Fatalf("ToUnstructured: %v", err)
+		}
+		u := &unstructured.Unstructured{Object: data}
+		u.SetAPIVersion("v1")
+		u.SetKind("Node")
+		return u
+	}
+	cases := []struct {
+		name    string
+		nodes   []runtime.Object
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "prefers internal ip",
+			nodes: []runtime.Object{node(
+				corev1.NodeAddress{Type: corev1.NodeHostName, Address: "node-1"},
+				corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.10"},
+				corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "192.168.1.10"},
+			)},
+			want: "192.168.1.10",
+		},
+		{
+			name:  "falls back to hostname",
+			nodes: []runtime.Object{node(corev1.NodeAddress{Type: corev1.NodeHostName, Address: "node-1"})},
+			want:  "node-1",
+		},
+		{name: "no addresses", nodes: []runtime.Object{node()}, wantErr: true},
+		{name: "no nodes", wantErr: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{nodeGVR: "NodeList"}, tt.nodes...)
+			got, err := ResolveNodeAddress(context.Background(), client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveNodeAddress() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveNodeAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsesNodeAddress(t *testing.T) {
+	t.Parallel()
+	if !UsesNodeAddress(Service{}) {
+		t.Error("Services probe NodePorts")
+	}
+	if UsesNodeAddress(Ingress{}) {
+		t.Error("Ingresses don't probe NodePorts")
+	}
+}
@@ -1,14 +1,14 @@
 // Package resources implements [k8s.Resource] for Ingress, Service, Gateway
-// API HTTPRoute, and Traefik IngressRoute.
+// API HTTPRoute and Gateway, Traefik IngressRoute, and EndpointSlice, plus a
+// generic annotation-only kind for anything else.
 package resources
 
 import (
 	"fmt"
 	"reflect"
-	"strconv"
-	"strings"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/gatus"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,8 +17,8 @@ import (
 )
 
 const (
-	conditionHTTPOK    = "[STATUS] == 200"
-	conditionConnected = "[CONNECTED] == true"
+	conditionHTTPOK    = gatus.StatusOKCondition
+	conditionConnected = gatus.ConnectedCondition
 )
 
 var (
@@ -26,25 +26,12 @@ var (
 	tcpDefaultConditions  = []string{conditionConnected}
 )
 
-// formatURL composes scheme://host/path, honoring an embedded scheme on host
-// (e.g. host = "http://example.com" yields host+path unchanged).
-func formatURL(host, path string, useTLS bool) string {
-	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
-		return host + path
-	}
-	scheme := "http"
-	if useTLS {
-		scheme = "https"
-	}
-	return scheme + "://" + host + path
-}
-
 // matchesAnnotation accepts obj when auto-mode is on or when an explicit
 // gatus annotation opts the resource in, unless the enabled annotation is
 // explicitly falsy. Callers run any kind-specific filter (ingress class,
 // gateway name) before this.
 func matchesAnnotation(obj metav1.Object, auto bool, cfg *config.Config) bool {
-	if isExplicitlyDisabled(obj.GetAnnotations(), cfg.EnabledAnnotation) {
+	if cfg.Disabled(obj.GetAnnotations()) {
 		return false
 	}
 	return auto || hasGatusAnnotations(obj, cfg)
@@ -52,29 +39,47 @@ func matchesAnnotation(obj metav1.Object, auto bool, cfg *config.Config) bool {
 
 // registry maps each kind name to its Resource constructor. It is the single
 // source of truth for which kinds exist and the order they're created in.
+// optIn kinds are left out of annotation-only mode and only run when their
+// own flag is set.
 var registry = []struct {
-	name string
-	new  func() k8s.Resource
+	name  string
+	new   func(cfg *config.Config) k8s.Resource
+	optIn bool
 }{
-	{config.KindIngress, func() k8s.Resource { return Ingress{} }},
-	{config.KindHTTPRoute, func() k8s.Resource { return HTTPRoute{} }},
-	{config.KindService, func() k8s.Resource { return Service{} }},
-	{config.KindIngressRoute, func() k8s.Resource { return IngressRoute{} }},
+	{config.KindIngress, func(*config.Config) k8s.Resource { return Ingress{} }, false},
+	{config.KindHTTPRoute, func(cfg *config.Config) k8s.Resource { return HTTPRoute{Version: cfg.GatewayAPIVersion} }, false},
+	{config.KindService, func(*config.Config) k8s.Resource { return Service{} }, false},
+	{config.KindIngressRoute, func(*config.Config) k8s.Resource { return IngressRoute{} }, false},
+	// Gateways carry the parent template for their HTTPRoutes, so annotating
+	// one must not also start monitoring its listeners.
+	{config.KindGateway, func(cfg *config.Config) k8s.Resource { return Gateway{Version: cfg.GatewayAPIVersion} }, true},
+	// One endpoint per pod is too much to turn on implicitly.
+	{config.KindEndpointSlice, func(*config.Config) k8s.Resource { return EndpointSlice{} }, true},
 }
 
-// All returns the Resource implementations enabled by cfg. With no flag set,
-// all kinds run in annotation-only mode.
+// All returns the Resource implementations enabled by cfg, followed by one
+// [Generic] per --watch-gvr. With no flag set, all non-opt-in kinds run in
+// annotation-only mode unless --no-default-controllers is set.
 func All(cfg *config.Config) []k8s.Resource {
-	annotationOnly := !cfg.AnyExplicitlyEnabled()
 	out := make([]k8s.Resource, 0, len(registry))
 	for _, e := range registry {
-		if annotationOnly || cfg.KindEnabled(e.name) {
-			out = append(out, e.new())
+		if selected(e.name, e.optIn, cfg) {
+			out = append(out, e.new(cfg))
 		}
 	}
+	for _, gvr := range cfg.WatchGVRs {
+		out = append(out, newGeneric(gvr))
+	}
 	return out
 }
 
+// selected is the single rule deciding whether a kind runs: its own
+// --enable-/--auto- flag, or annotation-only mode for kinds that aren't
+// opt-in. One kind's flags never start another kind.
+func selected(name string, optIn bool, cfg *config.Config) bool {
+	return cfg.KindEnabled(name) || (!optIn && cfg.AnnotationOnly())
+}
+
 func convertTo[T any](u *unstructured.Unstructured) (metav1.Object, error) {
 	obj := new(T)
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
@@ -97,15 +102,3 @@ func hasGatusAnnotations(obj metav1.Object, cfg *config.Config) bool {
 	_, ok := ann[cfg.TemplateAnnotation]
 	return ok
 }
-
-// isExplicitlyDisabled returns true only when the annotation is present *and*
-// falsy. Absence is not "disabled". Unparseable values (e.g. empty, "yes")
-// are treated as disabled so a typo can't silently widen monitoring.
-func isExplicitlyDisabled(annotations map[string]string, key string) bool {
-	v, ok := annotations[key]
-	if !ok {
-		return false
-	}
-	enabled, err := strconv.ParseBool(v)
-	return err != nil || !enabled
-}
@@ -1,6 +1,7 @@
 package resources
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
@@ -21,6 +22,22 @@ func TestAll_DefaultsToEverything(t *testing.T) {
 	if len(got) != 4 {
 		t.Errorf("got %d resources, want 4", len(got))
 	}
+	for _, r := range got {
+		if r.GVR().Resource == "gateways" {
+			t.Error("gateways are opt-in and should not run in annotation-only mode")
+		}
+	}
+}
+
+func TestAll_NoDefaultControllers(t *testing.T) {
+	t.Parallel()
+	if got := All(&config.Config{NoDefaultControllers: true}); len(got) != 0 {
+		t.Errorf("got %d resources, want none", len(got))
+	}
+	got := All(&config.Config{NoDefaultControllers: true, Kinds: autoEnabled(config.KindService)})
+	if len(got) != 1 || got[0].GVR().Resource != "services" {
+		t.Errorf("--auto-service should still run alone, got %d resources", len(got))
+	}
 }
 
 func TestAll_HonorsExplicitFlags(t *testing.T) {
@@ -46,9 +63,47 @@ func TestAll_HonorsExplicitFlags(t *testing.T) {
 	if !names["services"] || !names["httproutes"] {
 		t.Errorf("got %v, want services & httproutes", names)
 	}
-	if names["ingresses"] || names["ingressroutes"] {
+	if names["ingresses"] || names["ingressroutes"] || names["gateways"] {
 		t.Errorf("unexpected resources: %v", names)
 	}
+
+	got = All(&config.Config{Kinds: autoEnabled(config.KindGateway)})
+	if len(got) != 1 || got[0].GVR().Resource != "gateways" {
+		t.Errorf("--auto-gateway should enable only gateways, got %d resources", len(got))
+	}
+}
+
+func TestAll_SelectionMatrix(t *testing.T) {
+	t.Parallel()
+	defaults := []string{"ingresses", "httproutes", "services", "ingressroutes"}
+	cases := []struct {
+		name string
+		cfg  *config.Config
+		want []string
+	}{
+		{"no flags", &config.Config{}, defaults},
+		{"enable only", &config.Config{Kinds: map[string]*config.KindConfig{config.KindService: {Enable: true}}}, []string{"services"}},
+		{"auto only", &config.Config{Kinds: autoEnabled(config.KindIngress)}, []string{"ingresses"}},
+		{"explicit false", &config.Config{Kinds: map[string]*config.KindConfig{config.KindHTTPRoute: {}}}, defaults},
+		{"opt-in alone", &config.Config{Kinds: autoEnabled(config.KindEndpointSlice)}, []string{"endpointslices"}},
+		{"mixed enable and auto", &config.Config{Kinds: map[string]*config.KindConfig{
+			config.KindIngress:   {Enable: true},
+			config.KindHTTPRoute: {Auto: true},
+		}}, []string{"ingresses", "httproutes"}},
+		{"no default controllers", &config.Config{NoDefaultControllers: true}, nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, r := range All(tt.cfg) {
+				got = append(got, r.GVR().Resource)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("All() = %v, want %v", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestConvertTo(t *testing.T) {
@@ -117,30 +172,3 @@ func TestHasGatusAnnotations(t *testing.T) {
 		})
 	}
 }
-
-func TestIsExplicitlyDisabled(t *testing.T) {
-	t.Parallel()
-	cases := []struct {
-		name string
-		ann  map[string]string
-		want bool
-	}{
-		{"absent", nil, false},
-		{"true", map[string]string{"enabled": "true"}, false},
-		{"True", map[string]string{"enabled": "True"}, false},
-		{"TRUE", map[string]string{"enabled": "TRUE"}, false},
-		{"one", map[string]string{"enabled": "1"}, false},
-		{"false", map[string]string{"enabled": "false"}, true},
-		{"zero", map[string]string{"enabled": "0"}, true},
-		{"empty", map[string]string{"enabled": ""}, true},
-		{"unparseable", map[string]string{"enabled": "yes"}, true},
-	}
-	for _, tt := range cases {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			if got := isExplicitlyDisabled(tt.ann, "enabled"); got != tt.want {
-				t.Errorf("isExplicitlyDisabled() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
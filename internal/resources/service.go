@@ -3,16 +3,22 @@ package resources
 import (
 	"cmp"
 	"context"
-	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
 	"github.com/home-operations/gatus-sidecar/internal/k8s"
+	"github.com/home-operations/gatus-sidecar/internal/urlutil"
 
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 var serviceGVR = schema.GroupVersionResource{
@@ -25,29 +31,167 @@ type Service struct{}
 
 func (Service) GVR() schema.GroupVersionResource { return serviceGVR }
 
-func (Service) Prefix(cfg *config.Config) string { return cfg.Prefix(config.KindService) }
+func (Service) Prefix(cfg *config.Config) string          { return cfg.Prefix(config.KindService) }
+func (Service) Interval(cfg *config.Config) time.Duration { return cfg.Interval(config.KindService) }
 
 func (Service) Convert(u *unstructured.Unstructured) (metav1.Object, error) {
 	return convertTo[corev1.Service](u)
 }
 
 func (Service) Matches(obj metav1.Object, cfg *config.Config) bool {
-	if _, ok := obj.(*corev1.Service); !ok {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		return false
+	}
+	if len(cfg.ServiceTypes) > 0 && !cfg.ServiceTypes.Contains(string(serviceTypeOf(svc))) {
 		return false
 	}
 	return matchesAnnotation(obj, cfg.AutoEnabled(config.KindService), cfg)
 }
 
+// Ready implements [k8s.ReadinessGate]: under --require-backends a Service
+// is monitored only while an EndpointSlice address behind it is ready.
+// ExternalName Services have no backends and always pass, as does a Service
+// whose slices can't be listed, so an apiserver hiccup doesn't drop it.
+func (Service) Ready(ctx context.Context, obj metav1.Object, cfg *config.Config, fetcher k8s.Fetcher) bool {
+	svc, ok := obj.(*corev1.Service)
+	if !ok || !cfg.RequireBackends || serviceTypeOf(svc) == corev1.ServiceTypeExternalName {
+		return true
+	}
+	n, err := readyBackends(ctx, fetcher, svc.Namespace, svc.Name)
+	if err != nil {
+		slog.Debug("list EndpointSlices", "namespace", svc.Namespace, "name", svc.Name, "error", err)
+		return true
+	}
+	return n > 0
+}
+
+// DependencyGVR implements [k8s.DependentResource]: under
+// --require-backends the controller watches EndpointSlices, so a Service is
+// re-checked as soon as a backend becomes ready or goes away.
+func (Service) DependencyGVR(cfg *config.Config) (schema.GroupVersionResource, bool) {
+	return endpointSliceGVR, cfg.RequireBackends
+}
+
+// DependentKeys names the Service an EndpointSlice belongs to.
+func (Service) DependentKeys(dep metav1.Object) []string {
+	name := dep.GetLabels()[discoveryv1.LabelServiceName]
+	if name == "" {
+		return nil
+	}
+	return []string{dep.GetNamespace() + "/" + name}
+}
+
 func (Service) URL(obj metav1.Object) string {
 	svc, ok := obj.(*corev1.Service)
-	if !ok || len(svc.Spec.Ports) == 0 {
+	if !ok {
 		return ""
 	}
-	port := svc.Spec.Ports[0]
+	ports := probeablePorts(svc, nil)
+	if len(ports) == 0 {
+		return ""
+	}
+	return servicePortURL(svc, ports[0])
+}
+
+// Targets yields one target per probeable ServicePort under
+// --service-all-ports, suffixed with the port name (or number when
+// unnamed). Otherwise it is the single first-port target URL would produce.
+func (s Service) Targets(obj metav1.Object, cfg *config.Config) []k8s.Target {
+	svc, ok := obj.(*corev1.Service)
+	if !ok {
+		return nil
+	}
+	ports := probeablePorts(svc, cfg.ServicePortProtocols)
+	if !cfg.ServiceAllPorts {
+		if len(ports) == 0 {
+			return []k8s.Target{{}}
+		}
+		return []k8s.Target{servicePortTarget(svc, ports[0], cfg)}
+	}
+	out := make([]k8s.Target, 0, len(ports))
+	for _, port := range ports {
+		name := cmp.Or(port.Name, strconv.Itoa(int(port.Port)))
+		t := servicePortTarget(svc, port, cfg)
+		t.Suffix, t.Port = name, name
+		out = append(out, t)
+	}
+	return out
+}
+
+// probeablePorts returns svc's ports whose protocol (TCP when unset) is in
+// protocols, or TCP or UDP when protocols is empty.
+func probeablePorts(svc *corev1.Service, protocols config.StringSet) []corev1.ServicePort {
+	if len(protocols) == 0 {
+		protocols = config.StringSet{string(corev1.ProtocolTCP), string(corev1.ProtocolUDP)}
+	}
+	return slices.DeleteFunc(slices.Clone(svc.Spec.Ports), func(p corev1.ServicePort) bool {
+		return !protocols.Contains(string(cmp.Or(p.Protocol, corev1.ProtocolTCP)))
+	})
+}
+
+// servicePortTarget probes port over its protocol, except that UDP ports
+// listed in --service-dns-ports get a DNS query against the Service, since
+// a UDP "connection" proves nothing. Other UDP ports pass on
+// --udp-condition rather than the TCP default. Under
+// --service-nodeport-probe a NodePort Service is probed on the node, and
+// under --service-use-target-port a headless one on its targetPort.
+func servicePortTarget(svc *corev1.Service, port corev1.ServicePort, cfg *config.Config) k8s.Target {
+	t := k8s.Target{URL: servicePortURL(svc, port)}
+	switch {
+	case cfg.ServiceNodePortProbe != "" && serviceTypeOf(svc) == corev1.ServiceTypeNodePort:
+		if port.NodePort == 0 {
+			// Not allocated yet; the update that assigns it requeues us.
+			return k8s.Target{}
+		}
+		protocol := strings.ToLower(string(cmp.Or(port.Protocol, corev1.ProtocolTCP)))
+		t.URL = urlutil.HostPortURL(protocol, cfg.ServiceNodePortProbe, int(port.NodePort))
+	case cfg.ServiceUseTargetPort && svc.Spec.ClusterIP == corev1.ClusterIPNone:
+		// A cluster IP only forwards the service port; only a headless
+		// name resolves to the Pods listening on the targetPort.
+		t.URL = urlutil.SetPort(t.URL, int(targetPortNumber(port)))
+	}
+	if port.Protocol != corev1.ProtocolUDP {
+		return t
+	}
+	if slices.Contains(cfg.ServiceDNSPorts, port.Port) {
+		t.DNSServer = serviceDNSServer(svc)
+		t.DNSQuery = cfg.ServiceDNSQuery
+	} else if cfg.UDPCondition != "" {
+		t.Conditions = []string{cfg.UDPCondition}
+	}
+	return t
+}
+
+// targetPortNumber returns port's numeric targetPort, or its service port
+// when the targetPort is unset or a name, which only the Pod can resolve.
+func targetPortNumber(port corev1.ServicePort) int32 {
+	if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal > 0 {
+		return port.TargetPort.IntVal
+	}
+	return port.Port
+}
+
+// serviceDNSServer is the Service's cluster IP, or its DNS name when it is
+// headless.
+func serviceDNSServer(svc *corev1.Service) string {
+	if ip := svc.Spec.ClusterIP; ip != "" && ip != corev1.ClusterIPNone {
+		return ip
+	}
+	return svc.Name + "." + svc.Namespace + ".svc"
+}
+
+// servicePortURL builds <protocol>://<name>.<namespace>.svc:<port>, with the
+// scheme taken from the port's protocol (TCP when unset).
+func servicePortURL(svc *corev1.Service, port corev1.ServicePort) string {
 	protocol := strings.ToLower(string(cmp.Or(port.Protocol, corev1.ProtocolTCP)))
-	return fmt.Sprintf("%s://%s.%s.svc:%d", protocol, svc.Name, svc.Namespace, port.Port)
+	return urlutil.HostPortURL(protocol, svc.Name+"."+svc.Namespace+".svc", int(port.Port))
 }
 
+// ExternalDefault implements [k8s.ExternalDefaulter] for
+// --service-external-default.
+func (Service) ExternalDefault(cfg *config.Config) bool { return cfg.ServiceExternalDefault }
+
 func (Service) DefaultConditions() []string { return tcpDefaultConditions }
 
 // Services have no meaningful guarded mode.
@@ -56,3 +200,12 @@ func (Service) GuardHost(metav1.Object) string { return "" }
 func (Service) ParentAnnotations(context.Context, metav1.Object, k8s.Fetcher) map[string]string {
 	return nil
 }
+
+// serviceTypeOf returns svc's type, which the apiserver defaults to
+// ClusterIP when unset.
+func serviceTypeOf(svc *corev1.Service) corev1.ServiceType {
+	if svc.Spec.Type == "" {
+		return corev1.ServiceTypeClusterIP
+	}
+	return svc.Spec.Type
+}
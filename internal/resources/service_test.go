@@ -2,12 +2,20 @@ package resources
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/home-operations/gatus-sidecar/internal/config"
+	"github.com/home-operations/gatus-sidecar/internal/k8s"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 )
 
 func makeService(name, ns string, port int32, protocol corev1.Protocol) *corev1.Service {
@@ -59,6 +67,87 @@ func TestService_DefaultConditionsAndMatches(t *testing.T) {
 	}
 }
 
+func TestService_MatchesServiceTypes(t *testing.T) {
+	t.Parallel()
+	withType := func(typ corev1.ServiceType) *corev1.Service {
+		svc := makeService("a", "n", 80, corev1.ProtocolTCP)
+		svc.Spec.Type = typ
+		return svc
+	}
+	cases := []struct {
+		name  string
+		svc   *corev1.Service
+		types config.StringSet
+		want  bool
+	}{
+		{"no filter", withType(corev1.ServiceTypeExternalName), nil, true},
+		{"listed", withType(corev1.ServiceTypeLoadBalancer), config.StringSet{"ClusterIP", "LoadBalancer"}, true},
+		{"unlisted", withType(corev1.ServiceTypeExternalName), config.StringSet{"ClusterIP", "LoadBalancer"}, false},
+		{"unset type is ClusterIP", withType(""), config.StringSet{"ClusterIP"}, true},
+		{"unset type excluded", withType(""), config.StringSet{"NodePort"}, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg := &config.Config{Kinds: autoEnabled(config.KindService), ServiceTypes: tt.types}
+			if got := (Service{}).Matches(tt.svc, cfg); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_ReadyRequiresBackends(t *testing.T) {
+	t.Parallel()
+	unready := false
+	slice := makeSlice("pg", nil, podEndpoint("pg-0", "10.0.0.1", &unready))
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{endpointSliceGVR: "EndpointSliceList"},
+		toUnstructured(t, slice, endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")))
+	failing := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{endpointSliceGVR: "EndpointSliceList"})
+	failing.PrependReactor("list", "endpointslices", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	external := makeService("pg", "db", 5432, corev1.ProtocolTCP)
+	external.Spec.Type = corev1.ServiceTypeExternalName
+	on := &config.Config{RequireBackends: true}
+
+	cases := []struct {
+		name   string
+		svc    *corev1.Service
+		cfg    *config.Config
+		client *fake.FakeDynamicClient
+		want   bool
+	}{
+		{"flag off", makeService("pg", "db", 5432, corev1.ProtocolTCP), &config.Config{}, client, true},
+		{"no ready backend", makeService("pg", "db", 5432, corev1.ProtocolTCP), on, client, false},
+		{"no slices", makeService("other", "db", 5432, corev1.ProtocolTCP), on, client, false},
+		{"ExternalName has no backends to require", external, on, client, true},
+		{"listing fails open", makeService("pg", "db", 5432, corev1.ProtocolTCP), on, failing, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := (Service{}).Ready(context.Background(), tt.svc, tt.cfg, newClientFetcher(tt.client)); got != tt.want {
+				t.Errorf("Ready() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The same Service passes once a backend is ready.
+	ready := makeSlice("pg", nil, podEndpoint("pg-0", "10.0.0.1", nil))
+	ready.Name = "pg-ready"
+	readyClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{endpointSliceGVR: "EndpointSliceList"},
+		toUnstructured(t, slice, endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")),
+		toUnstructured(t, ready, endpointSliceGVR.GroupVersion().WithKind("EndpointSlice")))
+	if !(Service{}).Ready(context.Background(), makeService("pg", "db", 5432, corev1.ProtocolTCP), on, newClientFetcher(readyClient)) {
+		t.Error("Ready() = false with a ready backend")
+	}
+}
+
 func TestService_GuardHostAndParentAnnotations_NoOps(t *testing.T) {
 	t.Parallel()
 	if got := (Service{}).GuardHost(makeService("a", "n", 80, corev1.ProtocolTCP)); got != "" {
@@ -68,3 +157,194 @@ func TestService_GuardHostAndParentAnnotations_NoOps(t *testing.T) {
 		t.Errorf("ParentAnnotations should always return nil, got %v", ann)
 	}
 }
+
+func TestService_ExternalDefault(t *testing.T) {
+	t.Parallel()
+	var r k8s.Resource = Service{}
+	d, ok := r.(k8s.ExternalDefaulter)
+	if !ok {
+		t.Fatal("Service should implement k8s.ExternalDefaulter")
+	}
+	if d.ExternalDefault(&config.Config{}) || !d.ExternalDefault(&config.Config{ServiceExternalDefault: true}) {
+		t.Error("ExternalDefault should follow --service-external-default")
+	}
+}
+
+func TestService_Targets(t *testing.T) {
+	t.Parallel()
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "data"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "admin", Port: 8080, Protocol: corev1.ProtocolTCP},
+			{Name: "data", Port: 5432},
+			{Port: 53, Protocol: corev1.ProtocolUDP},
+		}},
+	}
+
+	t.Run("all ports", func(t *testing.T) {
+		t.Parallel()
+		got := (Service{}).Targets(svc, &config.Config{ServiceAllPorts: true})
+		want := []k8s.Target{
+			{Suffix: "admin", URL: "tcp://db.data.svc:8080", Port: "admin"},
+			{Suffix: "data", URL: "tcp://db.data.svc:5432", Port: "data"},
+			{Suffix: "53", URL: "udp://db.data.svc:53", Port: "53"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+	})
+
+	t.Run("first port by default", func(t *testing.T) {
+		t.Parallel()
+		got := (Service{}).Targets(svc, &config.Config{})
+		want := []k8s.Target{{URL: "tcp://db.data.svc:8080"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("sctp only is skipped", func(t *testing.T) {
+		t.Parallel()
+		sctp := makeService("diameter", "core", 3868, corev1.ProtocolSCTP)
+		if got := (Service{}).Targets(sctp, &config.Config{}); !reflect.DeepEqual(got, []k8s.Target{{}}) {
+			t.Errorf("Targets() = %+v, want one empty target", got)
+		}
+		if got := (Service{}).URL(sctp); got != "" {
+			t.Errorf("URL() = %q, want empty", got)
+		}
+	})
+
+	t.Run("mixed tcp and sctp uses tcp", func(t *testing.T) {
+		t.Parallel()
+		mixed := makeService("diameter", "core", 3868, corev1.ProtocolSCTP)
+		mixed.Spec.Ports = append(mixed.Spec.Ports, corev1.ServicePort{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP})
+		got := (Service{}).Targets(mixed, &config.Config{ServiceAllPorts: true, ServicePortProtocols: config.StringSet{"TCP", "UDP"}})
+		want := []k8s.Target{{Suffix: "metrics", URL: "tcp://diameter.core.svc:9090", Port: "metrics"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("protocol filter", func(t *testing.T) {
+		t.Parallel()
+		got := (Service{}).Targets(svc, &config.Config{ServicePortProtocols: config.StringSet{"UDP"}})
+		want := []k8s.Target{{URL: "udp://db.data.svc:53"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("udp condition", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{ServiceAllPorts: true, UDPCondition: "len([BODY]) > 0"}
+		got := (Service{}).Targets(svc, cfg)
+		want := []k8s.Target{
+			{Suffix: "admin", URL: "tcp://db.data.svc:8080", Port: "admin"},
+			{Suffix: "data", URL: "tcp://db.data.svc:5432", Port: "data"},
+			{Suffix: "53", URL: "udp://db.data.svc:53", Port: "53", Conditions: []string{"len([BODY]) > 0"}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+	})
+
+	t.Run("target port", func(t *testing.T) {
+		t.Parallel()
+		headless := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone, Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromInt32(8080)},
+				{Name: "metrics", Port: 9090, TargetPort: intstr.FromString("metrics")},
+				{Name: "admin", Port: 8081},
+			}},
+		}
+		got := (Service{}).Targets(headless, &config.Config{ServiceAllPorts: true, ServiceUseTargetPort: true})
+		want := []k8s.Target{
+			{Suffix: "http", URL: "tcp://web.default.svc:8080", Port: "http"},
+			{Suffix: "metrics", URL: "tcp://web.default.svc:9090", Port: "metrics"},
+			{Suffix: "admin", URL: "tcp://web.default.svc:8081", Port: "admin"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+		if got := (Service{}).Targets(headless, &config.Config{}); got[0].URL != "tcp://web.default.svc:80" {
+			t.Errorf("without the flag URL = %q, want the service port", got[0].URL)
+		}
+		clusterIP := headless.DeepCopy()
+		clusterIP.Spec.ClusterIP = "10.96.0.20"
+		if got := (Service{}).Targets(clusterIP, &config.Config{ServiceUseTargetPort: true}); got[0].URL != "tcp://web.default.svc:80" {
+			t.Errorf("ClusterIP Service URL = %q, want the service port it forwards", got[0].URL)
+		}
+	})
+
+	t.Run("nodeport probe", func(t *testing.T) {
+		t.Parallel()
+		nodePort := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort, Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80, NodePort: 30080},
+				{Name: "pending", Port: 81},
+				{Name: "syslog", Port: 514, NodePort: 30514, Protocol: corev1.ProtocolUDP},
+			}},
+		}
+		cfg := &config.Config{ServiceAllPorts: true, ServiceNodePortProbe: "192.168.1.10"}
+		want := []k8s.Target{
+			{Suffix: "http", URL: "tcp://192.168.1.10:30080", Port: "http"},
+			{Suffix: "pending", Port: "pending"},
+			{Suffix: "syslog", URL: "udp://192.168.1.10:30514", Port: "syslog"},
+		}
+		if got := (Service{}).Targets(nodePort, cfg); !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() =\n%+v\nwant\n%+v", got, want)
+		}
+
+		ipv6 := &config.Config{ServiceNodePortProbe: "fd00::10"}
+		if got := (Service{}).Targets(nodePort, ipv6); got[0].URL != "tcp://[fd00::10]:30080" {
+			t.Errorf("IPv6 node URL = %q", got[0].URL)
+		}
+		if got := (Service{}).Targets(svc, cfg); got[0].URL != "tcp://db.data.svc:8080" {
+			t.Errorf("ClusterIP Service URL = %q, want the in-cluster name", got[0].URL)
+		}
+	})
+
+	t.Run("dns port", func(t *testing.T) {
+		t.Parallel()
+		dns := makeService("kube-dns", "kube-system", 53, corev1.ProtocolUDP)
+		dns.Spec.ClusterIP = "10.96.0.10"
+		cfg := &config.Config{ServiceDNSPorts: []int32{53}, ServiceDNSQuery: "kubernetes.default.svc.cluster.local"}
+		got := (Service{}).Targets(dns, cfg)
+		want := []k8s.Target{{
+			URL:       "udp://kube-dns.kube-system.svc:53",
+			DNSServer: "10.96.0.10",
+			DNSQuery:  "kubernetes.default.svc.cluster.local",
+		}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Targets() = %+v, want %+v", got, want)
+		}
+
+		dns.Spec.ClusterIP = corev1.ClusterIPNone
+		if got := (Service{}).Targets(dns, cfg); got[0].DNSServer != "kube-dns.kube-system.svc" {
+			t.Errorf("headless DNSServer = %q, want the service name", got[0].DNSServer)
+		}
+	})
+
+	t.Run("generic udp and tcp 53 keep connect checks", func(t *testing.T) {
+		t.Parallel()
+		cfg := &config.Config{ServiceDNSPorts: []int32{53}, ServiceDNSQuery: "q"}
+		for _, svc := range []*corev1.Service{
+			makeService("syslog", "n", 514, corev1.ProtocolUDP),
+			makeService("dns-tcp", "n", 53, corev1.ProtocolTCP),
+		} {
+			if got := (Service{}).Targets(svc, cfg); got[0].DNSServer != "" {
+				t.Errorf("%s: unexpected DNS check %+v", svc.Name, got[0])
+			}
+		}
+	})
+
+	t.Run("no ports", func(t *testing.T) {
+		t.Parallel()
+		empty := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "n"}}
+		if got := (Service{}).Targets(empty, &config.Config{ServiceAllPorts: true}); len(got) != 0 {
+			t.Errorf("Targets(no ports) = %+v, want none", got)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+// Package urlutil builds and adjusts the probe URLs shared by every
+// resource kind, so scheme and path handling can't drift between them.
+package urlutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EnsureScheme prefixes host with defaultScheme unless it already carries an
+// explicit http:// or https:// scheme. Only the full "://" form counts, so a
+// hostname such as http-api.example.com still gets one.
+func EnsureScheme(host, defaultScheme string) string {
+	if strings.HasPrefix(host, "http://") || strings.HasPrefix(host, "https://") {
+		return host
+	}
+	return defaultScheme + "://" + host
+}
+
+// BuildHostURL composes scheme://host/path for an HTTP route, using https
+// when useTLS is set and honoring a scheme embedded in host.
+func BuildHostURL(host, path string, useTLS bool) string {
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	return EnsureScheme(host, scheme) + path
+}
+
+// HostPortURL composes scheme://host:port, bracketing IPv6 literals.
+func HostPortURL(scheme, host string, port int) string {
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// SetPath replaces rawURL's path with path (empty clears it). rawURL
+// is returned unchanged when it doesn't parse as an absolute URL.
+func SetPath(rawURL, path string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return rawURL
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u.Path = path
+	return u.String()
+}
+
+// SetHTTPScheme swaps the scheme of an http(s) URL, leaving tcp:// and
+// other probes untouched.
+func SetHTTPScheme(rawURL, scheme string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return rawURL
+	}
+	u.Scheme = scheme
+	return u.String()
+}
+
+// Hostname returns rawURL's host without port or IPv6 brackets, or "" when
+// it doesn't parse.
+func Hostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// defaultPorts fill in the port a tcp:// or tls:// probe needs when the
+// extracted URL relied on its scheme's default.
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ws": "80", "wss": "443"}
+
+// SetCheckScheme rewrites rawURL for a Gatus check type: "icmp" keeps only
+// the host, "tcp" and "tls" keep host and port, and "http" and "ws" swap the
+// scheme while keeping TLS-ness and the path. rawURL is returned unchanged
+// for other checks or when it doesn't parse as an absolute URL.
+func SetCheckScheme(rawURL, check string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss" || u.Scheme == "tls"
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[u.Scheme]
+	}
+	switch check {
+	case "icmp":
+		host := u.Hostname()
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		return "icmp://" + host
+	case "tcp", "tls":
+		if port == "" {
+			return rawURL
+		}
+		return check + "://" + net.JoinHostPort(u.Hostname(), port)
+	case "http", "ws":
+		u.Scheme = check
+		if secure {
+			u.Scheme += "s"
+		}
+		return u.String()
+	default:
+		return rawURL
+	}
+}
+
+// SetPort replaces rawURL's port, bracketing IPv6 literals. rawURL is
+// returned unchanged when it doesn't parse as an absolute URL.
+func SetPort(rawURL string, port int) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	return u.String()
+}
+
+// SetHost replaces rawURL's hostname, keeping its port. rawURL is returned
+// unchanged when it doesn't parse as an absolute URL.
+func SetHost(rawURL, host string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		u.Host = "[" + host + "]"
+	} else {
+		u.Host = host
+	}
+	return u.String()
+}
+
+// SetOrigin replaces rawURL's scheme, host and port with origin's, keeping
+// its path and query. rawURL is returned unchanged when either doesn't parse
+// as an absolute URL.
+func SetOrigin(rawURL, origin string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+	o, err := url.Parse(origin)
+	if err != nil || o.Scheme == "" || o.Host == "" {
+		return rawURL
+	}
+	u.Scheme, u.Host = o.Scheme, o.Host
+	return u.String()
+}
+
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9_])?(\.[A-Za-z0-9_]([A-Za-z0-9_-]*[A-Za-z0-9_])?)*\.?$`)
+
+// Validate is a safety net for extractor output Gatus would reject:
+// unparseable URLs, missing scheme or host, wildcard or junk-laden hosts,
+// and IPv6 literals without brackets.
+func Validate(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return errors.New("missing scheme or host")
+	}
+	host := u.Hostname()
+	if strings.Contains(host, ":") {
+		if !strings.HasPrefix(u.Host, "[") || net.ParseIP(host) == nil {
+			return fmt.Errorf("IPv6 host %q must be a bracketed address", host)
+		}
+		return nil
+	}
+	if !hostnamePattern.MatchString(host) {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package urlutil
+
+import "testing"
+
+func TestEnsureScheme(t *testing.T) {
+	cases := []struct {
+		name string
+		host string
+		want string
+	}{
+		{"bare host", "api.example.com", "https://api.example.com"},
+		{"http prefix preserved", "http://api", "http://api"},
+		{"https prefix preserved", "https://api", "https://api"},
+		{"http-prefixed hostname", "http-test.domain.com", "https://http-test.domain.com"},
+		{"https-prefixed hostname", "https-api.example.com", "https://https-api.example.com"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnsureScheme(tt.host, "https"); got != tt.want {
+				t.Errorf("EnsureScheme(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildHostURL(t *testing.T) {
+	cases := []struct {
+		name   string
+		host   string
+		path   string
+		useTLS bool
+		want   string
+	}{
+		{"http", "example.com", "", false, "http://example.com"},
+		{"https with path", "example.com", "/healthz", true, "https://example.com/healthz"},
+		{"embedded scheme wins over tls", "http://example.com", "/v1", true, "http://example.com/v1"},
+		{"http-prefixed hostname", "http-debug.com", "", false, "http://http-debug.com"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildHostURL(tt.host, tt.path, tt.useTLS); got != tt.want {
+				t.Errorf("BuildHostURL(%q, %q, %v) = %q, want %q", tt.host, tt.path, tt.useTLS, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostPortURL(t *testing.T) {
+	if got := HostPortURL("tcp", "10.0.0.1", 80); got != "tcp://10.0.0.1:80" {
+		t.Errorf("ipv4: %q", got)
+	}
+	if got := HostPortURL("udp", "fd00::1", 53); got != "udp://[fd00::1]:53" {
+		t.Errorf("ipv6: %q", got)
+	}
+}
+
+func TestSetHTTPScheme(t *testing.T) {
+	if got := SetHTTPScheme("https://x.example.com/api", "http"); got != "http://x.example.com/api" {
+		t.Errorf("https -> http: %q", got)
+	}
+	if got := SetHTTPScheme("tcp://x.svc:80", "https"); got != "tcp://x.svc:80" {
+		t.Errorf("tcp should be untouched: %q", got)
+	}
+}
+
+func TestSetCheckScheme(t *testing.T) {
+	tests := []struct {
+		url, check, want string
+	}{
+		{"tcp://nas.storage.svc:5000", "icmp", "icmp://nas.storage.svc"},
+		{"https://app.example.com/healthz", "icmp", "icmp://app.example.com"},
+		{"tcp://[fd00::1]:22", "icmp", "icmp://[fd00::1]"},
+		{"https://app.example.com/healthz", "tcp", "tcp://app.example.com:443"},
+		{"http://app.example.com:8080", "tls", "tls://app.example.com:8080"},
+		{"tcp://web.default.svc:8080", "http", "http://web.default.svc:8080"},
+		{"https://app.example.com/ws", "ws", "wss://app.example.com/ws"},
+		{"http://app.example.com/ws", "ws", "ws://app.example.com/ws"},
+		{"https://app.example.com", "dns", "https://app.example.com"},
+		{"not a url", "icmp", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := SetCheckScheme(tt.url, tt.check); got != tt.want {
+			t.Errorf("SetCheckScheme(%q, %q) = %q, want %q", tt.url, tt.check, got, tt.want)
+		}
+	}
+}
+
+func TestSetPort(t *testing.T) {
+	tests := []struct {
+		url  string
+		port int
+		want string
+	}{
+		{"tls://app.example.com:443", 8443, "tls://app.example.com:8443"},
+		{"tls://[fd00::1]:443", 636, "tls://[fd00::1]:636"},
+		{"https://app.example.com/healthz", 8443, "https://app.example.com:8443/healthz"},
+		{"not a url", 443, "not a url"},
+	}
+	for _, tt := range tests {
+		if got := SetPort(tt.url, tt.port); got != tt.want {
+			t.Errorf("SetPort(%q, %d) = %q, want %q", tt.url, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestSetHost(t *testing.T) {
+	tests := []struct {
+		url, host, want string
+	}{
+		{"https://www.example.com/healthz", "example.com", "https://example.com/healthz"},
+		{"http://www.example.com:8080", "example.com", "http://example.com:8080"},
+		{"tcp://web.default.svc:80", "fd00::1", "tcp://[fd00::1]:80"},
+		{"not a url", "example.com", "not a url"},
+	}
+	for _, tt := range tests {
+		if got := SetHost(tt.url, tt.host); got != tt.want {
+			t.Errorf("SetHost(%q, %q) = %q, want %q", tt.url, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSetOrigin(t *testing.T) {
+	tests := []struct {
+		url, origin, want string
+	}{
+		{"https://app.example.com/healthz?x=1", "http://ingress.ingress-nginx.svc", "http://ingress.ingress-nginx.svc/healthz?x=1"},
+		{"http://app.example.com", "https://ingress.svc:8443", "https://ingress.svc:8443"},
+		{"not a url", "http://ingress.svc", "not a url"},
+		{"https://app.example.com", "ingress.svc", "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		if got := SetOrigin(tt.url, tt.origin); got != tt.want {
+			t.Errorf("SetOrigin(%q, %q) = %q, want %q", tt.url, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	cases := []struct {
+		name    string
+		rawURL  string
+		newPath string
+		want    string
+	}{
+		{"replace path", "https://x.example.com/api", "/healthz", "https://x.example.com/healthz"},
+		{"strip path", "https://x.example.com/api", "", "https://x.example.com"},
+		{"add path when none", "https://x.example.com", "/alive", "https://x.example.com/alive"},
+		{"non-rooted gets leading slash", "https://x.example.com/api", "alive", "https://x.example.com/alive"},
+		{"preserves port", "https://x.example.com:8443/api", "/healthz", "https://x.example.com:8443/healthz"},
+		{"preserves query", "https://x.example.com/api?q=1", "/healthz", "https://x.example.com/healthz?q=1"},
+		{"unparseable returns as-is", "not a url", "/healthz", "not a url"},
+		{"scheme-less returns as-is", "x.example.com/api", "/healthz", "x.example.com/api"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetPath(tt.rawURL, tt.newPath); got != tt.want {
+				t.Errorf("SetPath(%q, %q) = %q, want %q", tt.rawURL, tt.newPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rawURL  string
+		wantErr bool
+	}{
+		{"https host", "https://api.example.com/v1", false},
+		{"service url", "tcp://web.default.svc:8080", false},
+		{"bracketed ipv6", "tcp://[fd00::1]:80", false},
+		{"ipv4", "http://10.0.0.1:8080", false},
+		{"wildcard host", "https://*.example.com", true},
+		{"trailing match junk", "https://example.com)", true},
+		{"space in host", "https://exa mple.com", true},
+		{"unbracketed ipv6", "tcp://fd00::1:80", true},
+		{"unbracketed ipv6 without port", "http://fd00::1", true},
+		{"no scheme", "example.com", true},
+		{"no host", "https://", true},
+		{"leading dash label", "https://-bad.example.com", true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.rawURL); (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) err=%v, wantErr=%v", tt.rawURL, err, tt.wantErr)
+			}
+		})
+	}
+}
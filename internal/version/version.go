@@ -0,0 +1,19 @@
+// Package version holds build metadata injected at link time, e.g.
+// -ldflags "-X github.com/home-operations/gatus-sidecar/internal/version.Version=v1.2.3".
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var (
+	Version   = "local"
+	Gitsha    = "?"
+	BuildDate = "unknown"
+)
+
+// String renders the build metadata for --version.
+func String() string {
+	return fmt.Sprintf("gatus-sidecar %s (commit %s, built %s, %s)", Version, Gitsha, BuildDate, runtime.Version())
+}